@@ -2,8 +2,11 @@ package sarama
 
 import (
 	"errors"
+	"fmt"
 	"math/rand"
+	"strconv"
 	"sync"
+	"time"
 )
 
 // ClusterAdmin is the administrative client for Kafka, which supports managing and inspecting topics,
@@ -39,6 +42,13 @@ type ClusterAdmin interface {
 	// new partitions. This operation is supported by brokers with version 1.0.0 or higher.
 	CreatePartitions(topic string, count int32, assignment [][]int32, validateOnly bool) error
 
+	// Elect the leaders of the given partitions, or of all the partitions of the cluster if
+	// topicPartitions is nil. The result of the election of every partition is returned; a
+	// partition whose preferred replica is already the leader reports ErrElectionNotNeeded.
+	// Preferred elections are supported by brokers with version 2.2.0 or higher, unclean
+	// elections by brokers with version 2.4.0 or higher.
+	ElectLeaders(electionType ElectionType, topicPartitions map[string][]int32) (map[string]map[int32]*TopicPartitionError, error)
+
 	// Delete records whose offset is smaller than the given offset of the corresponding partition.
 	// This operation is supported by brokers with version 0.11.0.0 or higher.
 	DeleteRecords(topic string, partitionOffsets map[int32]int64) error
@@ -59,6 +69,13 @@ type ClusterAdmin interface {
 	// for some resources while fail for others. The configs for a particular resource are updated automatically.
 	AlterConfig(resourceType ConfigResourceType, name string, entries map[string]*string, validateOnly bool) error
 
+	// Incrementally update the configuration of a resource. Unlike AlterConfig, only the given
+	// entries are changed, each with its own operation, and the other entries keep their value.
+	// Topic, broker and broker logger resources are supported; the name of broker and broker
+	// logger resources is the broker ID, and their request is sent to that broker.
+	// This operation is supported by brokers with version 2.3.0 or higher.
+	IncrementalAlterConfig(resourceType ConfigResourceType, name string, entries map[string]IncrementalAlterConfigsEntry, validateOnly bool) error
+
 	// Creates access control lists (ACLs) which are bound to specific resources.
 	// This operation is not transactional so it may succeed for some ACLs while fail for others.
 	// If you attempt to add an ACL that duplicates an existing ACL, no error will be raised, but
@@ -82,11 +99,83 @@ type ClusterAdmin interface {
 	DescribeConsumerGroups(groups []string) ([]*GroupDescription, error)
 
 	// List the consumer group offsets available in the cluster.
+	// If topicPartitions is nil, the offsets of every partition the group has committed to
+	// are returned. This requires brokers with version 0.10.2.0 or higher.
 	ListConsumerGroupOffsets(group string, topicPartitions map[string][]int32) (*OffsetFetchResponse, error)
 
+	// Delete the committed offsets of some partitions of a topic for a consumer group. The group
+	// may be active, as long as none of its members is subscribed to the topic.
+	// This operation is supported by brokers with version 2.4.0 or higher.
+	DeleteConsumerGroupOffsets(group string, topic string, partitions []int32) error
+
+	// Delete a consumer group. The group must not have any active members.
+	// This operation is supported by brokers with version 1.1.0 or higher.
+	DeleteConsumerGroup(group string) error
+
 	// Get information about the nodes in the cluster.
 	DescribeCluster() (brokers []*Broker, controllerID int32, err error)
 
+	// Describe the log directories of the given brokers, with the size and the offset lag
+	// of every partition replica they hold. Replicas being moved by AlterReplicaLogDirs are
+	// reported with IsTemporary set in the destination directory.
+	// This operation is supported by brokers with version 1.0.0 or higher.
+	DescribeLogDirs(brokers []int32) (map[int32][]DescribeLogDirsResponseDirMetadata, error)
+
+	// Move replicas hosted by a broker to other log directories of the same broker.
+	// The assignment maps each topic and partition to the absolute path of the
+	// destination log directory. The result of each move is returned per partition.
+	// This operation is supported by brokers with version 1.0.0 or higher.
+	AlterReplicaLogDirs(broker int32, assignment map[string]map[int32]string) (map[string]map[int32]KError, error)
+
+	// Create a delegation token owned by the authenticated principal, which can be renewed by
+	// the given renewers and by its owner. A maxLifetime of zero uses the
+	// delegation.token.max.lifetime.ms of the brokers. The connection must not itself be
+	// authenticated with a delegation token.
+	// This operation is supported by brokers with version 1.1.0 or higher.
+	CreateDelegationToken(renewers []KafkaPrincipal, maxLifetime time.Duration) (*DelegationToken, error)
+
+	// Renew the delegation token of the given HMAC for renewPeriod, or for the
+	// delegation.token.expiry.time.ms of the brokers when it is zero, and return its new
+	// expiry time, which never exceeds its maximum lifetime.
+	// This operation is supported by brokers with version 1.1.0 or higher.
+	RenewDelegationToken(hmac []byte, renewPeriod time.Duration) (time.Time, error)
+
+	// Bring forward the expiry time of the delegation token of the given HMAC to expiryPeriod
+	// from now, expiring it immediately when it is zero, and return its new expiry time.
+	// This operation is supported by brokers with version 1.1.0 or higher.
+	ExpireDelegationToken(hmac []byte, expiryPeriod time.Duration) (time.Time, error)
+
+	// Describe the delegation tokens owned by the given principals, or all the tokens the
+	// authenticated principal may describe when owners is empty.
+	// This operation is supported by brokers with version 1.1.0 or higher.
+	DescribeDelegationToken(owners []KafkaPrincipal) ([]DelegationToken, error)
+
+	// Describe the quotas of the entities matching all the filter components, or of all
+	// entities when there is none. With strict, the entities which also have components
+	// of other types are left out.
+	// This operation is supported by brokers with version 2.6.0 or higher.
+	DescribeClientQuotas(components []QuotaFilterComponent, strict bool) ([]DescribeClientQuotasEntry, error)
+
+	// Set or remove the quotas of entities, such as the producer_byte_rate of a user.
+	// The entities are altered independently, the error of the first failing one is returned.
+	// This operation is supported by brokers with version 2.6.0 or higher.
+	AlterClientQuotas(entries []AlterClientQuotasEntry, validateOnly bool) error
+
+	// Describe the SCRAM credentials of the given users, or of all users when users is empty.
+	// Only the mechanisms and iterations of the credentials are returned.
+	// This operation is supported by brokers with version 2.7.0 or higher.
+	DescribeUserScramCredentials(users []string) ([]*DescribeUserScramCredentialsResult, error)
+
+	// Create or update SCRAM credentials. The salted password of each upsertion is computed
+	// from its Password, with a random salt and 4096 iterations unless set otherwise, so that
+	// the password itself never goes on the wire. The result of each user is returned.
+	// This operation is supported by brokers with version 2.7.0 or higher.
+	UpsertUserScramCredentials(upsertions []AlterUserScramCredentialsUpsert) ([]*AlterUserScramCredentialsResult, error)
+
+	// Delete SCRAM credentials. The result of each user is returned.
+	// This operation is supported by brokers with version 2.7.0 or higher.
+	DeleteUserScramCredentials(deletions []AlterUserScramCredentialsDelete) ([]*AlterUserScramCredentialsResult, error)
+
 	// Close shuts down the admin and closes underlying client.
 	Close() error
 }
@@ -103,8 +192,18 @@ func NewClusterAdmin(addrs []string, conf *Config) (ClusterAdmin, error) {
 		return nil, err
 	}
 
+	admin, err := NewClusterAdminFromClient(client)
+	if err != nil {
+		_ = client.Close()
+	}
+	return admin, err
+}
+
+// NewClusterAdminFromClient creates a new ClusterAdmin using the given client.
+// Note that calling Close on the ClusterAdmin will also close the client.
+func NewClusterAdminFromClient(client Client) (ClusterAdmin, error) {
 	//make sure we can retrieve the controller
-	_, err = client.Controller()
+	_, err := client.Controller()
 	if err != nil {
 		return nil, err
 	}
@@ -366,6 +465,34 @@ func (ca *clusterAdmin) CreatePartitions(topic string, count int32, assignment [
 	return nil
 }
 
+func (ca *clusterAdmin) ElectLeaders(electionType ElectionType, topicPartitions map[string][]int32) (map[string]map[int32]*TopicPartitionError, error) {
+	request := &ElectLeadersRequest{
+		Type:            electionType,
+		TopicPartitions: topicPartitions,
+		Timeout:         ca.conf.Admin.Timeout,
+	}
+
+	if electionType != PreferredElection || ca.conf.Version.IsAtLeast(V2_4_0_0) {
+		request.Version = 1
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := b.ElectLeaders(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.ErrorCode != ErrNoError {
+		return nil, rsp.ErrorCode
+	}
+
+	return rsp.ReplicaElectionResults, nil
+}
+
 func (ca *clusterAdmin) DeleteRecords(topic string, partitionOffsets map[int32]int64) error {
 
 	if topic == "" {
@@ -466,6 +593,56 @@ func (ca *clusterAdmin) AlterConfig(resourceType ConfigResourceType, name string
 	return nil
 }
 
+func (ca *clusterAdmin) IncrementalAlterConfig(resourceType ConfigResourceType, name string, entries map[string]IncrementalAlterConfigsEntry, validateOnly bool) error {
+	var resources []*IncrementalAlterConfigsResource
+	resources = append(resources, &IncrementalAlterConfigsResource{
+		Type:          resourceType,
+		Name:          name,
+		ConfigEntries: entries,
+	})
+
+	request := &IncrementalAlterConfigsRequest{
+		Resources:    resources,
+		ValidateOnly: validateOnly,
+	}
+
+	var (
+		b   *Broker
+		err error
+	)
+	switch resourceType {
+	case BrokerResource, BrokerLoggerResource:
+		// the configs of a broker can only be altered by the broker itself
+		id, perr := strconv.ParseInt(name, 10, 32)
+		if perr != nil {
+			return perr
+		}
+		b, err = ca.findBroker(int32(id))
+	default:
+		b, err = ca.Controller()
+	}
+	if err != nil {
+		return err
+	}
+
+	rsp, err := b.IncrementalAlterConfigs(request)
+	if err != nil {
+		return err
+	}
+
+	for _, rspResource := range rsp.Resources {
+		if rspResource.Name == name {
+			if rspResource.ErrorMsg != "" {
+				return errors.New(rspResource.ErrorMsg)
+			}
+			if rspResource.ErrorCode != 0 {
+				return KError(rspResource.ErrorCode)
+			}
+		}
+	}
+	return nil
+}
+
 func (ca *clusterAdmin) CreateACL(resource Resource, acl Acl) error {
 	var acls []*AclCreation
 	acls = append(acls, &AclCreation{resource, acl})
@@ -551,6 +728,91 @@ func (ca *clusterAdmin) DescribeConsumerGroups(groups []string) (result []*Group
 	return result, nil
 }
 
+func (ca *clusterAdmin) findBroker(id int32) (*Broker, error) {
+	for _, b := range ca.client.Brokers() {
+		if b.ID() == id {
+			_ = b.Open(ca.client.Config())
+			return b, nil
+		}
+	}
+	return nil, fmt.Errorf("could not find broker id %d", id)
+}
+
+func (ca *clusterAdmin) DescribeLogDirs(brokerIds []int32) (allLogDirs map[int32][]DescribeLogDirsResponseDirMetadata, err error) {
+	allLogDirs = make(map[int32][]DescribeLogDirsResponseDirMetadata)
+
+	// Query brokers in parallel, since we may have to query multiple brokers
+	logDirsMaps := make(chan map[int32][]DescribeLogDirsResponseDirMetadata, len(brokerIds))
+	errors := make(chan error, len(brokerIds))
+	wg := sync.WaitGroup{}
+
+	for _, b := range brokerIds {
+		wg.Add(1)
+		broker, err := ca.findBroker(b)
+		if err != nil {
+			Logger.Printf("Unable to find broker with ID = %v\n", b)
+			wg.Done()
+			errors <- err
+			continue
+		}
+		go func(b *Broker, conf *Config) {
+			defer wg.Done()
+
+			request := &DescribeLogDirsRequest{}
+			if conf.Version.IsAtLeast(V2_0_0_0) {
+				request.Version = 1
+			}
+
+			response, err := b.DescribeLogDirs(request)
+			if err != nil {
+				errors <- err
+				return
+			}
+			logDirs := make(map[int32][]DescribeLogDirsResponseDirMetadata)
+			logDirs[b.ID()] = response.LogDirs
+			logDirsMaps <- logDirs
+		}(broker, ca.conf)
+	}
+
+	wg.Wait()
+	close(logDirsMaps)
+	close(errors)
+
+	for logDirsMap := range logDirsMaps {
+		for id, logDirs := range logDirsMap {
+			allLogDirs[id] = logDirs
+		}
+	}
+
+	// Intentionally return only the first error for simplicity
+	err = <-errors
+	return
+}
+
+func (ca *clusterAdmin) AlterReplicaLogDirs(brokerID int32, assignment map[string]map[int32]string) (map[string]map[int32]KError, error) {
+	request := new(AlterReplicaLogDirsRequest)
+	if ca.conf.Version.IsAtLeast(V2_0_0_0) {
+		request.Version = 1
+	}
+	for topic, partitions := range assignment {
+		for partition, path := range partitions {
+			request.AddPartition(path, topic, partition)
+		}
+	}
+
+	b, err := ca.findBroker(brokerID)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := b.AlterReplicaLogDirs(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.Results, nil
+}
+
 func (ca *clusterAdmin) ListConsumerGroups() (allGroups map[string]string, err error) {
 	allGroups = make(map[string]string)
 
@@ -608,9 +870,306 @@ func (ca *clusterAdmin) ListConsumerGroupOffsets(group string, topicPartitions m
 		partitions:    topicPartitions,
 	}
 
-	if ca.conf.Version.IsAtLeast(V0_8_2_2) {
+	if ca.conf.Version.IsAtLeast(V0_10_2_0) {
+		request.Version = 2
+	} else if ca.conf.Version.IsAtLeast(V0_8_2_2) {
 		request.Version = 1
 	}
 
 	return coordinator.FetchOffset(request)
 }
+
+func (ca *clusterAdmin) DeleteConsumerGroupOffsets(group string, topic string, partitions []int32) error {
+	coordinator, err := ca.client.Coordinator(group)
+	if err != nil {
+		return err
+	}
+	request := &DeleteOffsetsRequest{
+		Group: group,
+	}
+	for _, partition := range partitions {
+		request.AddPartition(topic, partition)
+	}
+
+	resp, err := coordinator.DeleteOffsets(request)
+	if err != nil {
+		return err
+	}
+
+	if resp.ErrorCode != ErrNoError {
+		return resp.ErrorCode
+	}
+
+	if resp.Errors[topic] == nil {
+		return ErrIncompleteResponse
+	}
+	for _, partition := range partitions {
+		kerr, ok := resp.Errors[topic][partition]
+		if !ok {
+			return ErrIncompleteResponse
+		}
+		if kerr != ErrNoError {
+			return kerr
+		}
+	}
+
+	return nil
+}
+
+func (ca *clusterAdmin) DeleteConsumerGroup(group string) error {
+	coordinator, err := ca.client.Coordinator(group)
+	if err != nil {
+		return err
+	}
+
+	request := &DeleteGroupsRequest{
+		Groups: []string{group},
+	}
+
+	resp, err := coordinator.DeleteGroups(request)
+	if err != nil {
+		return err
+	}
+
+	groupErr, ok := resp.GroupErrorCodes[group]
+	if !ok {
+		return ErrIncompleteResponse
+	}
+
+	if groupErr != ErrNoError {
+		return groupErr
+	}
+
+	return nil
+}
+
+func (ca *clusterAdmin) DescribeUserScramCredentials(users []string) ([]*DescribeUserScramCredentialsResult, error) {
+	request := &DescribeUserScramCredentialsRequest{}
+	for _, u := range users {
+		request.DescribeUsers = append(request.DescribeUsers, DescribeUserScramCredentialsRequestUser{
+			Name: u,
+		})
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := b.DescribeUserScramCredentials(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.ErrorMessage != nil && *rsp.ErrorMessage != "" {
+		return nil, errors.New(*rsp.ErrorMessage)
+	}
+	if rsp.ErrorCode != ErrNoError {
+		return nil, rsp.ErrorCode
+	}
+
+	return rsp.Results, nil
+}
+
+func (ca *clusterAdmin) UpsertUserScramCredentials(upsertions []AlterUserScramCredentialsUpsert) ([]*AlterUserScramCredentialsResult, error) {
+	salted := make([]AlterUserScramCredentialsUpsert, len(upsertions))
+	for i, upsertion := range upsertions {
+		if err := upsertion.saltPassword(); err != nil {
+			return nil, err
+		}
+		// the password itself is not sent
+		upsertion.Password = nil
+		salted[i] = upsertion
+	}
+
+	return ca.alterUserScramCredentials(&AlterUserScramCredentialsRequest{
+		Upsertions: salted,
+	})
+}
+
+func (ca *clusterAdmin) DeleteUserScramCredentials(deletions []AlterUserScramCredentialsDelete) ([]*AlterUserScramCredentialsResult, error) {
+	return ca.alterUserScramCredentials(&AlterUserScramCredentialsRequest{
+		Deletions: deletions,
+	})
+}
+
+func (ca *clusterAdmin) alterUserScramCredentials(request *AlterUserScramCredentialsRequest) ([]*AlterUserScramCredentialsResult, error) {
+	b, err := ca.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := b.AlterUserScramCredentials(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return rsp.Results, nil
+}
+
+func (ca *clusterAdmin) DescribeClientQuotas(components []QuotaFilterComponent, strict bool) ([]DescribeClientQuotasEntry, error) {
+	request, err := NewDescribeClientQuotasRequest(components, strict)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := b.DescribeClientQuotas(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.ErrorMsg != nil && len(*rsp.ErrorMsg) > 0 {
+		return nil, errors.New(*rsp.ErrorMsg)
+	}
+	if rsp.ErrorCode != ErrNoError {
+		return nil, rsp.ErrorCode
+	}
+
+	return rsp.Entries, nil
+}
+
+func (ca *clusterAdmin) AlterClientQuotas(entries []AlterClientQuotasEntry, validateOnly bool) error {
+	request := &AlterClientQuotasRequest{
+		Entries:      entries,
+		ValidateOnly: validateOnly,
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return err
+	}
+
+	rsp, err := b.AlterClientQuotas(request)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range rsp.Entries {
+		if entry.ErrorMsg != nil && len(*entry.ErrorMsg) > 0 {
+			return errors.New(*entry.ErrorMsg)
+		}
+		if entry.ErrorCode != ErrNoError {
+			return entry.ErrorCode
+		}
+	}
+
+	return nil
+}
+
+func (ca *clusterAdmin) delegationTokenVersion() int16 {
+	if ca.conf.Version.IsAtLeast(V2_0_0_0) {
+		return 1
+	}
+	return 0
+}
+
+func (ca *clusterAdmin) CreateDelegationToken(renewers []KafkaPrincipal, maxLifetime time.Duration) (*DelegationToken, error) {
+	request := &CreateDelegationTokenRequest{
+		Version:     ca.delegationTokenVersion(),
+		Renewers:    renewers,
+		MaxLifetime: maxLifetime,
+	}
+	if maxLifetime <= 0 {
+		request.MaxLifetime = -1
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := b.CreateDelegationToken(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.ErrorCode != ErrNoError {
+		return nil, rsp.ErrorCode
+	}
+
+	return &rsp.Token, nil
+}
+
+func (ca *clusterAdmin) RenewDelegationToken(hmac []byte, renewPeriod time.Duration) (time.Time, error) {
+	request := &RenewDelegationTokenRequest{
+		Version:     ca.delegationTokenVersion(),
+		HMAC:        hmac,
+		RenewPeriod: renewPeriod,
+	}
+	if renewPeriod <= 0 {
+		request.RenewPeriod = -1
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	rsp, err := b.RenewDelegationToken(request)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if rsp.ErrorCode != ErrNoError {
+		return time.Time{}, rsp.ErrorCode
+	}
+
+	return rsp.ExpiryTime, nil
+}
+
+func (ca *clusterAdmin) ExpireDelegationToken(hmac []byte, expiryPeriod time.Duration) (time.Time, error) {
+	request := &ExpireDelegationTokenRequest{
+		Version:      ca.delegationTokenVersion(),
+		HMAC:         hmac,
+		ExpiryPeriod: expiryPeriod,
+	}
+	if expiryPeriod <= 0 {
+		request.ExpiryPeriod = -1
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	rsp, err := b.ExpireDelegationToken(request)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if rsp.ErrorCode != ErrNoError {
+		return time.Time{}, rsp.ErrorCode
+	}
+
+	return rsp.ExpiryTime, nil
+}
+
+func (ca *clusterAdmin) DescribeDelegationToken(owners []KafkaPrincipal) ([]DelegationToken, error) {
+	request := &DescribeDelegationTokenRequest{
+		Version: ca.delegationTokenVersion(),
+	}
+	if len(owners) > 0 {
+		request.Owners = owners
+	}
+
+	b, err := ca.Controller()
+	if err != nil {
+		return nil, err
+	}
+
+	rsp, err := b.DescribeDelegationToken(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if rsp.ErrorCode != ErrNoError {
+		return nil, rsp.ErrorCode
+	}
+
+	return rsp.Tokens, nil
+}
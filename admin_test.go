@@ -2,8 +2,11 @@ package sarama
 
 import (
 	"errors"
+	"reflect"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestClusterAdmin(t *testing.T) {
@@ -332,6 +335,47 @@ func TestClusterAdminCreatePartitionsWithoutAuthorization(t *testing.T) {
 	}
 }
 
+func TestClusterAdminElectLeaders(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"ElectLeadersRequest": NewMockElectLeadersResponse(t).
+			SetPartitionError("my_topic", 1, ErrElectionNotNeeded),
+	})
+
+	config := NewConfig()
+	config.Version = V2_2_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := admin.ElectLeaders(PreferredElection, map[string][]int32{"my_topic": {0, 1}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["my_topic"][0].Err != ErrNoError {
+		t.Errorf("Expected no error for partition 0, got %v", results["my_topic"][0].Err)
+	}
+	if results["my_topic"][1].Err != ErrElectionNotNeeded {
+		t.Errorf("Expected ErrElectionNotNeeded for partition 1, got %v", results["my_topic"][1].Err)
+	}
+
+	_, err = admin.ElectLeaders(UncleanElection, map[string][]int32{"my_topic": {0}})
+	if err != ErrUnsupportedVersion {
+		t.Errorf("Expected ErrUnsupportedVersion for an unclean election, got %v", err)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestClusterAdminDeleteRecords(t *testing.T) {
 	seedBroker := NewMockBroker(t, 1)
 	defer seedBroker.Close()
@@ -467,6 +511,52 @@ func TestClusterAdminAlterConfig(t *testing.T) {
 	}
 }
 
+func TestClusterAdminIncrementalAlterConfig(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"IncrementalAlterConfigsRequest": NewMockIncrementalAlterConfigsResponse(t),
+	})
+
+	config := NewConfig()
+	config.Version = V2_3_0_0
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	value := "86400000"
+	err = admin.IncrementalAlterConfig(TopicResource, "my_topic", map[string]IncrementalAlterConfigsEntry{
+		"retention.ms":  {Operation: IncrementalAlterConfigsOperationSet, Value: &value},
+		"segment.bytes": {Operation: IncrementalAlterConfigsOperationDelete},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	level := "DEBUG"
+	err = admin.IncrementalAlterConfig(BrokerLoggerResource, strconv.Itoa(int(seedBroker.BrokerID())), map[string]IncrementalAlterConfigsEntry{
+		"kafka.controller": {Operation: IncrementalAlterConfigsOperationSet, Value: &level},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.IncrementalAlterConfig(BrokerResource, "not-a-broker-id", nil, false)
+	if err == nil {
+		t.Error("Expected an error for an invalid broker ID")
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestClusterAdminCreateAcl(t *testing.T) {
 	seedBroker := NewMockBroker(t, 1)
 	defer seedBroker.Close()
@@ -859,3 +949,518 @@ func TestListConsumerGroupOffsets(t *testing.T) {
 	}
 
 }
+
+func TestDeleteConsumerGroup(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	group := "my-group"
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"DeleteGroupsRequest": NewMockDeleteGroupsResponse(t).SetDeletedGroups([]string{group}),
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+			SetCoordinator(CoordinatorGroup, group, seedBroker).
+			SetCoordinator(CoordinatorGroup, "unknown-group", seedBroker),
+	})
+
+	config := NewConfig()
+	config.Version = V1_1_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.DeleteConsumerGroup(group)
+	if err != nil {
+		t.Fatalf("DeleteConsumerGroup failed with error %v", err)
+	}
+
+	err = admin.DeleteConsumerGroup("unknown-group")
+	if err != ErrIncompleteResponse {
+		t.Fatalf("Expected ErrIncompleteResponse for an unknown group, got %v", err)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteConsumerGroupOffsets(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	group := "my-group"
+	topic := "my-topic"
+	partition := int32(0)
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+			SetCoordinator(CoordinatorGroup, group, seedBroker),
+		"DeleteOffsetsRequest": NewMockDeleteOffsetResponse(t).
+			SetDeletedOffset(ErrNoError, topic, partition, ErrNoError),
+	})
+
+	config := NewConfig()
+	config.Version = V2_4_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.DeleteConsumerGroupOffsets(group, topic, []int32{partition})
+	if err != nil {
+		t.Fatalf("DeleteConsumerGroupOffsets failed with error %v", err)
+	}
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+			SetCoordinator(CoordinatorGroup, group, seedBroker),
+		"DeleteOffsetsRequest": NewMockDeleteOffsetResponse(t).
+			SetDeletedOffset(ErrNoError, topic, partition, ErrGroupSubscribedToTopic),
+	})
+
+	err = admin.DeleteConsumerGroupOffsets(group, topic, []int32{partition})
+	if err != ErrGroupSubscribedToTopic {
+		t.Fatalf("Expected ErrGroupSubscribedToTopic, got %v", err)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDescribeLogDirs(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"DescribeLogDirsRequest": NewMockDescribeLogDirsResponse(t).
+			SetLogDirs("/tmp/logs", map[string]int{"topic1": 2, "topic2": 2}),
+	})
+
+	config := NewConfig()
+	config.Version = V1_0_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logDirsPerBroker, err := admin.DescribeLogDirs([]int32{seedBroker.BrokerID()})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(logDirsPerBroker) != 1 {
+		t.Fatalf("Expected %v results, got %v", 1, len(logDirsPerBroker))
+	}
+	logDirs := logDirsPerBroker[seedBroker.BrokerID()]
+	if len(logDirs) != 1 {
+		t.Fatalf("Expected log dirs for broker %v to be returned, but it did not, got %v", seedBroker.BrokerID(), len(logDirs))
+	}
+	logDirsBroker := logDirs[0]
+	if logDirsBroker.ErrorCode != ErrNoError {
+		t.Fatalf("Expected no error for broker %v, but it was %v", seedBroker.BrokerID(), logDirsBroker.ErrorCode)
+	}
+	if logDirsBroker.Path != "/tmp/logs" {
+		t.Fatalf("Expected log dirs for broker %v to be '/tmp/logs', but it was %v", seedBroker.BrokerID(), logDirsBroker.Path)
+	}
+	if len(logDirsBroker.Topics) != 2 {
+		t.Fatalf("Expected log dirs for broker %v to have 2 topics, but it had %v", seedBroker.BrokerID(), len(logDirsBroker.Topics))
+	}
+
+	_, err = admin.DescribeLogDirs([]int32{seedBroker.BrokerID() + 1})
+	if err == nil {
+		t.Error("Expected an error describing the log dirs of an unknown broker")
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAlterReplicaLogDirs(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"AlterReplicaLogDirsRequest": NewMockAlterReplicaLogDirsResponse(t).
+			SetError("topic1", 1, ErrLogDirNotFound),
+	})
+
+	config := NewConfig()
+	config.Version = V2_0_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := admin.AlterReplicaLogDirs(seedBroker.BrokerID(), map[string]map[int32]string{
+		"topic1": {0: "/disk1", 1: "/disk2"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results["topic1"][0] != ErrNoError {
+		t.Errorf("Expected no error moving partition 0, got %v", results["topic1"][0])
+	}
+	if results["topic1"][1] != ErrLogDirNotFound {
+		t.Errorf("Expected ErrLogDirNotFound moving partition 1, got %v", results["topic1"][1])
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDescribeUserScramCredentials(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"DescribeUserScramCredentialsRequest": NewMockDescribeUserScramCredentialsResponse(t).
+			SetCredential("alice", ScramMechanismSHA256, 4096).
+			SetCredential("alice", ScramMechanismSHA512, 8192),
+	})
+
+	config := NewConfig()
+	config.Version = V2_7_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := admin.DescribeUserScramCredentials([]string{"alice", "bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("Expected 2 results, got %d", len(results))
+	}
+	if results[0].User != "alice" || len(results[0].CredentialInfos) != 2 {
+		t.Errorf("Unexpected result for alice %+v", results[0])
+	}
+	if results[0].CredentialInfos[1].Mechanism != ScramMechanismSHA512 || results[0].CredentialInfos[1].Iterations != 8192 {
+		t.Errorf("Unexpected credential for alice %+v", results[0].CredentialInfos[1])
+	}
+	if results[1].User != "bob" || results[1].ErrorCode != ErrResourceNotFound {
+		t.Errorf("Unexpected result for bob %+v", results[1])
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpsertUserScramCredentials(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"AlterUserScramCredentialsRequest": NewMockAlterUserScramCredentialsResponse(t).
+			SetError("bob", ErrUnacceptableCredential),
+	})
+
+	config := NewConfig()
+	config.Version = V2_7_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	upsertions := []AlterUserScramCredentialsUpsert{
+		{Name: "alice", Mechanism: ScramMechanismSHA256, Password: []byte("alice-secret")},
+		{Name: "bob", Mechanism: ScramMechanismSHA512, Iterations: 100, Password: []byte("bob-secret")},
+	}
+	results, err := admin.UpsertUserScramCredentials(upsertions)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ErrorCode != ErrNoError || results[1].ErrorCode != ErrUnacceptableCredential {
+		t.Errorf("Unexpected results %+v", results)
+	}
+	if upsertions[0].SaltedPassword != nil {
+		t.Error("Expected the given upsertions to be left untouched")
+	}
+
+	var request *AlterUserScramCredentialsRequest
+	for _, rr := range seedBroker.History() {
+		if r, ok := rr.Request.(*AlterUserScramCredentialsRequest); ok {
+			request = r
+		}
+	}
+	if request == nil {
+		t.Fatal("Expected an AlterUserScramCredentialsRequest")
+	}
+	for _, upsertion := range request.Upsertions {
+		if upsertion.Password != nil {
+			t.Errorf("Password of %s was sent", upsertion.Name)
+		}
+		if len(upsertion.Salt) == 0 || len(upsertion.SaltedPassword) == 0 {
+			t.Errorf("Missing salted password of %s", upsertion.Name)
+		}
+	}
+	if request.Upsertions[0].Iterations != 4096 || request.Upsertions[1].Iterations != 100 {
+		t.Errorf("Unexpected iterations %d and %d", request.Upsertions[0].Iterations, request.Upsertions[1].Iterations)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDeleteUserScramCredentials(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"AlterUserScramCredentialsRequest": NewMockAlterUserScramCredentialsResponse(t).
+			SetError("bob", ErrResourceNotFound),
+	})
+
+	config := NewConfig()
+	config.Version = V2_7_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := admin.DeleteUserScramCredentials([]AlterUserScramCredentialsDelete{
+		{Name: "alice", Mechanism: ScramMechanismSHA256},
+		{Name: "bob", Mechanism: ScramMechanismSHA512},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].ErrorCode != ErrNoError || results[1].ErrorCode != ErrResourceNotFound {
+		t.Errorf("Unexpected results %+v", results)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDescribeClientQuotas(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	alice := []QuotaEntityComponent{{EntityType: QuotaEntityUser, MatchType: QuotaMatchExact, Name: "alice"}}
+	defaultUser := []QuotaEntityComponent{{EntityType: QuotaEntityUser, MatchType: QuotaMatchDefault}}
+	aliceClient := []QuotaEntityComponent{
+		{EntityType: QuotaEntityUser, MatchType: QuotaMatchExact, Name: "alice"},
+		{EntityType: QuotaEntityClientID, MatchType: QuotaMatchExact, Name: "app"},
+	}
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"DescribeClientQuotasRequest": NewMockDescribeClientQuotasResponse(t).
+			AddEntry(alice, map[string]float64{QuotaProducerByteRate: 1024}).
+			AddEntry(defaultUser, map[string]float64{QuotaConsumerByteRate: 2048}).
+			AddEntry(aliceClient, map[string]float64{QuotaRequestPercentage: 50}),
+	})
+
+	config := NewConfig()
+	config.Version = V2_6_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filter := []QuotaFilterComponent{{EntityType: QuotaEntityUser, MatchType: QuotaMatchExact, Match: "alice"}}
+	entries, err := admin.DescribeClientQuotas(filter, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+	if entries[0].Values[QuotaProducerByteRate] != 1024 || entries[1].Values[QuotaRequestPercentage] != 50 {
+		t.Errorf("Unexpected entries %+v", entries)
+	}
+
+	entries, err = admin.DescribeClientQuotas(filter, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || !reflect.DeepEqual(entries[0].Entity, alice) {
+		t.Errorf("Expected only the quotas of alice, got %+v", entries)
+	}
+
+	if _, err = admin.DescribeClientQuotas([]QuotaFilterComponent{{EntityType: QuotaEntityUser}}, false); err == nil {
+		t.Error("Expected an error for an exact filter component without match")
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAlterClientQuotas(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	tenant := []QuotaEntityComponent{{EntityType: QuotaEntityUser, MatchType: QuotaMatchExact, Name: "noisy-tenant"}}
+	ip := []QuotaEntityComponent{{EntityType: QuotaEntityIP, MatchType: QuotaMatchExact, Name: "10.0.0.1"}}
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"AlterClientQuotasRequest": NewMockAlterClientQuotasResponse(t).
+			SetError(ip, ErrInvalidRequest),
+	})
+
+	config := NewConfig()
+	config.Version = V2_6_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.AlterClientQuotas([]AlterClientQuotasEntry{{
+		Entity: tenant,
+		Ops: []ClientQuotasOp{
+			{Key: QuotaProducerByteRate, Value: 1024},
+			{Key: QuotaConsumerByteRate, Remove: true},
+		},
+	}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = admin.AlterClientQuotas([]AlterClientQuotasEntry{{
+		Entity: ip,
+		Ops:    []ClientQuotasOp{{Key: QuotaRequestPercentage, Value: 10}},
+	}}, true)
+	if err != ErrInvalidRequest {
+		t.Errorf("Expected ErrInvalidRequest, got %v", err)
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDelegationTokens(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	defer seedBroker.Close()
+
+	owner := KafkaPrincipal{Type: "User", Name: "batch"}
+	worker := KafkaPrincipal{Type: "User", Name: "worker"}
+
+	seedBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetController(seedBroker.BrokerID()).
+			SetBroker(seedBroker.Addr(), seedBroker.BrokerID()),
+		"CreateDelegationTokenRequest": NewMockCreateDelegationTokenResponse(t).
+			SetToken(owner, "token-id", []byte{1, 2, 3}),
+		"RenewDelegationTokenRequest": NewMockRenewDelegationTokenResponse(t),
+		"ExpireDelegationTokenRequest": NewMockExpireDelegationTokenResponse(t).
+			SetError(ErrDelegationTokenOwnerMismatch),
+		"DescribeDelegationTokenRequest": NewMockDescribeDelegationTokenResponse(t).
+			AddToken(DelegationToken{Owner: owner, TokenID: "token-id", HMAC: []byte{1, 2, 3}, Renewers: []KafkaPrincipal{worker}}).
+			AddToken(DelegationToken{Owner: worker, TokenID: "other-id", HMAC: []byte{4}}),
+	})
+
+	config := NewConfig()
+	config.Version = V2_0_0_0
+
+	admin, err := NewClusterAdmin([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token, err := admin.CreateDelegationToken([]KafkaPrincipal{worker}, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.TokenID != "token-id" || token.Owner != owner {
+		t.Errorf("Unexpected token %+v", token)
+	}
+	if lifetime := token.MaxTime.Sub(token.IssueTime); lifetime != time.Hour {
+		t.Errorf("Expected a max lifetime of an hour, got %s", lifetime)
+	}
+
+	expiry, err := admin.RenewDelegationToken(token.HMAC, 2*time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expiry.Before(time.Now().Add(time.Hour)) {
+		t.Errorf("Unexpected expiry time %s", expiry)
+	}
+
+	if _, err = admin.ExpireDelegationToken(token.HMAC, 0); err != ErrDelegationTokenOwnerMismatch {
+		t.Errorf("Expected ErrDelegationTokenOwnerMismatch, got %v", err)
+	}
+
+	tokens, err := admin.DescribeDelegationToken([]KafkaPrincipal{owner})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 1 || tokens[0].TokenID != "token-id" || len(tokens[0].Renewers) != 1 {
+		t.Errorf("Unexpected tokens %+v", tokens)
+	}
+
+	tokens, err = admin.DescribeDelegationToken(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 2 {
+		t.Errorf("Expected 2 tokens, got %d", len(tokens))
+	}
+
+	for _, rr := range seedBroker.History() {
+		if req, ok := rr.Request.(*ExpireDelegationTokenRequest); ok && req.ExpiryPeriod != -1*time.Millisecond {
+			t.Errorf("Expected the token to be expired immediately, got %s", req.ExpiryPeriod)
+		}
+	}
+
+	err = admin.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
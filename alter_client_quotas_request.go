@@ -0,0 +1,109 @@
+package sarama
+
+// AlterClientQuotasRequest is a request to set or remove the quotas of
+// entities (KIP-546).
+type AlterClientQuotasRequest struct {
+	Entries      []AlterClientQuotasEntry
+	ValidateOnly bool
+}
+
+// AlterClientQuotasEntry holds the operations on the quotas of an entity.
+type AlterClientQuotasEntry struct {
+	Entity []QuotaEntityComponent
+	Ops    []ClientQuotasOp
+}
+
+// ClientQuotasOp sets a quota to Value, or removes it when Remove is set.
+type ClientQuotasOp struct {
+	Key    string
+	Value  float64
+	Remove bool
+}
+
+func (a *AlterClientQuotasRequest) encode(pe packetEncoder) error {
+	if err := pe.putArrayLength(len(a.Entries)); err != nil {
+		return err
+	}
+	for _, e := range a.Entries {
+		if err := e.encode(pe); err != nil {
+			return err
+		}
+	}
+
+	pe.putBool(a.ValidateOnly)
+	return nil
+}
+
+func (a *AlterClientQuotasRequest) decode(pd packetDecoder, version int16) error {
+	entryCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	if entryCount > 0 {
+		a.Entries = make([]AlterClientQuotasEntry, entryCount)
+		for i := range a.Entries {
+			if err = a.Entries[i].decode(pd, version); err != nil {
+				return err
+			}
+		}
+	}
+
+	a.ValidateOnly, err = pd.getBool()
+	return err
+}
+
+func (a *AlterClientQuotasEntry) encode(pe packetEncoder) error {
+	if err := encodeQuotaEntity(pe, a.Entity); err != nil {
+		return err
+	}
+
+	if err := pe.putArrayLength(len(a.Ops)); err != nil {
+		return err
+	}
+	for _, o := range a.Ops {
+		if err := pe.putString(o.Key); err != nil {
+			return err
+		}
+		pe.putFloat64(o.Value)
+		pe.putBool(o.Remove)
+	}
+	return nil
+}
+
+func (a *AlterClientQuotasEntry) decode(pd packetDecoder, version int16) (err error) {
+	if a.Entity, err = decodeQuotaEntity(pd, version); err != nil {
+		return err
+	}
+
+	opCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	if opCount > 0 {
+		a.Ops = make([]ClientQuotasOp, opCount)
+		for i := range a.Ops {
+			if a.Ops[i].Key, err = pd.getString(); err != nil {
+				return err
+			}
+			if a.Ops[i].Value, err = pd.getFloat64(); err != nil {
+				return err
+			}
+			if a.Ops[i].Remove, err = pd.getBool(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *AlterClientQuotasRequest) key() int16 {
+	return 49
+}
+
+func (a *AlterClientQuotasRequest) version() int16 {
+	return 0
+}
+
+func (a *AlterClientQuotasRequest) requiredVersion() KafkaVersion {
+	return V2_6_0_0
+}
@@ -0,0 +1,123 @@
+package sarama
+
+import "testing"
+
+var (
+	alterClientQuotasRequestSingleOp = []byte{
+		0, 0, 0, 1, // entries len
+		0, 0, 0, 1, // entity len
+		0, 4, 'u', 's', 'e', 'r', // entity type
+		255, 255, // entity value (default user)
+		0, 0, 0, 1, // ops len
+		0, 18, 'p', 'r', 'o', 'd', 'u', 'c', 'e', 'r', '_', 'b', 'y', 't', 'e', '_', 'r', 'a', 't', 'e', // op key
+		65, 46, 132, 128, 0, 0, 0, 0, // op value (1000000)
+		0, // remove
+		0, // validate only
+	}
+
+	alterClientQuotasRequestRemoveSingleOp = []byte{
+		0, 0, 0, 1, // entries len
+		0, 0, 0, 1, // entity len
+		0, 2, 'i', 'p', // entity type
+		0, 8, '1', '0', '.', '0', '.', '0', '.', '1', // entity value
+		0, 0, 0, 1, // ops len
+		0, 18, 'c', 'o', 'n', 's', 'u', 'm', 'e', 'r', '_', 'b', 'y', 't', 'e', '_', 'r', 'a', 't', 'e', // op key
+		0, 0, 0, 0, 0, 0, 0, 0, // op value (ignored)
+		1, // remove
+		1, // validate only
+	}
+
+	alterClientQuotasRequestMultipleOps = []byte{
+		0, 0, 0, 1, // entries len
+		0, 0, 0, 2, // entity len
+		0, 4, 'u', 's', 'e', 'r', // entity type
+		0, 5, 'a', 'l', 'i', 'c', 'e', // entity value
+		0, 9, 'c', 'l', 'i', 'e', 'n', 't', '-', 'i', 'd', // entity type
+		255, 255, // entity value (default client-id)
+		0, 0, 0, 2, // ops len
+		0, 18, 'p', 'r', 'o', 'd', 'u', 'c', 'e', 'r', '_', 'b', 'y', 't', 'e', '_', 'r', 'a', 't', 'e', // op key
+		65, 46, 132, 128, 0, 0, 0, 0, // op value (1000000)
+		0,                                                                                               // remove
+		0, 18, 'r', 'e', 'q', 'u', 'e', 's', 't', '_', 'p', 'e', 'r', 'c', 'e', 'n', 't', 'a', 'g', 'e', // op key
+		0, 0, 0, 0, 0, 0, 0, 0, // op value (ignored)
+		1, // remove
+		0, // validate only
+	}
+)
+
+func TestAlterClientQuotasRequest(t *testing.T) {
+	// default user
+	defaultUserComponent := QuotaEntityComponent{
+		EntityType: QuotaEntityUser,
+		MatchType:  QuotaMatchDefault,
+	}
+
+	// Add Quota to default user
+	op := ClientQuotasOp{
+		Key:    QuotaProducerByteRate,
+		Value:  1000000,
+		Remove: false,
+	}
+	entry := AlterClientQuotasEntry{
+		Entity: []QuotaEntityComponent{defaultUserComponent},
+		Ops:    []ClientQuotasOp{op},
+	}
+	req := &AlterClientQuotasRequest{
+		Entries:      []AlterClientQuotasEntry{entry},
+		ValidateOnly: false,
+	}
+	testRequest(t, "Add single Quota op", req, alterClientQuotasRequestSingleOp)
+
+	// IP entity
+	ipComponent := QuotaEntityComponent{
+		EntityType: QuotaEntityIP,
+		MatchType:  QuotaMatchExact,
+		Name:       "10.0.0.1",
+	}
+
+	// Remove Quota from the IP
+	op = ClientQuotasOp{
+		Key:    QuotaConsumerByteRate,
+		Remove: true,
+	}
+	entry = AlterClientQuotasEntry{
+		Entity: []QuotaEntityComponent{ipComponent},
+		Ops:    []ClientQuotasOp{op},
+	}
+	req = &AlterClientQuotasRequest{
+		Entries:      []AlterClientQuotasEntry{entry},
+		ValidateOnly: true,
+	}
+	testRequest(t, "Remove single Quota op", req, alterClientQuotasRequestRemoveSingleOp)
+
+	// user and default client-id
+	userComponent := QuotaEntityComponent{
+		EntityType: QuotaEntityUser,
+		MatchType:  QuotaMatchExact,
+		Name:       "alice",
+	}
+	defaultClientIDComponent := QuotaEntityComponent{
+		EntityType: QuotaEntityClientID,
+		MatchType:  QuotaMatchDefault,
+	}
+
+	// Add and Remove Quotas of the entity
+	addOp := ClientQuotasOp{
+		Key:    QuotaProducerByteRate,
+		Value:  1000000,
+		Remove: false,
+	}
+	removeOp := ClientQuotasOp{
+		Key:    QuotaRequestPercentage,
+		Remove: true,
+	}
+	entry = AlterClientQuotasEntry{
+		Entity: []QuotaEntityComponent{userComponent, defaultClientIDComponent},
+		Ops:    []ClientQuotasOp{addOp, removeOp},
+	}
+	req = &AlterClientQuotasRequest{
+		Entries:      []AlterClientQuotasEntry{entry},
+		ValidateOnly: false,
+	}
+	testRequest(t, "Add and Remove multiple Quota ops", req, alterClientQuotasRequestMultipleOps)
+}
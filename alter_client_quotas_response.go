@@ -0,0 +1,77 @@
+package sarama
+
+import "time"
+
+type AlterClientQuotasResponse struct {
+	ThrottleTime time.Duration
+	Entries      []AlterClientQuotasEntryResponse
+}
+
+// AlterClientQuotasEntryResponse holds the result of the alteration of the
+// quotas of an entity.
+type AlterClientQuotasEntryResponse struct {
+	ErrorCode KError
+	ErrorMsg  *string
+	Entity    []QuotaEntityComponent
+}
+
+func (a *AlterClientQuotasResponse) encode(pe packetEncoder) error {
+	pe.putInt32(int32(a.ThrottleTime / time.Millisecond))
+
+	if err := pe.putArrayLength(len(a.Entries)); err != nil {
+		return err
+	}
+	for _, e := range a.Entries {
+		pe.putInt16(int16(e.ErrorCode))
+		if err := pe.putNullableString(e.ErrorMsg); err != nil {
+			return err
+		}
+		if err := encodeQuotaEntity(pe, e.Entity); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AlterClientQuotasResponse) decode(pd packetDecoder, version int16) error {
+	throttleTime, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	a.ThrottleTime = time.Duration(throttleTime) * time.Millisecond
+
+	entryCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	if entryCount > 0 {
+		a.Entries = make([]AlterClientQuotasEntryResponse, entryCount)
+		for i := range a.Entries {
+			errorCode, err := pd.getInt16()
+			if err != nil {
+				return err
+			}
+			a.Entries[i].ErrorCode = KError(errorCode)
+
+			if a.Entries[i].ErrorMsg, err = pd.getNullableString(); err != nil {
+				return err
+			}
+			if a.Entries[i].Entity, err = decodeQuotaEntity(pd, version); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (a *AlterClientQuotasResponse) key() int16 {
+	return 49
+}
+
+func (a *AlterClientQuotasResponse) version() int16 {
+	return 0
+}
+
+func (a *AlterClientQuotasResponse) requiredVersion() KafkaVersion {
+	return V2_6_0_0
+}
@@ -0,0 +1,61 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	alterClientQuotasResponseError = []byte{
+		0, 0, 0, 0, // ThrottleTime
+		0, 0, 0, 1, // Entries len
+		0, 42, // ErrorCode
+		0, 23, 'I', 'n', 'v', 'a', 'l', 'i', 'd', ' ', 'q', 'u', 'o', 't', 'a', ' ', 'e', 'n', 't', 'i', 't', 'y', ' ', 'i', 'p',
+		0, 0, 0, 1, // Entity len
+		0, 2, 'i', 'p', // entity type
+		0, 3, '1', '.', '2', // entity name
+	}
+
+	alterClientQuotasResponseSuccess = []byte{
+		0, 0, 0, 50, // ThrottleTime
+		0, 0, 0, 1, // Entries len
+		0, 0, // ErrorCode
+		255, 255, // ErrorMsg
+		0, 0, 0, 2, // Entity len
+		0, 4, 'u', 's', 'e', 'r', // entity type
+		0, 5, 'a', 'l', 'i', 'c', 'e', // entity name
+		0, 9, 'c', 'l', 'i', 'e', 'n', 't', '-', 'i', 'd', // entity type
+		255, 255, // entity name (default)
+	}
+)
+
+func TestAlterClientQuotasResponse(t *testing.T) {
+	// Response With Error
+	errMsg := "Invalid quota entity ip"
+	res := &AlterClientQuotasResponse{
+		Entries: []AlterClientQuotasEntryResponse{
+			{
+				ErrorCode: ErrInvalidRequest,
+				ErrorMsg:  &errMsg,
+				Entity: []QuotaEntityComponent{
+					{EntityType: QuotaEntityIP, MatchType: QuotaMatchExact, Name: "1.2"},
+				},
+			},
+		},
+	}
+	testResponse(t, "Response With Error", res, alterClientQuotasResponseError)
+
+	// Response Success
+	res = &AlterClientQuotasResponse{
+		ThrottleTime: 50 * time.Millisecond,
+		Entries: []AlterClientQuotasEntryResponse{
+			{
+				Entity: []QuotaEntityComponent{
+					{EntityType: QuotaEntityUser, MatchType: QuotaMatchExact, Name: "alice"},
+					{EntityType: QuotaEntityClientID, MatchType: QuotaMatchDefault},
+				},
+			},
+		},
+	}
+	testResponse(t, "Response Success", res, alterClientQuotasResponseSuccess)
+}
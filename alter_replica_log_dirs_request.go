@@ -0,0 +1,128 @@
+package sarama
+
+import "sort"
+
+// request message format is:
+// [log_dir]
+// where log_dir is:
+//  path(string) [topic]
+// where topic is:
+//  name(string) [partition_id(int32)]
+
+// AlterReplicaLogDirsRequest moves replicas to other log directories of the
+// broker it is sent to.
+type AlterReplicaLogDirsRequest struct {
+	// Version 0 and 1 are equal
+	// The version number is bumped to indicate that on quota violation brokers send out responses before throttling.
+	Version int16
+
+	// LogDirs maps each destination log directory path to the partitions of
+	// every topic to move there.
+	LogDirs map[string]map[string][]int32
+}
+
+// AddPartition adds a partition of topic to move to the log directory at path.
+func (r *AlterReplicaLogDirsRequest) AddPartition(path string, topic string, partition int32) {
+	if r.LogDirs == nil {
+		r.LogDirs = make(map[string]map[string][]int32)
+	}
+	if r.LogDirs[path] == nil {
+		r.LogDirs[path] = make(map[string][]int32)
+	}
+	r.LogDirs[path][topic] = append(r.LogDirs[path][topic], partition)
+}
+
+func (r *AlterReplicaLogDirsRequest) encode(pe packetEncoder) error {
+	if err := pe.putArrayLength(len(r.LogDirs)); err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(r.LogDirs))
+	for path := range r.LogDirs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := pe.putString(path); err != nil {
+			return err
+		}
+
+		topics := r.LogDirs[path]
+		if err := pe.putArrayLength(len(topics)); err != nil {
+			return err
+		}
+
+		names := make([]string, 0, len(topics))
+		for topic := range topics {
+			names = append(names, topic)
+		}
+		sort.Strings(names)
+
+		for _, topic := range names {
+			if err := pe.putString(topic); err != nil {
+				return err
+			}
+			if err := pe.putInt32Array(topics[topic]); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *AlterReplicaLogDirsRequest) decode(pd packetDecoder, version int16) error {
+	r.Version = version
+
+	numDirs, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+
+	if numDirs > 0 {
+		r.LogDirs = make(map[string]map[string][]int32, numDirs)
+	}
+	for i := 0; i < numDirs; i++ {
+		path, err := pd.getString()
+		if err != nil {
+			return err
+		}
+
+		numTopics, err := pd.getArrayLength()
+		if err != nil {
+			return err
+		}
+
+		topics := make(map[string][]int32, numTopics)
+		for j := 0; j < numTopics; j++ {
+			topic, err := pd.getString()
+			if err != nil {
+				return err
+			}
+			if topics[topic], err = pd.getInt32Array(); err != nil {
+				return err
+			}
+		}
+		r.LogDirs[path] = topics
+	}
+
+	return nil
+}
+
+func (r *AlterReplicaLogDirsRequest) key() int16 {
+	return 34
+}
+
+func (r *AlterReplicaLogDirsRequest) version() int16 {
+	return r.Version
+}
+
+func (r *AlterReplicaLogDirsRequest) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V2_0_0_0
+	default:
+		return V1_0_0_0
+	}
+}
@@ -0,0 +1,27 @@
+package sarama
+
+import "testing"
+
+var alterReplicaLogDirsRequest = []byte{
+	0, 0, 0, 2, // 2 log dirs
+	0, 6, '/', 'd', 'i', 's', 'k', '1',
+	0, 0, 0, 1, // 1 topic
+	0, 3, 'f', 'o', 'o',
+	0, 0, 0, 2, // 2 partitions
+	0, 0, 0, 0, // partition 0
+	0, 0, 0, 2, // partition 2
+	0, 6, '/', 'd', 'i', 's', 'k', '2',
+	0, 0, 0, 1, // 1 topic
+	0, 3, 'f', 'o', 'o',
+	0, 0, 0, 1, // 1 partition
+	0, 0, 0, 1, // partition 1
+}
+
+func TestAlterReplicaLogDirsRequest(t *testing.T) {
+	request := new(AlterReplicaLogDirsRequest)
+	request.AddPartition("/disk1", "foo", 0)
+	request.AddPartition("/disk2", "foo", 1)
+	request.AddPartition("/disk1", "foo", 2)
+
+	testRequest(t, "two log dirs", request, alterReplicaLogDirsRequest)
+}
@@ -0,0 +1,95 @@
+package sarama
+
+import "time"
+
+// AlterReplicaLogDirsResponse holds the result of moving each replica
+// requested in an AlterReplicaLogDirsRequest.
+type AlterReplicaLogDirsResponse struct {
+	// Version 0 and 1 are equal
+	// The version number is bumped to indicate that on quota violation brokers send out responses before throttling.
+	Version      int16
+	ThrottleTime time.Duration
+	Results      map[string]map[int32]KError
+}
+
+func (r *AlterReplicaLogDirsResponse) encode(pe packetEncoder) error {
+	pe.putInt32(int32(r.ThrottleTime / time.Millisecond))
+
+	if err := pe.putArrayLength(len(r.Results)); err != nil {
+		return err
+	}
+	for topic, partitions := range r.Results {
+		if err := pe.putString(topic); err != nil {
+			return err
+		}
+		if err := pe.putArrayLength(len(partitions)); err != nil {
+			return err
+		}
+		for partition, kerr := range partitions {
+			pe.putInt32(partition)
+			pe.putInt16(int16(kerr))
+		}
+	}
+
+	return nil
+}
+
+func (r *AlterReplicaLogDirsResponse) decode(pd packetDecoder, version int16) error {
+	r.Version = version
+
+	throttleTime, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	r.ThrottleTime = time.Duration(throttleTime) * time.Millisecond
+
+	numTopics, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+
+	r.Results = make(map[string]map[int32]KError, numTopics)
+	for i := 0; i < numTopics; i++ {
+		topic, err := pd.getString()
+		if err != nil {
+			return err
+		}
+
+		numPartitions, err := pd.getArrayLength()
+		if err != nil {
+			return err
+		}
+
+		r.Results[topic] = make(map[int32]KError, numPartitions)
+		for j := 0; j < numPartitions; j++ {
+			partition, err := pd.getInt32()
+			if err != nil {
+				return err
+			}
+			kerr, err := pd.getInt16()
+			if err != nil {
+				return err
+			}
+			r.Results[topic][partition] = KError(kerr)
+		}
+	}
+
+	return nil
+}
+
+func (r *AlterReplicaLogDirsResponse) key() int16 {
+	return 34
+}
+
+func (r *AlterReplicaLogDirsResponse) version() int16 {
+	return r.Version
+}
+
+func (r *AlterReplicaLogDirsResponse) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V2_0_0_0
+	default:
+		return V1_0_0_0
+	}
+}
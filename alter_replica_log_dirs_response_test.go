@@ -0,0 +1,26 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var alterReplicaLogDirsResponse = []byte{
+	0, 0, 0, 100, // throttle time 100 ms
+	0, 0, 0, 1, // 1 topic
+	0, 3, 'f', 'o', 'o',
+	0, 0, 0, 1, // 1 partition
+	0, 0, 0, 2, // partition 2
+	0, 57, // ErrLogDirNotFound
+}
+
+func TestAlterReplicaLogDirsResponse(t *testing.T) {
+	response := &AlterReplicaLogDirsResponse{
+		ThrottleTime: 100 * time.Millisecond,
+		Results: map[string]map[int32]KError{
+			"foo": {2: ErrLogDirNotFound},
+		},
+	}
+
+	testResponse(t, "one partition", response, alterReplicaLogDirsResponse)
+}
@@ -0,0 +1,192 @@
+package sarama
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"hash"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+const (
+	// the default number of iterations of the Java tool kafka-configs
+	defaultScramIterations = 4096
+
+	scramSaltLength = 32
+)
+
+// AlterUserScramCredentialsRequest is a request to delete and upsert the
+// SCRAM credentials of users (KIP-554).
+type AlterUserScramCredentialsRequest struct {
+	// Version 0 is currently only supported
+	Version int16
+
+	// Deletions represent list of SCRAM credentials to remove
+	Deletions []AlterUserScramCredentialsDelete
+
+	// Upsertions represent list of SCRAM credentials to update/insert
+	Upsertions []AlterUserScramCredentialsUpsert
+}
+
+type AlterUserScramCredentialsDelete struct {
+	Name      string
+	Mechanism ScramMechanismType
+}
+
+type AlterUserScramCredentialsUpsert struct {
+	Name       string
+	Mechanism  ScramMechanismType
+	Iterations int32
+	Salt       []byte
+
+	// SaltedPassword is the password salted and hashed with Mechanism, as
+	// computed by ClusterAdmin.UpsertUserScramCredentials from Password.
+	SaltedPassword []byte
+
+	// Password is never sent to the brokers, only its salted version is.
+	Password []byte
+}
+
+// saltPassword sets SaltedPassword from Password, as the SCRAM Hi() function of
+// RFC 5802 does, generating a random salt and using the default number of
+// iterations when they are not set.
+func (u *AlterUserScramCredentialsUpsert) saltPassword() error {
+	var h func() hash.Hash
+	switch u.Mechanism {
+	case ScramMechanismSHA256:
+		h = sha256.New
+	case ScramMechanismSHA512:
+		h = sha512.New
+	default:
+		return ConfigurationError("unknown SCRAM mechanism for user " + u.Name)
+	}
+
+	if len(u.Password) == 0 {
+		if len(u.SaltedPassword) == 0 {
+			return ConfigurationError("no password for user " + u.Name)
+		}
+		return nil
+	}
+
+	if u.Iterations == 0 {
+		u.Iterations = defaultScramIterations
+	}
+	if len(u.Salt) == 0 {
+		u.Salt = make([]byte, scramSaltLength)
+		if _, err := rand.Read(u.Salt); err != nil {
+			return errors.New("could not generate a SCRAM salt: " + err.Error())
+		}
+	}
+
+	u.SaltedPassword = pbkdf2.Key(u.Password, u.Salt, int(u.Iterations), h().Size(), h)
+	return nil
+}
+
+func (r *AlterUserScramCredentialsRequest) encode(pe packetEncoder) error {
+	pe.putCompactArrayLength(len(r.Deletions))
+	for _, d := range r.Deletions {
+		if err := pe.putCompactString(d.Name); err != nil {
+			return err
+		}
+		pe.putInt8(int8(d.Mechanism))
+		pe.putEmptyTaggedFieldArray()
+	}
+
+	pe.putCompactArrayLength(len(r.Upsertions))
+	for _, u := range r.Upsertions {
+		if err := pe.putCompactString(u.Name); err != nil {
+			return err
+		}
+		pe.putInt8(int8(u.Mechanism))
+		pe.putInt32(u.Iterations)
+
+		if err := pe.putCompactBytes(u.Salt); err != nil {
+			return err
+		}
+		if err := pe.putCompactBytes(u.SaltedPassword); err != nil {
+			return err
+		}
+		pe.putEmptyTaggedFieldArray()
+	}
+
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *AlterUserScramCredentialsRequest) decode(pd packetDecoder, version int16) error {
+	r.Version = version
+
+	numDeletions, err := pd.getCompactArrayLength()
+	if err != nil {
+		return err
+	}
+
+	if numDeletions > 0 {
+		r.Deletions = make([]AlterUserScramCredentialsDelete, numDeletions)
+		for i := 0; i < numDeletions; i++ {
+			if r.Deletions[i].Name, err = pd.getCompactString(); err != nil {
+				return err
+			}
+			mechanism, err := pd.getInt8()
+			if err != nil {
+				return err
+			}
+			r.Deletions[i].Mechanism = ScramMechanismType(mechanism)
+			if _, err = pd.getEmptyTaggedFieldArray(); err != nil {
+				return err
+			}
+		}
+	}
+
+	numUpsertions, err := pd.getCompactArrayLength()
+	if err != nil {
+		return err
+	}
+
+	if numUpsertions > 0 {
+		r.Upsertions = make([]AlterUserScramCredentialsUpsert, numUpsertions)
+		for i := 0; i < numUpsertions; i++ {
+			if r.Upsertions[i].Name, err = pd.getCompactString(); err != nil {
+				return err
+			}
+			mechanism, err := pd.getInt8()
+			if err != nil {
+				return err
+			}
+			r.Upsertions[i].Mechanism = ScramMechanismType(mechanism)
+			if r.Upsertions[i].Iterations, err = pd.getInt32(); err != nil {
+				return err
+			}
+			if r.Upsertions[i].Salt, err = pd.getCompactBytes(); err != nil {
+				return err
+			}
+			if r.Upsertions[i].SaltedPassword, err = pd.getCompactBytes(); err != nil {
+				return err
+			}
+			if _, err = pd.getEmptyTaggedFieldArray(); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *AlterUserScramCredentialsRequest) key() int16 {
+	return 51
+}
+
+func (r *AlterUserScramCredentialsRequest) version() int16 {
+	return r.Version
+}
+
+func (r *AlterUserScramCredentialsRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *AlterUserScramCredentialsRequest) requiredVersion() KafkaVersion {
+	return V2_7_0_0
+}
@@ -0,0 +1,81 @@
+package sarama
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+var alterUserScramCredentialsRequest = []byte{
+	2,                // 1 deletion
+	4, 'f', 'o', 'o', // user name: foo
+	2,                // SCRAM-SHA-512
+	0,                // empty tagged fields
+	2,                // 1 upsertion
+	4, 'b', 'a', 'r', // user name: bar
+	1,           // SCRAM-SHA-256
+	0, 0, 16, 0, // 4096 iterations
+	3, 1, 2, // salt
+	4, 3, 4, 5, // salted password
+	0, // empty tagged fields
+	0, // empty tagged fields
+}
+
+func TestAlterUserScramCredentialsRequest(t *testing.T) {
+	request := &AlterUserScramCredentialsRequest{
+		Version: 0,
+		Deletions: []AlterUserScramCredentialsDelete{
+			{Name: "foo", Mechanism: ScramMechanismSHA512},
+		},
+		Upsertions: []AlterUserScramCredentialsUpsert{
+			{
+				Name:           "bar",
+				Mechanism:      ScramMechanismSHA256,
+				Iterations:     4096,
+				Salt:           []byte{1, 2},
+				SaltedPassword: []byte{3, 4, 5},
+			},
+		},
+	}
+
+	testRequest(t, "one deletion and one upsertion", request, alterUserScramCredentialsRequest)
+}
+
+func TestAlterUserScramCredentialsUpsertSaltPassword(t *testing.T) {
+	upsertion := &AlterUserScramCredentialsUpsert{
+		Name:       "foo",
+		Mechanism:  ScramMechanismSHA256,
+		Iterations: 4096,
+		Salt:       []byte("salt"),
+		Password:   []byte("password"),
+	}
+	if err := upsertion.saltPassword(); err != nil {
+		t.Fatal(err)
+	}
+
+	// PBKDF2-HMAC-SHA256 test vector
+	expected, _ := hex.DecodeString("c5e478d59288c841aa530db6845c4c8d962893a001ce4e11a4963873aa98134a")
+	if !bytes.Equal(upsertion.SaltedPassword, expected) {
+		t.Errorf("Unexpected salted password %x", upsertion.SaltedPassword)
+	}
+
+	upsertion = &AlterUserScramCredentialsUpsert{
+		Name:      "foo",
+		Mechanism: ScramMechanismSHA512,
+		Password:  []byte("password"),
+	}
+	if err := upsertion.saltPassword(); err != nil {
+		t.Fatal(err)
+	}
+	if upsertion.Iterations != 4096 || len(upsertion.Salt) != 32 || len(upsertion.SaltedPassword) != 64 {
+		t.Errorf("Unexpected default credential %+v", upsertion)
+	}
+
+	upsertion = &AlterUserScramCredentialsUpsert{
+		Name:     "foo",
+		Password: []byte("password"),
+	}
+	if err := upsertion.saltPassword(); err == nil {
+		t.Error("Expected an error for an unknown mechanism")
+	}
+}
@@ -0,0 +1,33 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var alterUserScramCredentialsResponse = []byte{
+	0, 0, 0, 100, // throttle time: 100ms
+	3,                // 2 results
+	4, 'f', 'o', 'o', // user name: foo
+	0, 0, // no error
+	0,                // null error message
+	0,                // empty tagged fields
+	4, 'b', 'a', 'r', // user name: bar
+	0, 93, // ErrUnacceptableCredential
+	4, 'b', 'a', 'd', // error message: bad
+	0, // empty tagged fields
+	0, // empty tagged fields
+}
+
+func TestAlterUserScramCredentialsResponse(t *testing.T) {
+	response := &AlterUserScramCredentialsResponse{
+		Version:      0,
+		ThrottleTime: 100 * time.Millisecond,
+		Results: []*AlterUserScramCredentialsResult{
+			{User: "foo"},
+			{User: "bar", ErrorCode: ErrUnacceptableCredential, ErrorMessage: nullString("bad")},
+		},
+	}
+
+	testResponse(t, "two results", response, alterUserScramCredentialsResponse)
+}
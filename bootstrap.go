@@ -0,0 +1,100 @@
+package sarama
+
+import (
+	"net"
+	"strings"
+)
+
+// ClientDNSLookup is how the client resolves the addresses of the bootstrap
+// brokers, like the `client.dns.lookup` setting of the JVM client.
+type ClientDNSLookup int
+
+const (
+	// DefaultDNSLookup dials the bootstrap addresses as given, the system
+	// picking the IP address of the names resolving to several.
+	DefaultDNSLookup ClientDNSLookup = iota
+	// UseAllDNSIPs expands every bootstrap name to a seed broker per IP address
+	// it resolves to (KIP-302). The seed brokers are dialed by IP, but still
+	// authenticated as the name with TLS and Kerberos.
+	UseAllDNSIPs
+	// ResolveCanonicalBootstrapServersOnly expands every bootstrap name to a
+	// seed broker per IP address it resolves to, named by the canonical name of
+	// the address (KIP-235), which suits TLS and Kerberos.
+	ResolveCanonicalBootstrapServersOnly
+)
+
+// the resolvers, replaced in the tests
+var (
+	lookupHost = net.LookupHost
+	lookupAddr = net.LookupAddr
+)
+
+// bootstrapAddr is the address a seed broker is dialed at, with the name of
+// the host it was resolved from when dialed by IP, which the broker is then
+// authenticated as
+type bootstrapAddr struct {
+	addr     string
+	hostName string
+}
+
+func (a bootstrapAddr) String() string {
+	if a.hostName == "" {
+		return a.addr
+	}
+	return a.addr + " (" + a.hostName + ")"
+}
+
+// resolveBootstrapAddrs expands the bootstrap addresses as per lookup. The
+// addresses which cannot be resolved are kept as given.
+func resolveBootstrapAddrs(addrs []string, lookup ClientDNSLookup) []bootstrapAddr {
+	var resolved []bootstrapAddr
+	seen := make(map[string]bool)
+	add := func(addr, hostName string) {
+		if !seen[addr] {
+			seen[addr] = true
+			resolved = append(resolved, bootstrapAddr{addr: addr, hostName: hostName})
+		}
+	}
+
+	if lookup == DefaultDNSLookup {
+		for _, addr := range addrs {
+			add(addr, "")
+		}
+		return resolved
+	}
+
+	for _, addr := range addrs {
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			add(addr, "")
+			continue
+		}
+
+		ips, err := lookupHost(host)
+		if err != nil || len(ips) == 0 {
+			Logger.Printf("client/brokers failed to resolve bootstrap address %s: %v\n", addr, err)
+			add(addr, "")
+			continue
+		}
+
+		for _, ip := range ips {
+			switch {
+			case lookup == ResolveCanonicalBootstrapServersOnly:
+				name := ip
+				if names, err := lookupAddr(ip); err == nil && len(names) > 0 {
+					name = strings.TrimSuffix(names[0], ".")
+				} else {
+					Logger.Printf("client/brokers failed to find the canonical name of %s: %v\n", ip, err)
+				}
+				add(net.JoinHostPort(name, port), "")
+			case ip == host:
+				add(net.JoinHostPort(ip, port), "")
+			default:
+				add(net.JoinHostPort(ip, port), host)
+			}
+		}
+	}
+
+	Logger.Printf("client/brokers resolved bootstrap addresses %v to %v\n", addrs, resolved)
+	return resolved
+}
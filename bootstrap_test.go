@@ -0,0 +1,166 @@
+package sarama
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+// stubResolvers makes the names of hosts resolve to their IP addresses, and the
+// IP addresses of names to them, until the returned function is called
+func stubResolvers(hosts map[string][]string, names map[string]string) func() {
+	lookupHost = func(host string) ([]string, error) {
+		if ips, ok := hosts[host]; ok {
+			return ips, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	lookupAddr = func(addr string) ([]string, error) {
+		if name, ok := names[addr]; ok {
+			return []string{name + "."}, nil
+		}
+		return nil, errors.New("no such host")
+	}
+	return func() {
+		lookupHost = defaultLookupHost
+		lookupAddr = defaultLookupAddr
+	}
+}
+
+var (
+	defaultLookupHost = lookupHost
+	defaultLookupAddr = lookupAddr
+)
+
+func TestResolveBootstrapAddrs(t *testing.T) {
+	defer stubResolvers(map[string][]string{
+		"bootstrap.test": {"10.0.0.1", "10.0.0.2", "fd00::3"},
+		"kafka-1.test":   {"10.0.0.1"},
+	}, map[string]string{
+		"10.0.0.1": "kafka-1.test",
+		"10.0.0.2": "kafka-2.test",
+	})()
+
+	addrs := []string{"bootstrap.test:9092", "kafka-1.test:9092", "unknown.test:9092"}
+	for _, tc := range []struct {
+		lookup   ClientDNSLookup
+		expected []bootstrapAddr
+	}{
+		{DefaultDNSLookup, []bootstrapAddr{
+			{addr: "bootstrap.test:9092"},
+			{addr: "kafka-1.test:9092"},
+			{addr: "unknown.test:9092"},
+		}},
+		{UseAllDNSIPs, []bootstrapAddr{
+			{addr: "10.0.0.1:9092", hostName: "bootstrap.test"},
+			{addr: "10.0.0.2:9092", hostName: "bootstrap.test"},
+			{addr: "[fd00::3]:9092", hostName: "bootstrap.test"},
+			{addr: "unknown.test:9092"},
+		}},
+		{ResolveCanonicalBootstrapServersOnly, []bootstrapAddr{
+			{addr: "kafka-1.test:9092"},
+			{addr: "kafka-2.test:9092"},
+			{addr: "[fd00::3]:9092"},
+			{addr: "unknown.test:9092"},
+		}},
+	} {
+		if resolved := resolveBootstrapAddrs(addrs, tc.lookup); !reflect.DeepEqual(resolved, tc.expected) {
+			t.Errorf("lookup %d: expected %v, got %v", tc.lookup, tc.expected, resolved)
+		}
+	}
+}
+
+func seedAddrs(brokers []*Broker) []string {
+	addrs := make([]string, 0, len(brokers))
+	for _, broker := range brokers {
+		addrs = append(addrs, broker.Addr())
+	}
+	sort.Strings(addrs)
+	return addrs
+}
+
+func TestClientReresolvesDeadSeedBrokers(t *testing.T) {
+	hosts := map[string][]string{"bootstrap.test": {"10.0.0.1", "10.0.0.2"}}
+	defer stubResolvers(hosts, nil)()
+
+	config := NewConfig()
+	config.Metadata.Full = false
+	config.Net.ClientDNSLookup = UseAllDNSIPs
+	c, err := NewClient([]string{"bootstrap.test:9092"}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, c)
+	client := c.(*client)
+
+	if addrs := seedAddrs(client.seedBrokers); !reflect.DeepEqual(addrs, []string{"10.0.0.1:9092", "10.0.0.2:9092"}) {
+		t.Fatal("expected a seed broker per IP address, got", addrs)
+	}
+
+	// the pods behind the name got replaced
+	hosts["bootstrap.test"] = []string{"10.0.0.3"}
+	for len(client.seedBrokers) > 0 {
+		client.deregisterBroker(client.seedBrokers[0])
+	}
+	client.resurrectDeadBrokers()
+
+	if addrs := seedAddrs(client.seedBrokers); !reflect.DeepEqual(addrs, []string{"10.0.0.3:9092"}) {
+		t.Error("expected the bootstrap name to be resolved again, got", addrs)
+	}
+	if len(client.deadSeeds) != 0 {
+		t.Error("expected the dead seed brokers to be dropped, got", len(client.deadSeeds))
+	}
+}
+
+func TestUseAllDNSIPsAuthenticatesHostName(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+	hostKey, hostCert := ca.issue(t, "bootstrap.test", 2, time.Now().Add(time.Hour))
+	serverNames := make(chan string, 1)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			select {
+			case serverNames <- hello.ServerName:
+			default:
+			}
+			return &tls.Certificate{Certificate: [][]byte{hostCert}, PrivateKey: hostKey}, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockBroker := NewMockBrokerListener(t, 1, listener)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+
+	_, port, err := net.SplitHostPort(mockBroker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stubResolvers(map[string][]string{"bootstrap.test": {"127.0.0.1"}}, nil)()
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	config := NewConfig()
+	config.Net.ClientDNSLookup = UseAllDNSIPs
+	config.Net.TLS.Enable = true
+	config.Net.TLS.Config = &tls.Config{RootCAs: pool}
+	c, err := NewClient([]string{net.JoinHostPort("bootstrap.test", port)}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, c)
+
+	if serverName := <-serverNames; serverName != "bootstrap.test" {
+		t.Error("expected the seed broker to be authenticated as the bootstrap name, got", serverName)
+	}
+	if addrs := seedAddrs(c.(*client).seedBrokers); !reflect.DeepEqual(addrs, []string{mockBroker.Addr()}) {
+		t.Error("expected the seed broker to be dialed by IP address, got", addrs)
+	}
+}
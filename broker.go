@@ -1,11 +1,14 @@
 package sarama
 
 import (
+	"context"
 	"crypto/tls"
+	"encoding/base64"
 	"encoding/binary"
 	"fmt"
 	metrics "github.com/rcrowley/go-metrics"
 	"io"
+	"math/rand"
 	"net"
 	"sort"
 	"strconv"
@@ -30,6 +33,26 @@ type Broker struct {
 	responses     chan responsePromise
 	done          chan bool
 
+	// pendingResponses counts the promises the response receiver has not
+	// completed yet, it is only reading from the connection while non-zero
+	pendingResponses sync.WaitGroup
+	// sessionReauthenticationTime is when the SASL session needs to be
+	// re-authenticated as per KIP-368, zero if the broker set no lifetime on it
+	sessionReauthenticationTime time.Time
+	// hostName is the name of the host the seed broker was resolved from when
+	// dialed by IP, which TLS and Kerberos authenticate it as
+	hostName string
+	// closeTimer closes the connection before its client certificate expires
+	closeTimer *time.Timer
+	// reapTimer closes the connection once idle or too old, see Net.MaxIdleTime
+	// and Net.MaxConnectionAge, reaped telling the connection closed by either
+	// timer is to be reopened on next use
+	reapTimer    *time.Timer
+	reaped       int32
+	openTime     time.Time
+	inFlight     int32
+	lastActivity int64
+
 	registeredMetrics []string
 
 	incomingByteRate       metrics.Meter
@@ -46,6 +69,9 @@ type Broker struct {
 	brokerOutgoingByteRate metrics.Meter
 	brokerResponseRate     metrics.Meter
 	brokerResponseSize     metrics.Histogram
+	connectionCreationRate metrics.Meter
+	connectionCloseRate    metrics.Meter
+	connectionCount        metrics.Counter
 
 	kerberosAuthenticator GSSAPIKerberosAuth
 }
@@ -113,6 +139,25 @@ type SCRAMClient interface {
 	Done() bool
 }
 
+// SASLMechanismProvider is an interface to a SASL mechanism which exchange
+// is wrapped in the Kafka protocol, that is a SaslHandshake v1 request
+// followed by SaslAuthenticate requests (Kafka 1.0.0+). It allows plugging
+// in mechanisms sarama does not implement, such as AWS_MSK_IAM. A new instance
+// is generated by Net.SASL.MechanismProviderGeneratorFunc for every exchange,
+// so that the state of the conversation is never shared between connections.
+type SASLMechanismProvider interface {
+	// Name returns the name of the mechanism, as sent in the handshake.
+	Name() string
+	// Start begins the exchange with the broker and returns the initial
+	// client response.
+	Start() ([]byte, error)
+	// Step processes the challenge of the broker and returns the next client
+	// response. It is called repeatedly until it errors or `Done` returns true.
+	Step(challenge []byte) ([]byte, error)
+	// Done should return true when the exchange is over.
+	Done() bool
+}
+
 type responsePromise struct {
 	requestTime   time.Time
 	correlationID int32
@@ -150,19 +195,8 @@ func (b *Broker) Open(conf *Config) error {
 	go withRecover(func() {
 		defer b.lock.Unlock()
 
-		dialer := net.Dialer{
-			Timeout:   conf.Net.DialTimeout,
-			KeepAlive: conf.Net.KeepAlive,
-			LocalAddr: conf.Net.LocalAddr,
-		}
-
-		if conf.Net.TLS.Enable {
-			b.conn, b.connErr = tls.DialWithDialer(&dialer, "tcp", b.addr, conf.Net.TLS.Config)
-		} else if conf.Net.Proxy.Enable {
-			b.conn, b.connErr = conf.Net.Proxy.Dialer.Dial("tcp", b.addr)
-		} else {
-			b.conn, b.connErr = dialer.Dial("tcp", b.addr)
-		}
+		var certificateExpiry time.Time
+		b.conn, certificateExpiry, b.connErr = b.dial(conf)
 		if b.connErr != nil {
 			Logger.Printf("Failed to connect to broker %s: %s\n", b.addr, b.connErr)
 			b.conn = nil
@@ -181,6 +215,9 @@ func (b *Broker) Open(conf *Config) error {
 		b.outgoingByteRate = metrics.GetOrRegisterMeter("outgoing-byte-rate", conf.MetricRegistry)
 		b.responseRate = metrics.GetOrRegisterMeter("response-rate", conf.MetricRegistry)
 		b.responseSize = getOrRegisterHistogram("response-size", conf.MetricRegistry)
+		b.connectionCreationRate = metrics.GetOrRegisterMeter("connection-creation-rate", conf.MetricRegistry)
+		b.connectionCloseRate = metrics.GetOrRegisterMeter("connection-close-rate", conf.MetricRegistry)
+		b.connectionCount = metrics.GetOrRegisterCounter("connection-count", conf.MetricRegistry)
 		// Do not gather metrics for seeded broker (only used during bootstrap) because they share
 		// the same id (-1) and are already exposed through the global metrics above
 		if b.id >= 0 {
@@ -213,11 +250,154 @@ func (b *Broker) Open(conf *Config) error {
 			Logger.Printf("Connected to broker at %s (unregistered)\n", b.addr)
 		}
 		go withRecover(b.responseReceiver)
+
+		b.connectionCreationRate.Mark(1)
+		b.connectionCount.Inc(1)
+
+		if conf.Net.TLS.CloseBeforeExpiry > 0 && !certificateExpiry.IsZero() {
+			b.closeAt(certificateExpiry.Add(-conf.Net.TLS.CloseBeforeExpiry))
+		}
+
+		b.openTime = time.Now()
+		atomic.StoreInt64(&b.lastActivity, b.openTime.UnixNano())
+		if delay := b.reapDelay(b.openTime); delay > 0 {
+			b.reapAfter(delay)
+		}
 	})
 
 	return nil
 }
 
+// dial dials the broker, with the TLS configuration provided if TLS is
+// enabled, and returns when the client certificate of the connection expires
+// if known
+func (b *Broker) dial(conf *Config) (net.Conn, time.Time, error) {
+	var tlsConfig *tls.Config
+	var expiry time.Time
+	if conf.Net.TLS.Enable {
+		tlsConfig = conf.Net.TLS.Config
+		if provider := conf.Net.TLS.ConfigProvider; provider != nil {
+			var err error
+			if tlsConfig, err = provider.TLSConfig(); err != nil {
+				return nil, time.Time{}, err
+			}
+		}
+
+		var err error
+		if expiry, err = clientCertificateExpiry(tlsConfig); err != nil {
+			return nil, time.Time{}, err
+		}
+
+		if b.hostName != "" && (tlsConfig == nil || tlsConfig.ServerName == "") {
+			if tlsConfig == nil {
+				tlsConfig = &tls.Config{}
+			} else {
+				tlsConfig = tlsConfig.Clone()
+			}
+			tlsConfig.ServerName = b.hostName
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), conf.Net.DialTimeout)
+	defer cancel()
+
+	conn, err := newBrokerDialer(conf, tlsConfig).DialContext(ctx, "tcp", b.addr)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return conn, expiry, nil
+}
+
+// closeAt closes the connection at the given time, unless it got closed
+// before, for it to be reopened with the rotated credentials on its next use.
+// It must be called with the lock held.
+func (b *Broker) closeAt(t time.Time) {
+	if time.Now().After(t) {
+		// the credentials were not rotated in time, closing the connection
+		// now would only have it reopened with the same ones over and over
+		Logger.Printf("The client certificate of the connection to broker %s is about to expire\n", b.addr)
+		return
+	}
+
+	conn := b.conn
+	b.closeTimer = time.AfterFunc(time.Until(t), func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		if b.conn != conn {
+			return
+		}
+		Logger.Printf("Closing connection to broker %s as its client certificate is about to expire, reopening it on next use\n", b.addr)
+		_ = b.close()
+		atomic.StoreInt32(&b.reaped, 1)
+	})
+}
+
+// reapDelay returns how long from now the connection is to be closed as per
+// Net.MaxIdleTime and Net.MaxConnectionAge, negative if it is to be closed now,
+// once the responses in flight are received if it is too old, and 0 if it is
+// not to be closed. It must be called with the lock held.
+func (b *Broker) reapDelay(now time.Time) time.Duration {
+	var delay time.Duration
+
+	if maxAge := b.conf.Net.MaxConnectionAge; maxAge > 0 {
+		delay = b.openTime.Add(maxAge).Sub(now)
+		if delay <= 0 {
+			return -1
+		}
+	}
+
+	if maxIdle := b.conf.Net.MaxIdleTime; maxIdle > 0 {
+		idleDelay := time.Unix(0, atomic.LoadInt64(&b.lastActivity)).Add(maxIdle).Sub(now)
+		if idleDelay <= 0 {
+			if atomic.LoadInt32(&b.inFlight) == 0 {
+				return -1
+			}
+			// waiting for a response is not being idle
+			idleDelay = maxIdle
+		}
+		if delay == 0 || idleDelay < delay {
+			delay = idleDelay
+		}
+	}
+
+	return delay
+}
+
+// reapAfter closes the connection after the given delay if it is then idle or
+// too old, or checks it again later otherwise. The connection is reopened on
+// its next use. It must be called with the lock held.
+func (b *Broker) reapAfter(delay time.Duration) {
+	conn := b.conn
+	b.reapTimer = time.AfterFunc(delay, func() {
+		b.lock.Lock()
+		defer b.lock.Unlock()
+
+		if b.conn != conn {
+			return
+		}
+		if delay := b.reapDelay(time.Now()); delay > 0 {
+			b.reapAfter(delay)
+			return
+		}
+
+		// closing waits for the responses in flight, the lock held keeping
+		// other requests from being sent meanwhile
+		Logger.Printf("Closing idle or expired connection to broker %s, reopening it on next use\n", b.addr)
+		_ = b.close()
+		atomic.StoreInt32(&b.reaped, 1)
+	})
+}
+
+// reopen reopens the connection if it was closed for being idle or too old, or
+// for its client certificate expiring, so that it looks open to the users of
+// the broker
+func (b *Broker) reopen() {
+	if atomic.CompareAndSwapInt32(&b.reaped, 1, 0) {
+		_ = b.Open(b.conf)
+	}
+}
+
 // Connected returns true if the broker is connected and false otherwise. If the broker is not
 // connected but it had tried to connect, the error from that connection attempt is also returned.
 func (b *Broker) Connected() (bool, error) {
@@ -232,10 +412,25 @@ func (b *Broker) Close() error {
 	b.lock.Lock()
 	defer b.lock.Unlock()
 
+	atomic.StoreInt32(&b.reaped, 0)
+	return b.close()
+}
+
+// close closes the connection, it must be called with the lock held
+func (b *Broker) close() error {
 	if b.conn == nil {
 		return ErrNotConnected
 	}
 
+	if b.closeTimer != nil {
+		b.closeTimer.Stop()
+		b.closeTimer = nil
+	}
+	if b.reapTimer != nil {
+		b.reapTimer.Stop()
+		b.reapTimer = nil
+	}
+
 	close(b.responses)
 	<-b.done
 
@@ -245,8 +440,13 @@ func (b *Broker) Close() error {
 	b.connErr = nil
 	b.done = nil
 	b.responses = nil
+	b.sessionReauthenticationTime = time.Time{}
 
 	b.unregisterMetrics()
+	if b.connectionCloseRate != nil {
+		b.connectionCloseRate.Mark(1)
+		b.connectionCount.Dec(1)
+	}
 
 	if err == nil {
 		Logger.Printf("Closed connection to broker %s\n", b.addr)
@@ -332,6 +532,18 @@ func (b *Broker) GetAvailableOffsets(request *OffsetRequest) (*OffsetResponse, e
 	return response, nil
 }
 
+//OffsetForLeaderEpoch returns the end offsets of leader epochs or error
+func (b *Broker) OffsetForLeaderEpoch(request *OffsetForLeaderEpochRequest) (*OffsetForLeaderEpochResponse, error) {
+	response := new(OffsetForLeaderEpochResponse)
+
+	err := b.sendAndReceive(request, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
 //Produce returns a produce response or error
 func (b *Broker) Produce(request *ProduceRequest) (*ProduceResponse, error) {
 	var (
@@ -658,8 +870,159 @@ func (b *Broker) DeleteGroups(request *DeleteGroupsRequest) (*DeleteGroupsRespon
 	return response, nil
 }
 
+//ElectLeaders sends a request to elect the leaders of partitions and returns a response or error
+func (b *Broker) ElectLeaders(request *ElectLeadersRequest) (*ElectLeadersResponse, error) {
+	response := new(ElectLeadersResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//DescribeLogDirs sends a request to get the broker's log dir paths and sizes
+func (b *Broker) DescribeLogDirs(request *DescribeLogDirsRequest) (*DescribeLogDirsResponse, error) {
+	response := new(DescribeLogDirsResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//AlterReplicaLogDirs sends a request to move replicas to other log dirs of the broker and returns a response or error
+func (b *Broker) AlterReplicaLogDirs(request *AlterReplicaLogDirsRequest) (*AlterReplicaLogDirsResponse, error) {
+	response := new(AlterReplicaLogDirsResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//IncrementalAlterConfigs sends a request to incrementally alter configs and returns a response or error
+func (b *Broker) IncrementalAlterConfigs(request *IncrementalAlterConfigsRequest) (*IncrementalAlterConfigsResponse, error) {
+	response := new(IncrementalAlterConfigsResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//DeleteOffsets sends a request to delete group offsets and returns a response or error
+func (b *Broker) DeleteOffsets(request *DeleteOffsetsRequest) (*DeleteOffsetsResponse, error) {
+	response := new(DeleteOffsetsResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//CreateDelegationToken sends a request to create a delegation token and returns a response or error
+func (b *Broker) CreateDelegationToken(request *CreateDelegationTokenRequest) (*CreateDelegationTokenResponse, error) {
+	response := new(CreateDelegationTokenResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//RenewDelegationToken sends a request to renew a delegation token and returns a response or error
+func (b *Broker) RenewDelegationToken(request *RenewDelegationTokenRequest) (*RenewDelegationTokenResponse, error) {
+	response := new(RenewDelegationTokenResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//ExpireDelegationToken sends a request to expire a delegation token and returns a response or error
+func (b *Broker) ExpireDelegationToken(request *ExpireDelegationTokenRequest) (*ExpireDelegationTokenResponse, error) {
+	response := new(ExpireDelegationTokenResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//DescribeDelegationToken sends a request to describe delegation tokens and returns a response or error
+func (b *Broker) DescribeDelegationToken(request *DescribeDelegationTokenRequest) (*DescribeDelegationTokenResponse, error) {
+	response := new(DescribeDelegationTokenResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//DescribeClientQuotas sends a request to get the quotas of entities and returns a response or error
+func (b *Broker) DescribeClientQuotas(request *DescribeClientQuotasRequest) (*DescribeClientQuotasResponse, error) {
+	response := new(DescribeClientQuotasResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//AlterClientQuotas sends a request to set or remove the quotas of entities and returns a response or error
+func (b *Broker) AlterClientQuotas(request *AlterClientQuotasRequest) (*AlterClientQuotasResponse, error) {
+	response := new(AlterClientQuotasResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//DescribeUserScramCredentials sends a request to get the SCRAM credentials of users and returns a response or error
+func (b *Broker) DescribeUserScramCredentials(request *DescribeUserScramCredentialsRequest) (*DescribeUserScramCredentialsResponse, error) {
+	response := new(DescribeUserScramCredentialsResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+//AlterUserScramCredentials sends a request to delete and upsert SCRAM credentials and returns a response or error
+func (b *Broker) AlterUserScramCredentials(request *AlterUserScramCredentialsRequest) (*AlterUserScramCredentialsResponse, error) {
+	response := new(AlterUserScramCredentialsResponse)
+
+	if err := b.sendAndReceive(request, response); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
 func (b *Broker) send(rb protocolBody, promiseResponse bool) (*responsePromise, error) {
+	b.reopen()
+
 	b.lock.Lock()
+	if b.conn == nil && atomic.LoadInt32(&b.reaped) == 1 {
+		// the connection got closed while waiting for the lock, to be
+		// reopened on this use as well
+		b.lock.Unlock()
+		return b.send(rb, promiseResponse)
+	}
 	defer b.lock.Unlock()
 
 	if b.conn == nil {
@@ -673,6 +1036,12 @@ func (b *Broker) send(rb protocolBody, promiseResponse bool) (*responsePromise,
 		return nil, ErrUnsupportedVersion
 	}
 
+	if !b.sessionReauthenticationTime.IsZero() && time.Now().After(b.sessionReauthenticationTime) {
+		if err := b.reauthenticate(); err != nil {
+			return nil, err
+		}
+	}
+
 	req := &request{correlationID: b.correlationID, clientID: b.conf.ClientID, body: rb}
 	buf, err := encode(req, b.conf.MetricRegistry)
 	if err != nil {
@@ -685,6 +1054,7 @@ func (b *Broker) send(rb protocolBody, promiseResponse bool) (*responsePromise,
 	}
 
 	requestTime := time.Now()
+	atomic.StoreInt64(&b.lastActivity, requestTime.UnixNano())
 	bytes, err := b.conn.Write(buf)
 	b.updateOutgoingCommunicationMetrics(bytes) //TODO: should it be after error check
 	if err != nil {
@@ -699,6 +1069,8 @@ func (b *Broker) send(rb protocolBody, promiseResponse bool) (*responsePromise,
 	}
 
 	promise := responsePromise{requestTime, req.correlationID, make(chan []byte), make(chan error)}
+	b.pendingResponses.Add(1)
+	atomic.AddInt32(&b.inFlight, 1)
 	b.responses <- promise
 
 	return &promise, nil
@@ -716,6 +1088,11 @@ func (b *Broker) sendAndReceive(req protocolBody, res versionedDecoder) error {
 
 	select {
 	case buf := <-promise.packets:
+		if headerVersion(res, 0) >= 1 {
+			if buf, err = skipResponseHeaderTaggedFields(buf); err != nil {
+				return err
+			}
+		}
 		return versionedDecode(buf, res, req.version())
 	case err = <-promise.errors:
 		return err
@@ -790,6 +1167,7 @@ func (b *Broker) responseReceiver() {
 	for response := range b.responses {
 		if dead != nil {
 			response.errors <- dead
+			b.responseDone()
 			continue
 		}
 
@@ -797,6 +1175,7 @@ func (b *Broker) responseReceiver() {
 		if err != nil {
 			dead = err
 			response.errors <- err
+			b.responseDone()
 			continue
 		}
 
@@ -806,6 +1185,7 @@ func (b *Broker) responseReceiver() {
 			b.updateIncomingCommunicationMetrics(bytesReadHeader, requestLatency)
 			dead = err
 			response.errors <- err
+			b.responseDone()
 			continue
 		}
 
@@ -815,6 +1195,7 @@ func (b *Broker) responseReceiver() {
 			b.updateIncomingCommunicationMetrics(bytesReadHeader, requestLatency)
 			dead = err
 			response.errors <- err
+			b.responseDone()
 			continue
 		}
 		if decodedHeader.correlationID != response.correlationID {
@@ -823,6 +1204,7 @@ func (b *Broker) responseReceiver() {
 			// TODO if decoded ID > cur ID, save it so when cur ID catches up we have a response
 			dead = PacketDecodingError{fmt.Sprintf("correlation ID didn't match, wanted %d, got %d", response.correlationID, decodedHeader.correlationID)}
 			response.errors <- dead
+			b.responseDone()
 			continue
 		}
 
@@ -832,20 +1214,26 @@ func (b *Broker) responseReceiver() {
 		if err != nil {
 			dead = err
 			response.errors <- err
+			b.responseDone()
 			continue
 		}
 
 		response.packets <- buf
+		b.responseDone()
 	}
 	close(b.done)
 }
 
 func (b *Broker) authenticateViaSASL() error {
+	if newMechanism := b.conf.Net.SASL.MechanismProviderGeneratorFunc; newMechanism != nil {
+		return b.sendAndReceiveSASLMechanism(newMechanism())
+	}
+
 	switch b.conf.Net.SASL.Mechanism {
 	case SASLTypeOAuth:
-		return b.sendAndReceiveSASLOAuth(b.conf.Net.SASL.TokenProvider)
+		return b.sendAndReceiveSASLMechanism(&oauthBearerMechanism{provider: b.conf.Net.SASL.TokenProvider})
 	case SASLTypeSCRAMSHA256, SASLTypeSCRAMSHA512:
-		return b.sendAndReceiveSASLSCRAMv1()
+		return b.sendAndReceiveSASLMechanism(b.newSCRAMMechanism())
 	case SASLTypeGSSAPI:
 		return b.sendAndReceiveKerberos()
 	default:
@@ -853,6 +1241,129 @@ func (b *Broker) authenticateViaSASL() error {
 	}
 }
 
+// responseDone marks the promise of a response as completed
+func (b *Broker) responseDone() {
+	atomic.StoreInt64(&b.lastActivity, time.Now().UnixNano())
+	atomic.AddInt32(&b.inFlight, -1)
+	b.pendingResponses.Done()
+}
+
+// reauthenticate re-authenticates the SASL session in-band on the open
+// connection, waiting first for the in-flight requests to get their response
+// so that the response receiver does not read from the connection meanwhile.
+// It must be called with the broker lock held, which keeps other requests
+// from being sent until the session is re-authenticated.
+func (b *Broker) reauthenticate() error {
+	b.pendingResponses.Wait()
+
+	if err := b.conn.SetReadDeadline(time.Now().Add(b.conf.Net.ReadTimeout)); err != nil {
+		return err
+	}
+
+	Logger.Printf("Re-authenticating SASL session with broker %s\n", b.addr)
+	if err := b.authenticateViaSASL(); err != nil {
+		Logger.Printf("Failed to re-authenticate SASL session with broker %s: %s\n", b.addr, err)
+		return err
+	}
+	return nil
+}
+
+// saslAuthenticateVersion returns the SaslAuthenticate version to use, v1
+// making the broker return the lifetime of the session
+func (b *Broker) saslAuthenticateVersion() int16 {
+	if b.conf.Version.IsAtLeast(V2_2_0_0) {
+		return 1
+	}
+	return 0
+}
+
+// updateSessionLifetime schedules the re-authentication of the session from
+// the lifetime the broker returned, at 85 to 95% of it to leave room for the
+// re-authentication itself as the Java client does
+func (b *Broker) updateSessionLifetime(res *SaslAuthenticateResponse) {
+	if res.SessionLifetimeMs <= 0 {
+		b.sessionReauthenticationTime = time.Time{}
+		return
+	}
+
+	lifetime := time.Duration(res.SessionLifetimeMs) * time.Millisecond
+	factor := 0.85 + 0.10*rand.Float64()
+	b.sessionReauthenticationTime = time.Now().Add(time.Duration(float64(lifetime) * factor))
+	Logger.Printf("SASL session with broker %s expires in %s, re-authenticating in %s\n",
+		b.addr, lifetime, time.Until(b.sessionReauthenticationTime).Truncate(time.Millisecond))
+}
+
+// sendAndReceiveSASLMechanism performs the exchange of a SASL mechanism
+// wrapped in SaslAuthenticate requests after a v1 handshake
+func (b *Broker) sendAndReceiveSASLMechanism(mechanism SASLMechanismProvider) error {
+	if err := b.sendAndReceiveSASLHandshake(SASLMechanism(mechanism.Name()), SASLHandshakeV1); err != nil {
+		return err
+	}
+
+	msg, err := mechanism.Start()
+	if err != nil {
+		return err
+	}
+
+	for {
+		requestTime := time.Now()
+		correlationID := b.correlationID
+		bytesWritten, err := b.sendSaslAuthenticateRequest(correlationID, msg)
+		b.updateOutgoingCommunicationMetrics(bytesWritten)
+		if err != nil {
+			Logger.Printf("Failed to write SASL auth header to broker %s: %s\n", b.addr, err.Error())
+			return err
+		}
+		b.correlationID++
+
+		challenge, err := b.receiveSaslAuthenticateResponse(correlationID)
+		if err != nil {
+			Logger.Printf("Failed to read response while authenticating with SASL to broker %s: %s\n", b.addr, err.Error())
+			return err
+		}
+		b.updateIncomingCommunicationMetrics(len(challenge), time.Since(requestTime))
+
+		msg, err = mechanism.Step(challenge)
+		if err != nil {
+			return err
+		}
+		if mechanism.Done() {
+			break
+		}
+	}
+
+	Logger.Printf("SASL/%s authentication succeeded with broker %s\n", mechanism.Name(), b.addr)
+	return nil
+}
+
+// newSCRAMMechanism returns the SCRAM mechanism authenticating either with
+// the user credentials or with the delegation token
+func (b *Broker) newSCRAMMechanism() *scramMechanism {
+	sasl := b.conf.Net.SASL
+	if token := sasl.DelegationToken; token != nil {
+		// the user is the ID of the token and the password its HMAC, as the
+		// brokers tell them from regular credentials with this extension
+		return &scramMechanism{
+			name: sasl.Mechanism,
+			newClient: func() (SCRAMClient, error) {
+				return newSCRAMClient(sasl.Mechanism, map[string]string{"tokenauth": "true"})
+			},
+			user:     token.TokenID,
+			password: base64.StdEncoding.EncodeToString(token.HMAC),
+			authzID:  sasl.SCRAMAuthzID,
+		}
+	}
+	return &scramMechanism{
+		name: sasl.Mechanism,
+		newClient: func() (SCRAMClient, error) {
+			return sasl.SCRAMClientGeneratorFunc(), nil
+		},
+		user:     sasl.User,
+		password: sasl.Password,
+		authzID:  sasl.SCRAMAuthzID,
+	}
+}
+
 func (b *Broker) sendAndReceiveKerberos() error {
 	b.kerberosAuthenticator.Config = &b.conf.Net.SASL.GSSAPI
 	if b.kerberosAuthenticator.NewKerberosClientFunc == nil {
@@ -942,23 +1453,19 @@ func (b *Broker) sendAndReceiveSASLHandshake(saslType SASLMechanism, version int
 // When credentials are invalid, Kafka replies with a SaslAuthenticate response
 // containing an error code and message detailing the authentication failure.
 func (b *Broker) sendAndReceiveSASLPlainAuth() error {
+	if b.conf.Net.SASL.Handshake && b.conf.Version.IsAtLeast(V1_0_0_0) {
+		return b.sendAndReceiveSASLMechanism(&plainMechanism{user: b.conf.Net.SASL.User, password: b.conf.Net.SASL.Password})
+	}
+
 	// default to V0 to allow for backward compatability when SASL is enabled
 	// but not the handshake
-	saslHandshake := SASLHandshakeV0
 	if b.conf.Net.SASL.Handshake {
-		if b.conf.Version.IsAtLeast(V1_0_0_0) {
-			saslHandshake = SASLHandshakeV1
-		}
-		handshakeErr := b.sendAndReceiveSASLHandshake(SASLTypePlaintext, saslHandshake)
+		handshakeErr := b.sendAndReceiveSASLHandshake(SASLTypePlaintext, SASLHandshakeV0)
 		if handshakeErr != nil {
 			Logger.Printf("Error while performing SASL handshake %s\n", b.addr)
 			return handshakeErr
 		}
 	}
-
-	if saslHandshake == SASLHandshakeV1 {
-		return b.sendAndReceiveV1SASLPlainAuth()
-	}
 	return b.sendAndReceiveV0SASLPlainAuth()
 }
 
@@ -998,116 +1505,8 @@ func (b *Broker) sendAndReceiveV0SASLPlainAuth() error {
 	return nil
 }
 
-// sendAndReceiveV1SASLPlainAuth flows the v1 sasl authentication using the kafka protocol
-func (b *Broker) sendAndReceiveV1SASLPlainAuth() error {
-	correlationID := b.correlationID
-
-	requestTime := time.Now()
-
-	bytesWritten, err := b.sendSASLPlainAuthClientResponse(correlationID)
-
-	b.updateOutgoingCommunicationMetrics(bytesWritten)
-
-	if err != nil {
-		Logger.Printf("Failed to write SASL auth header to broker %s: %s\n", b.addr, err.Error())
-		return err
-	}
-
-	b.correlationID++
-
-	bytesRead, err := b.receiveSASLServerResponse(correlationID)
-	b.updateIncomingCommunicationMetrics(bytesRead, time.Since(requestTime))
-
-	// With v1 sasl we get an error message set in the response we can return
-	if err != nil {
-		Logger.Printf("Error returned from broker during SASL flow %s: %s\n", b.addr, err.Error())
-		return err
-	}
-
-	return nil
-}
-
-// sendAndReceiveSASLOAuth performs the authentication flow as described by KIP-255
-// https://cwiki.apache.org/confluence/pages/viewpage.action?pageId=75968876
-func (b *Broker) sendAndReceiveSASLOAuth(provider AccessTokenProvider) error {
-	if err := b.sendAndReceiveSASLHandshake(SASLTypeOAuth, SASLHandshakeV1); err != nil {
-		return err
-	}
-
-	token, err := provider.Token()
-	if err != nil {
-		return err
-	}
-
-	requestTime := time.Now()
-	correlationID := b.correlationID
-
-	bytesWritten, err := b.sendSASLOAuthBearerClientResponse(token, correlationID)
-	if err != nil {
-		return err
-	}
-
-	b.updateOutgoingCommunicationMetrics(bytesWritten)
-	b.correlationID++
-
-	bytesRead, err := b.receiveSASLServerResponse(correlationID)
-	if err != nil {
-		return err
-	}
-
-	requestLatency := time.Since(requestTime)
-	b.updateIncomingCommunicationMetrics(bytesRead, requestLatency)
-
-	return nil
-}
-
-func (b *Broker) sendAndReceiveSASLSCRAMv1() error {
-	if err := b.sendAndReceiveSASLHandshake(b.conf.Net.SASL.Mechanism, SASLHandshakeV1); err != nil {
-		return err
-	}
-
-	scramClient := b.conf.Net.SASL.SCRAMClientGeneratorFunc()
-	if err := scramClient.Begin(b.conf.Net.SASL.User, b.conf.Net.SASL.Password, b.conf.Net.SASL.SCRAMAuthzID); err != nil {
-		return fmt.Errorf("failed to start SCRAM exchange with the server: %s", err.Error())
-	}
-
-	msg, err := scramClient.Step("")
-	if err != nil {
-		return fmt.Errorf("failed to advance the SCRAM exchange: %s", err.Error())
-
-	}
-
-	for !scramClient.Done() {
-		requestTime := time.Now()
-		correlationID := b.correlationID
-		bytesWritten, err := b.sendSaslAuthenticateRequest(correlationID, []byte(msg))
-		if err != nil {
-			Logger.Printf("Failed to write SASL auth header to broker %s: %s\n", b.addr, err.Error())
-			return err
-		}
-
-		b.updateOutgoingCommunicationMetrics(bytesWritten)
-		b.correlationID++
-		challenge, err := b.receiveSaslAuthenticateResponse(correlationID)
-		if err != nil {
-			Logger.Printf("Failed to read response while authenticating with SASL to broker %s: %s\n", b.addr, err.Error())
-			return err
-		}
-
-		b.updateIncomingCommunicationMetrics(len(challenge), time.Since(requestTime))
-		msg, err = scramClient.Step(string(challenge))
-		if err != nil {
-			Logger.Println("SASL authentication failed", err)
-			return err
-		}
-	}
-
-	Logger.Println("SASL authentication succeeded")
-	return nil
-}
-
 func (b *Broker) sendSaslAuthenticateRequest(correlationID int32, msg []byte) (int, error) {
-	rb := &SaslAuthenticateRequest{msg}
+	rb := &SaslAuthenticateRequest{Version: b.saslAuthenticateVersion(), SaslAuthBytes: msg}
 	req := &request{correlationID: correlationID, clientID: b.conf.ClientID, body: rb}
 	buf, err := encode(req, b.conf.MetricRegistry)
 	if err != nil {
@@ -1145,12 +1544,13 @@ func (b *Broker) receiveSaslAuthenticateResponse(correlationID int32) ([]byte, e
 	}
 
 	res := &SaslAuthenticateResponse{}
-	if err := versionedDecode(buf, res, 0); err != nil {
+	if err := versionedDecode(buf, res, b.saslAuthenticateVersion()); err != nil {
 		return nil, err
 	}
 	if res.Err != ErrNoError {
 		return nil, res.Err
 	}
+	b.updateSessionLifetime(res)
 	return res.SaslAuthBytes, nil
 }
 
@@ -1185,90 +1585,6 @@ func mapToString(extensions map[string]string, keyValSep string, elemSep string)
 	return strings.Join(buf, elemSep)
 }
 
-func (b *Broker) sendSASLPlainAuthClientResponse(correlationID int32) (int, error) {
-	authBytes := []byte("\x00" + b.conf.Net.SASL.User + "\x00" + b.conf.Net.SASL.Password)
-	rb := &SaslAuthenticateRequest{authBytes}
-	req := &request{correlationID: correlationID, clientID: b.conf.ClientID, body: rb}
-	buf, err := encode(req, b.conf.MetricRegistry)
-	if err != nil {
-		return 0, err
-	}
-
-	err = b.conn.SetWriteDeadline(time.Now().Add(b.conf.Net.WriteTimeout))
-	if err != nil {
-		Logger.Printf("Failed to set write deadline when doing SASL auth with broker %s: %s\n", b.addr, err.Error())
-		return 0, err
-	}
-	return b.conn.Write(buf)
-}
-
-func (b *Broker) sendSASLOAuthBearerClientResponse(token *AccessToken, correlationID int32) (int, error) {
-	initialResp, err := buildClientInitialResponse(token)
-	if err != nil {
-		return 0, err
-	}
-
-	rb := &SaslAuthenticateRequest{initialResp}
-
-	req := &request{correlationID: correlationID, clientID: b.conf.ClientID, body: rb}
-
-	buf, err := encode(req, b.conf.MetricRegistry)
-	if err != nil {
-		return 0, err
-	}
-
-	if err := b.conn.SetWriteDeadline(time.Now().Add(b.conf.Net.WriteTimeout)); err != nil {
-		return 0, err
-	}
-
-	return b.conn.Write(buf)
-}
-
-func (b *Broker) receiveSASLServerResponse(correlationID int32) (int, error) {
-
-	buf := make([]byte, responseLengthSize+correlationIDSize)
-
-	bytesRead, err := io.ReadFull(b.conn, buf)
-	if err != nil {
-		return bytesRead, err
-	}
-
-	header := responseHeader{}
-
-	err = decode(buf, &header)
-	if err != nil {
-		return bytesRead, err
-	}
-
-	if header.correlationID != correlationID {
-		return bytesRead, fmt.Errorf("correlation ID didn't match, wanted %d, got %d", b.correlationID, header.correlationID)
-	}
-
-	buf = make([]byte, header.length-correlationIDSize)
-
-	c, err := io.ReadFull(b.conn, buf)
-	bytesRead += c
-	if err != nil {
-		return bytesRead, err
-	}
-
-	res := &SaslAuthenticateResponse{}
-
-	if err := versionedDecode(buf, res, 0); err != nil {
-		return bytesRead, err
-	}
-
-	if res.Err != ErrNoError {
-		return bytesRead, res.Err
-	}
-
-	if len(res.SaslAuthBytes) > 0 {
-		Logger.Printf("Received SASL auth response: %s", res.SaslAuthBytes)
-	}
-
-	return bytesRead, nil
-}
-
 func (b *Broker) updateIncomingCommunicationMetrics(bytes int, requestLatency time.Duration) {
 	b.updateRequestLatencyMetrics(requestLatency)
 	b.responseRate.Mark(1)
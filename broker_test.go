@@ -6,6 +6,7 @@ import (
 	"gopkg.in/jcmturner/gokrb5.v7/krberror"
 	"net"
 	"reflect"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -245,6 +246,292 @@ func TestSASLOAuthBearer(t *testing.T) {
 	}
 }
 
+func TestBrokerReapsConnections(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		configure func(conf *Config)
+	}{
+		{"idle", func(conf *Config) { conf.Net.MaxIdleTime = 100 * time.Millisecond }},
+		{"age", func(conf *Config) { conf.Net.MaxConnectionAge = 100 * time.Millisecond }},
+	} {
+		mockBroker := NewMockBroker(t, 0)
+		mockBroker.SetHandlerByMap(map[string]MockResponse{
+			"MetadataRequest": NewMockMetadataResponse(t),
+		})
+
+		conf := NewConfig()
+		tc.configure(conf)
+		broker := NewBroker(mockBroker.Addr())
+		if err := broker.Open(conf); err != nil {
+			t.Fatal(tc.name, err)
+		}
+		if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+			t.Fatal(tc.name, err)
+		}
+
+		deadline := time.Now().Add(5 * time.Second)
+		for {
+			if connected, _ := broker.Connected(); !connected {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Fatalf("%s: expected the connection to be closed", tc.name)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		// the connection is reopened on its next use
+		if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+			t.Error(tc.name, err)
+		}
+
+		safeClose(t, broker)
+		mockBroker.Close()
+
+		creations := metrics.GetOrRegisterMeter("connection-creation-rate", conf.MetricRegistry).Count()
+		closes := metrics.GetOrRegisterMeter("connection-close-rate", conf.MetricRegistry).Count()
+		open := metrics.GetOrRegisterCounter("connection-count", conf.MetricRegistry).Count()
+		if creations != 2 || closes != 2 || open != 0 {
+			t.Errorf("%s: expected 2 connections opened and closed, got %d opened, %d closed and %d open",
+				tc.name, creations, closes, open)
+		}
+	}
+}
+
+func TestBrokerReapsOldConnectionsOnceDrained(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+	// the connection gets too old while the first request is in flight
+	mockBroker.SetLatency(300 * time.Millisecond)
+
+	conf := NewConfig()
+	conf.Net.MaxConnectionAge = 100 * time.Millisecond
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+
+	inFlight := make(chan error)
+	go func() {
+		_, err := broker.GetMetadata(&MetadataRequest{})
+		inFlight <- err
+	}()
+
+	// the second request is sent while the connection is being drained
+	time.Sleep(200 * time.Millisecond)
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Error("expected the request sent while draining to be sent on a new connection, got", err)
+	}
+	if err := <-inFlight; err != nil {
+		t.Error("expected the request in flight to get its response, got", err)
+	}
+
+	if creations := metrics.GetOrRegisterMeter("connection-creation-rate", conf.MetricRegistry).Count(); creations != 2 {
+		t.Error("expected the connection to be reopened once, got", creations, "connections")
+	}
+	// the new connection may be too old already
+	_ = broker.Close()
+}
+
+// sequenceTokenProvider returns a new token every time one is asked for
+type sequenceTokenProvider struct {
+	tokens int
+}
+
+func (p *sequenceTokenProvider) Token() (*AccessToken, error) {
+	p.tokens++
+	return &AccessToken{Token: fmt.Sprintf("access-token-%d", p.tokens)}, nil
+}
+
+func TestSASLReauthentication(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"SaslAuthenticateRequest": NewMockSaslAuthenticateResponse(t).SetSessionLifetimeMs(100),
+		"SaslHandshakeRequest":    NewMockSaslHandshakeResponse(t).SetEnabledMechanisms([]string{SASLTypeOAuth}),
+		"MetadataRequest":         NewMockMetadataResponse(t),
+	})
+
+	provider := &sequenceTokenProvider{}
+	conf := NewConfig()
+	conf.Version = V2_2_0_0
+	conf.Net.SASL.Enable = true
+	conf.Net.SASL.Mechanism = SASLTypeOAuth
+	conf.Net.SASL.TokenProvider = provider
+
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = broker.Close() }()
+	if connected, err := broker.Connected(); !connected || err != nil {
+		t.Fatal("expected the broker to be connected, got", err)
+	}
+
+	// the session is not due for re-authentication yet
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	var tokens []string
+	var requests []string
+	for _, rr := range mockBroker.History() {
+		switch req := rr.Request.(type) {
+		case *SaslHandshakeRequest:
+			requests = append(requests, "handshake")
+		case *SaslAuthenticateRequest:
+			if req.Version != 1 {
+				t.Error("expected SaslAuthenticate v1, got", req.Version)
+			}
+			requests = append(requests, "authenticate")
+			tokens = append(tokens, string(req.SaslAuthBytes))
+		case *MetadataRequest:
+			requests = append(requests, "metadata")
+		}
+	}
+
+	expected := []string{"handshake", "authenticate", "metadata", "handshake", "authenticate", "metadata"}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests %v, got %v", expected, requests)
+	}
+	if len(tokens) != 2 || tokens[0] == tokens[1] {
+		t.Errorf("expected a fresh token on re-authentication, got %q", tokens)
+	}
+}
+
+// challengeMechanism is a SASL mechanism answering a single challenge
+type challengeMechanism struct {
+	steps int
+}
+
+func (m *challengeMechanism) Name() string {
+	return "X-CHALLENGE"
+}
+
+func (m *challengeMechanism) Start() ([]byte, error) {
+	m.steps = 0
+	return []byte("hello"), nil
+}
+
+func (m *challengeMechanism) Step(challenge []byte) ([]byte, error) {
+	m.steps++
+	if m.steps == 1 {
+		return append([]byte("answer:"), challenge...), nil
+	}
+	if len(challenge) > 0 {
+		return nil, fmt.Errorf("unexpected challenge %q", challenge)
+	}
+	return nil, nil
+}
+
+func (m *challengeMechanism) Done() bool {
+	return m.steps == 2
+}
+
+var _ SASLMechanismProvider = &challengeMechanism{}
+
+func TestSASLMechanismProvider(t *testing.T) {
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"SaslAuthenticateRequest": NewMockSequence(
+			NewMockSaslAuthenticateResponse(t).SetAuthBytes([]byte("42")),
+			NewMockSaslAuthenticateResponse(t),
+		),
+		"SaslHandshakeRequest": NewMockSaslHandshakeResponse(t).SetEnabledMechanisms([]string{"X-CHALLENGE"}),
+	})
+
+	broker := NewBroker(mockBroker.Addr())
+	broker.requestRate = metrics.NilMeter{}
+	broker.outgoingByteRate = metrics.NilMeter{}
+	broker.incomingByteRate = metrics.NilMeter{}
+	broker.requestSize = metrics.NilHistogram{}
+	broker.responseSize = metrics.NilHistogram{}
+	broker.responseRate = metrics.NilMeter{}
+	broker.requestLatency = metrics.NilHistogram{}
+
+	conf := NewConfig()
+	conf.Version = V1_0_0_0
+	conf.Net.SASL.MechanismProviderGeneratorFunc = func() SASLMechanismProvider { return &challengeMechanism{} }
+	broker.conf = conf
+
+	conn, err := net.Dial("tcp", mockBroker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.conn = conn
+	defer conn.Close()
+
+	if err := broker.authenticateViaSASL(); err != nil {
+		t.Fatal(err)
+	}
+
+	var messages []string
+	for _, rr := range mockBroker.History() {
+		switch req := rr.Request.(type) {
+		case *SaslHandshakeRequest:
+			if req.Mechanism != "X-CHALLENGE" || req.Version != SASLHandshakeV1 {
+				t.Errorf("unexpected handshake %+v", req)
+			}
+		case *SaslAuthenticateRequest:
+			messages = append(messages, string(req.SaslAuthBytes))
+		}
+	}
+	if expected := []string{"hello", "answer:42"}; !reflect.DeepEqual(messages, expected) {
+		t.Errorf("expected messages %q, got %q", expected, messages)
+	}
+}
+
+func TestSASLMechanismProviderPerConnection(t *testing.T) {
+	var generated int32
+	conf := NewConfig()
+	conf.Version = V1_0_0_0
+	conf.Net.SASL.Enable = true
+	conf.Net.SASL.MechanismProviderGeneratorFunc = func() SASLMechanismProvider {
+		atomic.AddInt32(&generated, 1)
+		return &challengeMechanism{}
+	}
+
+	// the brokers authenticate concurrently, each with its own mechanism
+	var brokers []*Broker
+	for i := int32(0); i < 2; i++ {
+		mockBroker := NewMockBroker(t, i)
+		defer mockBroker.Close()
+		mockBroker.SetHandlerByMap(map[string]MockResponse{
+			"SaslAuthenticateRequest": NewMockSequence(
+				NewMockSaslAuthenticateResponse(t).SetAuthBytes([]byte("42")),
+				NewMockSaslAuthenticateResponse(t),
+			),
+			"SaslHandshakeRequest": NewMockSaslHandshakeResponse(t).SetEnabledMechanisms([]string{"X-CHALLENGE"}),
+		})
+
+		broker := NewBroker(mockBroker.Addr())
+		if err := broker.Open(conf); err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = broker.Close() }()
+		brokers = append(brokers, broker)
+	}
+
+	for _, broker := range brokers {
+		if connected, err := broker.Connected(); !connected || err != nil {
+			t.Error("expected the broker to be connected, got", err)
+		}
+	}
+	if generated := atomic.LoadInt32(&generated); generated != 2 {
+		t.Error("expected a mechanism per connection, got", generated)
+	}
+}
+
 // A mock scram client.
 type MockSCRAMClient struct {
 	done bool
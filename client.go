@@ -106,11 +106,15 @@ type client struct {
 	// so we store them separately
 	seedBrokers []*Broker
 	deadSeeds   []*Broker
+	// the bootstrap addresses, resolved again when all the seed brokers are dead
+	// unless Net.ClientDNSLookup is DefaultDNSLookup
+	seedAddrs []string
 
 	controllerID   int32                                   // cluster controller broker id
 	brokers        map[int32]*Broker                       // maps broker ids to brokers
 	metadata       map[string]map[int32]*PartitionMetadata // maps topics to partition ids to metadata
 	metadataTopics map[string]none                         // topics that need to collect metadata
+	internalTopics map[string]none                         // topics internal to Kafka, like __consumer_offsets
 	coordinators   map[string]int32                        // Maps consumer group names to coordinating broker IDs
 
 	// If the number of partitions is large, we can get some churn calling cachedPartitions,
@@ -145,14 +149,13 @@ func NewClient(addrs []string, conf *Config) (Client, error) {
 		brokers:                 make(map[int32]*Broker),
 		metadata:                make(map[string]map[int32]*PartitionMetadata),
 		metadataTopics:          make(map[string]none),
+		internalTopics:          make(map[string]none),
 		cachedPartitionsResults: make(map[string][maxPartitionIndex][]int32),
 		coordinators:            make(map[string]int32),
+		seedAddrs:               addrs,
 	}
 
-	random := rand.New(rand.NewSource(time.Now().UnixNano()))
-	for _, index := range random.Perm(len(addrs)) {
-		client.seedBrokers = append(client.seedBrokers, NewBroker(addrs[index]))
-	}
+	client.seedBrokers = newSeedBrokers(resolveBootstrapAddrs(addrs, conf.Net.ClientDNSLookup), nil)
 
 	if conf.Metadata.Full {
 		// do an initial fetch of all cluster metadata by specifying an empty list of topics
@@ -237,6 +240,7 @@ func (client *client) Close() error {
 	client.brokers = nil
 	client.metadata = nil
 	client.metadataTopics = nil
+	client.internalTopics = nil
 
 	return nil
 }
@@ -277,6 +281,16 @@ func (client *client) MetadataTopics() ([]string, error) {
 	return ret, nil
 }
 
+// isInternalTopic tells whether the topic is internal to Kafka, as per the
+// metadata of version 1 and later
+func (client *client) isInternalTopic(topic string) bool {
+	client.lock.RLock()
+	defer client.lock.RUnlock()
+
+	_, ok := client.internalTopics[topic]
+	return ok
+}
+
 func (client *client) Partitions(topic string) ([]int32, error) {
 	if client.Closed() {
 		return nil, ErrClosedClient
@@ -404,21 +418,29 @@ func (client *client) OfflineReplicas(topic string, partitionID int32) ([]int32,
 }
 
 func (client *client) Leader(topic string, partitionID int32) (*Broker, error) {
+	leader, _, err := client.leaderAndEpoch(topic, partitionID)
+	return leader, err
+}
+
+// leaderAndEpoch returns the leader of the topic/partition like Leader, and
+// the epoch of that leader, or -1 when it is unknown because the cluster is
+// older than 2.1.0
+func (client *client) leaderAndEpoch(topic string, partitionID int32) (*Broker, int32, error) {
 	if client.Closed() {
-		return nil, ErrClosedClient
+		return nil, -1, ErrClosedClient
 	}
 
-	leader, err := client.cachedLeader(topic, partitionID)
+	leader, epoch, err := client.cachedLeader(topic, partitionID)
 
 	if leader == nil {
 		err = client.RefreshMetadata(topic)
 		if err != nil {
-			return nil, err
+			return nil, -1, err
 		}
-		leader, err = client.cachedLeader(topic, partitionID)
+		leader, epoch, err = client.cachedLeader(topic, partitionID)
 	}
 
-	return leader, err
+	return leader, epoch, err
 }
 
 func (client *client) RefreshMetadata(topics ...string) error {
@@ -523,12 +545,31 @@ func (client *client) RefreshCoordinator(consumerGroup string) error {
 	return nil
 }
 
+// StaticAddressMapper returns an address mapper for Config.Net.AddressMapper
+// rewriting the advertised broker addresses found in addresses, keyed by
+// host:port, and keeping the others.
+func StaticAddressMapper(addresses map[string]string) func(brokerID int32, advertised string) string {
+	return func(brokerID int32, advertised string) string {
+		if addr, ok := addresses[advertised]; ok {
+			return addr
+		}
+		return advertised
+	}
+}
+
 // private broker management helpers
 
 // registerBroker makes sure a broker received by a Metadata or Coordinator request is registered
 // in the brokers map. It returns the broker that is registered, which may be the provided broker,
 // or a previously registered Broker instance. You must hold the write lock before calling this function.
 func (client *client) registerBroker(broker *Broker) {
+	if mapper := client.conf.Net.AddressMapper; mapper != nil {
+		if addr := mapper(broker.ID(), broker.Addr()); addr != broker.Addr() {
+			Logger.Printf("client/brokers mapped broker #%d advertised at %s to %s", broker.ID(), broker.Addr(), addr)
+			broker.addr = addr
+		}
+	}
+
 	if client.brokers[broker.ID()] == nil {
 		client.brokers[broker.ID()] = broker
 		Logger.Printf("client/brokers registered new broker #%d at %s", broker.ID(), broker.Addr())
@@ -559,14 +600,46 @@ func (client *client) deregisterBroker(broker *Broker) {
 }
 
 func (client *client) resurrectDeadBrokers() {
+	var resolved []bootstrapAddr
+	if client.conf.Net.ClientDNSLookup != DefaultDNSLookup {
+		// the brokers behind the bootstrap names may have changed since they
+		// were last resolved
+		resolved = resolveBootstrapAddrs(client.seedAddrs, client.conf.Net.ClientDNSLookup)
+	}
+
 	client.lock.Lock()
 	defer client.lock.Unlock()
 
-	Logger.Printf("client/brokers resurrecting %d dead seed brokers", len(client.deadSeeds))
-	client.seedBrokers = append(client.seedBrokers, client.deadSeeds...)
+	if resolved == nil {
+		Logger.Printf("client/brokers resurrecting %d dead seed brokers", len(client.deadSeeds))
+		client.seedBrokers = append(client.seedBrokers, client.deadSeeds...)
+	} else {
+		Logger.Printf("client/brokers replacing %d dead seed brokers with the resolved bootstrap addresses", len(client.deadSeeds))
+		client.seedBrokers = append(client.seedBrokers, newSeedBrokers(resolved, client.seedBrokers)...)
+	}
 	client.deadSeeds = nil
 }
 
+// newSeedBrokers returns the seed brokers of the given addresses in random
+// order, but for the addresses of the live ones
+func newSeedBrokers(addrs []bootstrapAddr, live []*Broker) []*Broker {
+	skip := make(map[string]bool, len(live))
+	for _, broker := range live {
+		skip[broker.Addr()] = true
+	}
+
+	var brokers []*Broker
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for _, index := range random.Perm(len(addrs)) {
+		if addr := addrs[index]; !skip[addr.addr] {
+			broker := NewBroker(addr.addr)
+			broker.hostName = addr.hostName
+			brokers = append(brokers, broker)
+		}
+	}
+	return brokers
+}
+
 func (client *client) any() *Broker {
 	client.lock.RLock()
 	defer client.lock.RUnlock()
@@ -641,7 +714,7 @@ func (client *client) setPartitionCache(topic string, partitionSet partitionType
 	return ret
 }
 
-func (client *client) cachedLeader(topic string, partitionID int32) (*Broker, error) {
+func (client *client) cachedLeader(topic string, partitionID int32) (*Broker, int32, error) {
 	client.lock.RLock()
 	defer client.lock.RUnlock()
 
@@ -650,18 +723,18 @@ func (client *client) cachedLeader(topic string, partitionID int32) (*Broker, er
 		metadata, ok := partitions[partitionID]
 		if ok {
 			if metadata.Err == ErrLeaderNotAvailable {
-				return nil, ErrLeaderNotAvailable
+				return nil, -1, ErrLeaderNotAvailable
 			}
 			b := client.brokers[metadata.Leader]
 			if b == nil {
-				return nil, ErrLeaderNotAvailable
+				return nil, -1, ErrLeaderNotAvailable
 			}
 			_ = b.Open(client.conf)
-			return b, nil
+			return b, metadata.LeaderEpoch, nil
 		}
 	}
 
-	return nil, ErrUnknownTopicOrPartition
+	return nil, -1, ErrUnknownTopicOrPartition
 }
 
 func (client *client) getOffset(topic string, partitionID int32, time int64) (int64, error) {
@@ -776,7 +849,9 @@ func (client *client) tryRefreshMetadata(topics []string, attemptsRemaining int,
 		}
 
 		req := &MetadataRequest{Topics: topics, AllowAutoTopicCreation: allowAutoTopicCreation}
-		if client.conf.Version.IsAtLeast(V1_0_0_0) {
+		if client.conf.Version.IsAtLeast(V2_1_0_0) {
+			req.Version = 7
+		} else if client.conf.Version.IsAtLeast(V1_0_0_0) {
 			req.Version = 5
 		} else if client.conf.Version.IsAtLeast(V0_10_0_0) {
 			req.Version = 1
@@ -844,6 +919,7 @@ func (client *client) updateMetadata(data *MetadataResponse, allKnownMetaData bo
 	if allKnownMetaData {
 		client.metadata = make(map[string]map[int32]*PartitionMetadata)
 		client.metadataTopics = make(map[string]none)
+		client.internalTopics = make(map[string]none)
 		client.cachedPartitionsResults = make(map[string][maxPartitionIndex][]int32)
 	}
 	for _, topic := range data.Topics {
@@ -855,6 +931,11 @@ func (client *client) updateMetadata(data *MetadataResponse, allKnownMetaData bo
 		}
 		delete(client.metadata, topic.Name)
 		delete(client.cachedPartitionsResults, topic.Name)
+		if topic.IsInternal {
+			client.internalTopics[topic.Name] = none{}
+		} else {
+			delete(client.internalTopics, topic.Name)
+		}
 
 		switch topic.Err {
 		case ErrNoError:
@@ -876,6 +957,9 @@ func (client *client) updateMetadata(data *MetadataResponse, allKnownMetaData bo
 
 		client.metadata[topic.Name] = make(map[int32]*PartitionMetadata, len(topic.Partitions))
 		for _, partition := range topic.Partitions {
+			if data.Version < 7 {
+				partition.LeaderEpoch = -1
+			}
 			client.metadata[topic.Name][partition.ID] = partition
 			if partition.Err == ErrLeaderNotAvailable {
 				retry = true
@@ -990,3 +1074,34 @@ type nopCloserClient struct {
 func (ncc *nopCloserClient) Close() error {
 	return nil
 }
+
+func (ncc *nopCloserClient) isInternalTopic(topic string) bool {
+	internal, ok := ncc.Client.(internalTopicsClient)
+	return ok && internal.isInternalTopic(topic)
+}
+
+func (ncc *nopCloserClient) leaderAndEpoch(topic string, partitionID int32) (*Broker, int32, error) {
+	return leaderAndEpoch(ncc.Client, topic, partitionID)
+}
+
+// leaderEpochClient is a client telling the epochs of the leaders of the
+// partitions, which the consumers detect the truncation of the logs with
+type leaderEpochClient interface {
+	leaderAndEpoch(topic string, partitionID int32) (*Broker, int32, error)
+}
+
+// leaderAndEpoch returns the leader of the topic/partition and its epoch, -1
+// when the client does not tell it
+func leaderAndEpoch(client Client, topic string, partitionID int32) (*Broker, int32, error) {
+	if epochs, ok := client.(leaderEpochClient); ok {
+		return epochs.leaderAndEpoch(topic, partitionID)
+	}
+	leader, err := client.Leader(topic, partitionID)
+	return leader, -1, err
+}
+
+// internalTopicsClient is a client telling the topics internal to Kafka apart,
+// which are not subscribed to by pattern
+type internalTopicsClient interface {
+	isInternalTopic(topic string) bool
+}
@@ -30,6 +30,47 @@ func TestSimpleClient(t *testing.T) {
 	safeClose(t, client)
 }
 
+func TestClientAddressMapper(t *testing.T) {
+	seedBroker := NewMockBroker(t, 1)
+	leader := NewMockBroker(t, 5)
+
+	metadataResponse := new(MetadataResponse)
+	metadataResponse.AddBroker("kafka-0.internal:9092", leader.BrokerID())
+	metadataResponse.AddBroker("kafka-1.internal:9092", 6)
+	metadataResponse.AddTopicPartition("my_topic", 0, leader.BrokerID(), nil, nil, nil, ErrNoError)
+	seedBroker.Returns(metadataResponse)
+
+	config := NewConfig()
+	config.Net.AddressMapper = StaticAddressMapper(map[string]string{
+		"kafka-0.internal:9092": leader.Addr(),
+	})
+	client, err := NewClient([]string{seedBroker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	broker, err := client.Leader("my_topic", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if broker.Addr() != leader.Addr() {
+		t.Errorf("expected the leader to be mapped to %s, got %s", leader.Addr(), broker.Addr())
+	}
+	if connected, err := broker.Connected(); !connected || err != nil {
+		t.Error("expected the leader to be connected at its mapped address, got", err)
+	}
+
+	for _, broker := range client.Brokers() {
+		if broker.ID() == 6 && broker.Addr() != "kafka-1.internal:9092" {
+			t.Error("expected the unmapped address to be kept, got", broker.Addr())
+		}
+	}
+
+	leader.Close()
+	seedBroker.Close()
+	safeClose(t, client)
+}
+
 func TestCachedPartitions(t *testing.T) {
 	seedBroker := NewMockBroker(t, 1)
 
@@ -40,6 +40,17 @@ type Config struct {
 		ReadTimeout  time.Duration // How long to wait for a response.
 		WriteTimeout time.Duration // How long to wait for a transmit.
 
+		// MaxIdleTime, if positive, closes the connections no request was sent
+		// on nor response received from for that long, before the brokers close
+		// them as per `connections.max.idle.ms` (10 minutes by default). The
+		// connections are reopened on their next use (defaults to 0, disabled).
+		MaxIdleTime time.Duration
+		// MaxConnectionAge, if positive, closes the connections that long after
+		// they were opened, so that they get spread again by the load balancers
+		// in front of the brokers. The connections are reopened on their next
+		// use (defaults to 0, disabled).
+		MaxConnectionAge time.Duration
+
 		TLS struct {
 			// Whether or not to use TLS when connecting to the broker
 			// (defaults to false).
@@ -47,10 +58,24 @@ type Config struct {
 			// The TLS configuration to use for secure connections if
 			// enabled (defaults to nil).
 			Config *tls.Config
+			// ConfigProvider, if set, provides the TLS configuration of every new
+			// connection in place of Config, which allows rotating the credentials
+			// (see FileTLSConfigProvider).
+			ConfigProvider TLSConfigProvider
+			// CloseBeforeExpiry, if positive, closes the connections that long
+			// before the client certificate they were opened with expires. They
+			// are reopened with the credentials then provided on their next use
+			// by the client (defaults to 0, disabled).
+			CloseBeforeExpiry time.Duration
 		}
 
 		// SASL based authentication with broker. While there are multiple SASL authentication methods
 		// the current implementation is limited to plaintext (SASL/PLAIN) authentication
+		//
+		// From Kafka 2.2.0 on, when the brokers bound the lifetime of the sessions
+		// (connections.max.reauth.ms), the sessions are re-authenticated in-band
+		// before they expire (KIP-368). This is not supported with GSSAPI, nor with
+		// SASL/PLAIN without the handshake.
 		SASL struct {
 			// Whether or not to use SASL authentication when connecting to the broker
 			// (defaults to false).
@@ -70,11 +95,22 @@ type Config struct {
 			// SCRAMClientGeneratorFunc is a generator of a user provided implementation of a SCRAM
 			// client used to perform the SCRAM exchange with the server.
 			SCRAMClientGeneratorFunc func() SCRAMClient
+			// DelegationToken, if set, is used to authenticate with SASL/SCRAM instead of
+			// User and Password. Only its TokenID and HMAC are required. The SCRAM exchange,
+			// which carries the tokenauth=true extension, is then performed by sarama and
+			// SCRAMClientGeneratorFunc is not needed.
+			DelegationToken *DelegationToken
 			// TokenProvider is a user-defined callback for generating
 			// access tokens for SASL/OAUTHBEARER auth. See the
 			// AccessTokenProvider interface docs for proper implementation
 			// guidelines.
 			TokenProvider AccessTokenProvider
+			// MechanismProviderGeneratorFunc, if set, is a generator of the SASL
+			// mechanism used to authenticate in place of the one named by
+			// Mechanism, called for every connection so that each one has its
+			// own instance. Its exchange is wrapped in the Kafka protocol, which
+			// requires Version to be at least V1_0_0_0.
+			MechanismProviderGeneratorFunc func() SASLMechanismProvider
 
 			GSSAPI GSSAPIConfig
 		}
@@ -96,6 +132,25 @@ type Config struct {
 			// The proxy dialer to use enabled (defaults to nil).
 			Dialer proxy.Dialer
 		}
+
+		// Dialer, if set, opens the connections to the brokers in place of a
+		// net.Dialer configured with DialTimeout, KeepAlive and LocalAddr, TLS
+		// being layered over it when enabled. See NewHTTPConnectDialer,
+		// NewSOCKS5Dialer and NewTLSDialer for proxies (defaults to nil).
+		Dialer ContextDialer
+
+		// AddressMapper, if set, rewrites the addresses the brokers advertise in
+		// the metadata before they are dialed, for instance to reach a cluster
+		// through port forwarding or SSH tunnels. It is given the ID of the
+		// broker and the host:port it advertises, and returns the host:port to
+		// dial instead, or the advertised one to keep it. The addresses of the
+		// seed brokers are not rewritten. See StaticAddressMapper (defaults to nil).
+		AddressMapper func(brokerID int32, advertised string) string
+
+		// ClientDNSLookup is how the bootstrap addresses are resolved, names
+		// possibly being expanded to a seed broker per IP address and resolved
+		// again when all the seed brokers are dead (defaults to DefaultDNSLookup).
+		ClientDNSLookup ClientDNSLookup
 	}
 
 	// Metadata is the namespace for metadata management properties used by the
@@ -338,10 +393,34 @@ type Config struct {
 			// How frequently to commit updated offsets. Defaults to 1s.
 			CommitInterval time.Duration
 
+			AutoCommit struct {
+				// Whether to commit the marked offsets every CommitInterval
+				// and when closing (default true). When disabled, the offsets
+				// are only committed by the Commit methods of
+				// ConsumerGroupSession, OffsetManager and
+				// PartitionOffsetManager, which block until they are, the
+				// offsets marked but not committed being dropped on close.
+				Enable bool
+			}
+
 			// The initial offset to use if no offset was previously committed.
 			// Should be OffsetNewest or OffsetOldest. Defaults to OffsetNewest.
 			Initial int64
 
+			// AutoReset is where to consume a partition from when its offset
+			// is out of range, and for consumer groups when no offset was
+			// committed for it, taking precedence over Initial. One of
+			// OffsetResetEarliest, OffsetResetLatest, OffsetResetNone or
+			// OffsetResetToTimestamp. When zero (the default), consumer groups
+			// start from Initial, and the partition consumers stop with
+			// ErrOffsetOutOfRange when their offset is out of range.
+			AutoReset OffsetResetPolicy
+
+			// Store, when set, is where the offsets are fetched from and
+			// committed to instead of the group coordinator, such as a
+			// FileOffsetStore or a SQLOffsetStore (default nil).
+			Store OffsetStore
+
 			// The retention duration for committed offsets. If zero, disabled
 			// (in which case the `offsets.retention.minutes` option on the
 			// broker will be used).  Kafka only supports precision up to
@@ -420,6 +499,7 @@ func NewConfig() *Config {
 	c.Consumer.MaxProcessingTime = 100 * time.Millisecond
 	c.Consumer.Return.Errors = false
 	c.Consumer.Offsets.CommitInterval = 1 * time.Second
+	c.Consumer.Offsets.AutoCommit.Enable = true
 	c.Consumer.Offsets.Initial = OffsetNewest
 	c.Consumer.Offsets.Retry.Max = 3
 
@@ -502,6 +582,22 @@ func (c *Config) Validate() error {
 		return ConfigurationError("Net.WriteTimeout must be > 0")
 	case c.Net.KeepAlive < 0:
 		return ConfigurationError("Net.KeepAlive must be >= 0")
+	case c.Net.MaxIdleTime < 0:
+		return ConfigurationError("Net.MaxIdleTime must be >= 0")
+	case c.Net.MaxConnectionAge < 0:
+		return ConfigurationError("Net.MaxConnectionAge must be >= 0")
+	case c.Net.Dialer != nil && c.Net.Proxy.Enable:
+		return ConfigurationError("Net.Dialer and Net.Proxy are mutually exclusive")
+	case c.Net.Proxy.Enable && c.Net.Proxy.Dialer == nil:
+		return ConfigurationError("Net.Proxy.Dialer must be set when Net.Proxy is enabled")
+	case c.Net.ClientDNSLookup < DefaultDNSLookup || c.Net.ClientDNSLookup > ResolveCanonicalBootstrapServersOnly:
+		return ConfigurationError("Net.ClientDNSLookup is invalid")
+	case c.Net.TLS.CloseBeforeExpiry < 0:
+		return ConfigurationError("Net.TLS.CloseBeforeExpiry must be >= 0")
+	case c.Net.SASL.Enable && c.Net.SASL.MechanismProviderGeneratorFunc != nil:
+		if !c.Version.IsAtLeast(V1_0_0_0) {
+			return ConfigurationError("Net.SASL.MechanismProviderGeneratorFunc requires Version >= V1_0_0_0")
+		}
 	case c.Net.SASL.Enable:
 		if c.Net.SASL.Mechanism == "" {
 			c.Net.SASL.Mechanism = SASLTypePlaintext
@@ -520,6 +616,12 @@ func (c *Config) Validate() error {
 				return ConfigurationError("An AccessTokenProvider instance must be provided to Net.SASL.TokenProvider")
 			}
 		case SASLTypeSCRAMSHA256, SASLTypeSCRAMSHA512:
+			if token := c.Net.SASL.DelegationToken; token != nil {
+				if token.TokenID == "" || len(token.HMAC) == 0 {
+					return ConfigurationError("Net.SASL.DelegationToken must have a TokenID and an HMAC")
+				}
+				break
+			}
 			if c.Net.SASL.User == "" {
 				return ConfigurationError("Net.SASL.User must not be empty when SASL is enabled")
 			}
@@ -650,6 +752,10 @@ func (c *Config) Validate() error {
 		return ConfigurationError("Consumer.Offsets.CommitInterval must be > 0")
 	case c.Consumer.Offsets.Initial != OffsetOldest && c.Consumer.Offsets.Initial != OffsetNewest:
 		return ConfigurationError("Consumer.Offsets.Initial must be OffsetOldest or OffsetNewest")
+	case c.Consumer.Offsets.AutoReset < OffsetResetNone:
+		return ConfigurationError("Consumer.Offsets.AutoReset must be OffsetResetEarliest, OffsetResetLatest, OffsetResetNone or a timestamp")
+	case c.Consumer.Offsets.AutoReset > 0 && !c.Version.IsAtLeast(V0_10_1_0):
+		return ConfigurationError("Consumer.Offsets.AutoReset to a timestamp requires Version >= V0_10_1_0")
 	case c.Consumer.Offsets.Retry.Max < 0:
 		return ConfigurationError("Consumer.Offsets.Retry.Max must be >= 0")
 	case c.Consumer.IsolationLevel != ReadUncommitted && c.Consumer.IsolationLevel != ReadCommitted:
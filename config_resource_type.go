@@ -8,15 +8,20 @@ type ConfigResourceType int8
 
 const (
 	//UnknownResource constant type
-	UnknownResource ConfigResourceType = iota
+	UnknownResource ConfigResourceType = 0
 	//AnyResource constant type
-	AnyResource
+	AnyResource ConfigResourceType = 1
 	//TopicResource constant type
-	TopicResource
+	TopicResource ConfigResourceType = 2
 	//GroupResource constant type
-	GroupResource
+	GroupResource ConfigResourceType = 3
 	//ClusterResource constant type
-	ClusterResource
-	//BrokerResource constant type
-	BrokerResource
+	//
+	//Deprecated: the protocol has no cluster resource type, 4 identifies
+	//brokers, use BrokerResource instead.
+	ClusterResource ConfigResourceType = 4
+	//BrokerResource constant type, brokers are identified by 4 on the wire
+	BrokerResource ConfigResourceType = 4
+	//BrokerLoggerResource constant type, the log4j loggers of a broker (KIP-412)
+	BrokerLoggerResource ConfigResourceType = 8
 )
@@ -1,10 +1,13 @@
 package sarama
 
 import (
+	"net"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/rcrowley/go-metrics"
+	"golang.org/x/net/proxy"
 )
 
 func TestDefaultConfigValidates(t *testing.T) {
@@ -72,6 +75,13 @@ func TestNetConfigValidates(t *testing.T) {
 				cfg.Net.KeepAlive = -1
 			},
 			"Net.KeepAlive must be >= 0"},
+		{"Dialer and Proxy",
+			func(cfg *Config) {
+				cfg.Net.Dialer = &net.Dialer{}
+				cfg.Net.Proxy.Enable = true
+				cfg.Net.Proxy.Dialer = proxy.Direct
+			},
+			"Net.Dialer and Net.Proxy are mutually exclusive"},
 		{"SASL.User",
 			func(cfg *Config) {
 				cfg.Net.SASL.Enable = true
@@ -117,6 +127,19 @@ func TestNetConfigValidates(t *testing.T) {
 				cfg.Net.SASL.Password = "stong_password"
 			},
 			"A SCRAMClientGeneratorFunc function must be provided to Net.SASL.SCRAMClientGeneratorFunc"},
+		{"SASL.Mechanism SCRAM-SHA-256 - Delegation token without HMAC",
+			func(cfg *Config) {
+				cfg.Net.SASL.Enable = true
+				cfg.Net.SASL.Mechanism = SASLTypeSCRAMSHA256
+				cfg.Net.SASL.DelegationToken = &DelegationToken{TokenID: "token-id"}
+			},
+			"Net.SASL.DelegationToken must have a TokenID and an HMAC"},
+		{"SASL.MechanismProviderGeneratorFunc - Version before 1.0.0",
+			func(cfg *Config) {
+				cfg.Net.SASL.Enable = true
+				cfg.Net.SASL.MechanismProviderGeneratorFunc = func() SASLMechanismProvider { return &challengeMechanism{} }
+			},
+			"Net.SASL.MechanismProviderGeneratorFunc requires Version >= V1_0_0_0"},
 		{"SASL.Mechanism GSSAPI (Kerberos) - Using User/Password, Missing password field",
 			func(cfg *Config) {
 				cfg.Net.SASL.Enable = true
@@ -382,6 +405,19 @@ func TestConsumerConfigValidates(t *testing.T) {
 			},
 			"Consumer.IsolationLevel must be ReadUncommitted or ReadCommitted",
 		},
+		{"Incorrect offset reset policy",
+			func(cfg *Config) {
+				cfg.Consumer.Offsets.AutoReset = OffsetResetPolicy(-4)
+			},
+			"Consumer.Offsets.AutoReset must be OffsetResetEarliest, OffsetResetLatest, OffsetResetNone or a timestamp",
+		},
+		{"Offset reset to a timestamp Version",
+			func(cfg *Config) {
+				cfg.Version = V0_10_0_0
+				cfg.Consumer.Offsets.AutoReset = OffsetResetToTimestamp(time.Now())
+			},
+			"Consumer.Offsets.AutoReset to a timestamp requires Version >= V0_10_1_0",
+		},
 	}
 
 	for i, test := range tests {
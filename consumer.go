@@ -34,6 +34,23 @@ func (ce ConsumerError) Error() string {
 	return fmt.Sprintf("kafka: error while consuming %s/%d: %s", ce.Topic, ce.Partition, ce.Err)
 }
 
+// LogTruncationError is the error of the ConsumerError sent when the log of a
+// partition is found to have been truncated past the position of its consumer,
+// as happens when a leader is elected which did not replicate all the records
+// already consumed. Consuming resumes at DivergingOffset, the records consumed
+// from there on having been replaced by the new leader. The truncation is only
+// detected from Kafka 2.1.0 on, and for the records with leader epochs.
+type LogTruncationError struct {
+	FetchOffset     int64 // the offset the consumer was at
+	DivergingOffset int64 // the end offset of the last epoch common to the consumer and the leader
+	LeaderEpoch     int32 // the epoch of the last record consumed
+}
+
+func (err *LogTruncationError) Error() string {
+	return fmt.Sprintf("kafka: log truncated at offset %d after epoch %d, consumer was at offset %d",
+		err.DivergingOffset, err.LeaderEpoch, err.FetchOffset)
+}
+
 // ConsumerErrors is a type that wraps a batch of errors and implements the Error interface.
 // It can be returned from the PartitionConsumer's Close methods to avoid the need to manually drain errors
 // when stopping.
@@ -127,16 +144,17 @@ func (c *consumer) Partitions(topic string) ([]int32, error) {
 
 func (c *consumer) ConsumePartition(topic string, partition int32, offset int64) (PartitionConsumer, error) {
 	child := &partitionConsumer{
-		consumer:  c,
-		conf:      c.conf,
-		topic:     topic,
-		partition: partition,
-		messages:  make(chan *ConsumerMessage, c.conf.ChannelBufferSize),
-		errors:    make(chan *ConsumerError, c.conf.ChannelBufferSize),
-		feeder:    make(chan *FetchResponse, 1),
-		trigger:   make(chan none, 1),
-		dying:     make(chan none),
-		fetchSize: c.conf.Consumer.Fetch.Default,
+		consumer:     c,
+		conf:         c.conf,
+		topic:        topic,
+		partition:    partition,
+		messages:     make(chan *ConsumerMessage, c.conf.ChannelBufferSize),
+		errors:       make(chan *ConsumerError, c.conf.ChannelBufferSize),
+		feeder:       make(chan *FetchResponse, 1),
+		trigger:      make(chan none, 1),
+		dying:        make(chan none),
+		fetchSize:    c.conf.Consumer.Fetch.Default,
+		fetchedEpoch: -1,
 	}
 
 	if err := child.chooseStartingOffset(offset); err != nil {
@@ -145,7 +163,7 @@ func (c *consumer) ConsumePartition(topic string, partition int32, offset int64)
 
 	var leader *Broker
 	var err error
-	if leader, err = c.client.Leader(child.topic, child.partition); err != nil {
+	if leader, child.leaderEpoch, err = leaderAndEpoch(c.client, child.topic, child.partition); err != nil {
 		return nil, err
 	}
 
@@ -306,6 +324,11 @@ type partitionConsumer struct {
 	fetchSize      int32
 	offset         int64
 	retries        int32
+	leaderEpoch    int32 // the epoch of the leader consumed from, -1 if unknown
+	fetchedEpoch   int32 // the epoch of the leader which appended the last record consumed, -1 if unknown
+	validate       bool  // whether the offset must be validated against the log of the new leader
+	outOfRange     bool  // whether the offset got out of range, to be checked for the truncation of the log
+	reset          bool  // whether the offset must be reset as per Consumer.Offsets.AutoReset if out of range
 }
 
 var errTimedOut = errors.New("timed out feeding messages to the user") // not user-facing
@@ -346,6 +369,12 @@ func (child *partitionConsumer) dispatcher() {
 			Logger.Printf("consumer/%s/%d finding new broker\n", child.topic, child.partition)
 			if err := child.dispatch(); err != nil {
 				child.sendError(err)
+				if _, ok := err.(*OffsetOutOfRangeError); ok || err == ErrOffsetOutOfRange {
+					// there's no point in retrying this it will just fail the same way again
+					Logger.Printf("consumer/%s/%d shutting down because %s\n", child.topic, child.partition, err)
+					close(child.trigger)
+					continue
+				}
 				child.trigger <- none{}
 			}
 		}
@@ -365,10 +394,45 @@ func (child *partitionConsumer) dispatch() error {
 
 	var leader *Broker
 	var err error
-	if leader, err = child.consumer.client.Leader(child.topic, child.partition); err != nil {
+	if leader, child.leaderEpoch, err = leaderAndEpoch(child.consumer.client, child.topic, child.partition); err != nil {
 		return err
 	}
 
+	if child.outOfRange {
+		// the offset may be out of range because the log of the leader got
+		// truncated, the consumer moving back to where they diverged then
+		truncated, err := child.detectTruncation(leader)
+		if err != nil {
+			Logger.Printf("consumer/%s/%d failed to detect log truncation: %s\n", child.topic, child.partition, err)
+		}
+		child.outOfRange = false
+		if !truncated {
+			if child.conf.Consumer.Offsets.AutoReset == 0 {
+				return ErrOffsetOutOfRange
+			}
+			child.reset = true
+		}
+	}
+
+	if child.reset {
+		offset := child.offset
+		if err := child.chooseStartingOffset(offset); err != nil {
+			return err
+		}
+		if child.offset != offset {
+			child.fetchedEpoch = -1
+		}
+		child.reset = false
+		child.validate = false
+	}
+
+	if child.validate {
+		if _, err := child.detectTruncation(leader); err != nil {
+			return err
+		}
+		child.validate = false
+	}
+
 	child.broker = child.consumer.refBrokerConsumer(leader)
 
 	child.broker.input <- child
@@ -394,12 +458,81 @@ func (child *partitionConsumer) chooseStartingOffset(offset int64) error {
 	case offset >= oldestOffset && offset <= newestOffset:
 		child.offset = offset
 	default:
-		return ErrOffsetOutOfRange
+		return child.resetOffset(offset, oldestOffset, newestOffset)
 	}
 
 	return nil
 }
 
+// resetOffset moves the consumer to the offset of Consumer.Offsets.AutoReset,
+// offset being out of the range of the available ones.
+func (child *partitionConsumer) resetOffset(offset, oldestOffset, newestOffset int64) error {
+	if child.conf.Consumer.Offsets.AutoReset == OffsetResetNone {
+		return &OffsetOutOfRangeError{Offset: offset, Oldest: oldestOffset, Newest: newestOffset}
+	}
+
+	resetOffset, err := autoResetOffset(child.consumer.client, child.topic, child.partition, offset)
+	if err != nil {
+		return err
+	}
+	switch resetOffset {
+	case OffsetOldest:
+		resetOffset = oldestOffset
+	case OffsetNewest:
+		resetOffset = newestOffset
+	}
+
+	Logger.Printf("consumer/%s/%d offset %d out of range [%d, %d], resetting to offset %d\n",
+		child.topic, child.partition, offset, oldestOffset, newestOffset, resetOffset)
+	child.offset = resetOffset
+	return nil
+}
+
+// detectTruncation asks the leader the end offset of the epoch of the last
+// record consumed and, when it is before the offset of the consumer, reports
+// the truncation of the log and moves back to that offset (KIP-320).
+func (child *partitionConsumer) detectTruncation(leader *Broker) (bool, error) {
+	if !child.canDetectTruncation() {
+		return false, nil
+	}
+
+	request := &OffsetForLeaderEpochRequest{Version: 2}
+	request.AddBlock(child.topic, child.partition, child.leaderEpoch, child.fetchedEpoch)
+	response, err := leader.OffsetForLeaderEpoch(request)
+	if err != nil {
+		return false, err
+	}
+
+	block := response.GetBlock(child.topic, child.partition)
+	if block == nil {
+		return false, ErrIncompleteResponse
+	}
+	if block.Err != ErrNoError {
+		return false, block.Err
+	}
+	if block.EndOffset < 0 || block.EndOffset >= child.offset {
+		// the epoch is unknown to the leader, or the log did not diverge
+		return false, nil
+	}
+
+	Logger.Printf("consumer/%s/%d log truncated to offset %d at epoch %d, was at offset %d\n",
+		child.topic, child.partition, block.EndOffset, child.fetchedEpoch, child.offset)
+	child.sendError(&LogTruncationError{
+		FetchOffset:     child.offset,
+		DivergingOffset: block.EndOffset,
+		LeaderEpoch:     child.fetchedEpoch,
+	})
+	child.offset = block.EndOffset
+	child.fetchedEpoch = block.LeaderEpoch
+	return true, nil
+}
+
+// canDetectTruncation tells whether the leader can be asked for the end offset
+// of the epoch of the last record consumed
+func (child *partitionConsumer) canDetectTruncation() bool {
+	return child.fetchedEpoch >= 0 && child.conf.Version.IsAtLeast(V2_1_0_0)
+}
+
 func (child *partitionConsumer) Messages() <-chan *ConsumerMessage {
 	return child.messages
 }
@@ -548,6 +681,7 @@ func (child *partitionConsumer) parseRecords(batch *RecordBatch) ([]*ConsumerMes
 	if len(messages) == 0 {
 		child.offset++
 	}
+	child.fetchedEpoch = batch.PartitionLeaderEpoch
 	return messages, nil
 }
 
@@ -818,14 +952,34 @@ func (bc *brokerConsumer) handleResponses() {
 			Logger.Printf("consumer/broker/%d abandoned subscription to %s/%d because consuming was taking too long\n",
 				bc.broker.ID(), child.topic, child.partition)
 			delete(bc.subscriptions, child)
+		case ErrFencedLeaderEpoch:
+			// the leader changed since we last refreshed the metadata, and it may
+			// not have all the records we consumed: validate our offset against
+			// its log once redispatched
+			Logger.Printf("consumer/broker/%d abandoned subscription to %s/%d because %s\n",
+				bc.broker.ID(), child.topic, child.partition, result)
+			child.validate = true
+			child.trigger <- none{}
+			delete(bc.subscriptions, child)
 		case ErrOffsetOutOfRange:
+			if child.conf.Consumer.Offsets.AutoReset != 0 || child.canDetectTruncation() {
+				// check the log for truncation, and reset the offset if it is
+				// not the cause, once redispatched so that the other
+				// subscriptions to the broker are not held back meanwhile
+				Logger.Printf("consumer/broker/%d abandoned subscription to %s/%d because %s\n",
+					bc.broker.ID(), child.topic, child.partition, result)
+				child.outOfRange = true
+				child.trigger <- none{}
+				delete(bc.subscriptions, child)
+				continue
+			}
 			// there's no point in retrying this it will just fail the same way again
 			// shut it down and force the user to choose what to do
 			child.sendError(result)
 			Logger.Printf("consumer/%s/%d shutting down because %s\n", child.topic, child.partition, result)
 			close(child.trigger)
 			delete(bc.subscriptions, child)
-		case ErrUnknownTopicOrPartition, ErrNotLeaderForPartition, ErrLeaderNotAvailable, ErrReplicaNotAvailable:
+		case ErrUnknownTopicOrPartition, ErrNotLeaderForPartition, ErrLeaderNotAvailable, ErrReplicaNotAvailable, ErrUnknownLeaderEpoch:
 			// not an error, but does need redispatching
 			Logger.Printf("consumer/broker/%d abandoned subscription to %s/%d because %s\n",
 				bc.broker.ID(), child.topic, child.partition, result)
@@ -883,8 +1037,14 @@ func (bc *brokerConsumer) fetchNewMessages() (*FetchResponse, error) {
 		request.Isolation = bc.consumer.conf.Consumer.IsolationLevel
 	}
 
+	if bc.consumer.conf.Version.IsAtLeast(V2_1_0_0) {
+		// the fetch sessions are not used, each request fetches all the partitions
+		request.Version = 9
+		request.SessionEpoch = -1
+	}
+
 	for child := range bc.subscriptions {
-		request.AddBlock(child.topic, child.partition, child.offset, child.fetchSize)
+		request.AddBlockWithLeaderEpoch(child.topic, child.partition, child.offset, child.fetchSize, child.leaderEpoch)
 	}
 
 	return bc.broker.Fetch(request)
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"reflect"
+	"regexp"
 	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -23,14 +26,16 @@ type ConsumerGroup interface {
 	// 1. The consumers join the group (as explained in https://kafka.apache.org/documentation/#intro_consumers)
 	//    and is assigned their "fair share" of partitions, aka 'claims'.
 	// 2. Before processing starts, the handler's Setup() hook is called to notify the user
-	//    of the claims and allow any necessary preparation or alteration of state.
+	//    of the claims and allow any necessary preparation or alteration of state, followed
+	//    by OnPartitionsAssigned() if the handler is a ConsumerGroupRebalanceListener.
 	// 3. For each of the assigned claims the handler's ConsumeClaim() function is then called
 	//    in a separate goroutine which requires it to be thread-safe. Any state must be carefully protected
 	//    from concurrent reads/writes.
 	// 4. The session will persist until one of the ConsumeClaim() functions exits. This can be either when the
 	//    parent context is cancelled or when a server-side rebalance cycle is initiated.
 	// 5. Once all the ConsumeClaim() loops have exited, the handler's Cleanup() hook is called
-	//    to allow the user to perform any final tasks before a rebalance.
+	//    to allow the user to perform any final tasks before a rebalance, preceded by
+	//    OnPartitionsRevoked(), or OnPartitionsLost(), for ConsumerGroupRebalanceListeners.
 	// 6. Finally, marked offsets are committed one last time before claims are released.
 	//
 	// Please note, that once a rebalance is triggered, sessions must be completed within
@@ -51,6 +56,22 @@ type ConsumerGroup interface {
 	Close() error
 }
 
+// RegexConsumerGroup is implemented by the ConsumerGroups which consume the
+// topics matching a pattern, as the ones of NewConsumerGroup and
+// NewConsumerGroupFromClient do.
+type RegexConsumerGroup interface {
+	ConsumerGroup
+
+	// ConsumeRegex is like Consume for the topics whose names match pattern,
+	// none possibly, the topics internal to Kafka excepted. When it is the
+	// leader of the group, the member watches the metadata refreshed by the
+	// client every Metadata.RefreshFrequency and ends the session when
+	// matching topics are created or deleted, or when partitions are added
+	// to them, so that the group rebalances as soon as ConsumeRegex is
+	// called again.
+	ConsumeRegex(ctx context.Context, pattern *regexp.Regexp, handler ConsumerGroupHandler) error
+}
+
 type consumerGroup struct {
 	client Client
 
@@ -163,6 +184,45 @@ func (c *consumerGroup) Consume(ctx context.Context, topics []string, handler Co
 		return err
 	}
 
+	return c.consume(ctx, topics, handler, nil)
+}
+
+// ConsumeRegex implements RegexConsumerGroup.
+func (c *consumerGroup) ConsumeRegex(ctx context.Context, pattern *regexp.Regexp, handler ConsumerGroupHandler) error {
+	// Ensure group is not closed
+	select {
+	case <-c.closed:
+		return ErrClosedConsumerGroup
+	default:
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	// Refresh metadata for all topics, the ones of the last session included
+	if err := c.client.RefreshMetadata(); err != nil {
+		return err
+	}
+
+	matched, err := c.matchTopics(pattern)
+	if err != nil {
+		return err
+	}
+	topics := make([]string, 0, len(matched))
+	for topic := range matched {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	// Watch the matched topics if we lead the group
+	return c.consume(ctx, topics, handler, func(sess *consumerGroupSession) {
+		c.watchTopics(sess, pattern, matched)
+	})
+}
+
+// consume runs a session of the group consuming topics until it ends, watch
+// being run in the background meanwhile if set and the member leads the group
+func (c *consumerGroup) consume(ctx context.Context, topics []string, handler ConsumerGroupHandler, watch func(*consumerGroupSession)) error {
 	// Init session
 	sess, err := c.newSession(ctx, topics, handler, c.config.Consumer.Group.Rebalance.Retry.Max)
 	if err == ErrClosedClient {
@@ -171,6 +231,10 @@ func (c *consumerGroup) Consume(ctx context.Context, topics []string, handler Co
 		return err
 	}
 
+	if watch != nil && sess.leader {
+		go withRecover(func() { watch(sess) })
+	}
+
 	// Wait for session exit signal
 	<-sess.ctx.Done()
 
@@ -178,6 +242,67 @@ func (c *consumerGroup) Consume(ctx context.Context, topics []string, handler Co
 	return sess.release(true)
 }
 
+// matchTopics returns the number of partitions of the topics matching pattern,
+// but for the ones internal to Kafka
+func (c *consumerGroup) matchTopics(pattern *regexp.Regexp) (map[string]int, error) {
+	topics, err := c.client.Topics()
+	if err != nil {
+		return nil, err
+	}
+	internal, _ := c.client.(internalTopicsClient)
+
+	matched := make(map[string]int)
+	for _, topic := range topics {
+		if !pattern.MatchString(topic) || (internal != nil && internal.isInternalTopic(topic)) {
+			continue
+		}
+		partitions, err := c.client.Partitions(topic)
+		if err != nil {
+			return nil, err
+		}
+		matched[topic] = len(partitions)
+	}
+	return matched, nil
+}
+
+// watchTopics ends the session once the topics matching pattern, or their
+// partitions, differ from matched, for the group to rebalance
+func (c *consumerGroup) watchTopics(sess *consumerGroupSession, pattern *regexp.Regexp, matched map[string]int) {
+	if c.config.Metadata.RefreshFrequency == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(c.config.Metadata.RefreshFrequency)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-sess.ctx.Done():
+			return
+		}
+
+		if !c.config.Metadata.Full {
+			// the background updater only refreshes the topics known
+			if err := c.client.RefreshMetadata(); err != nil {
+				Logger.Printf("consumergroup/%s failed to refresh the metadata of the topics: %v\n", c.groupID, err)
+				continue
+			}
+		}
+
+		current, err := c.matchTopics(pattern)
+		if err != nil {
+			Logger.Printf("consumergroup/%s failed to match the topics: %v\n", c.groupID, err)
+			continue
+		}
+		if !reflect.DeepEqual(current, matched) {
+			Logger.Printf("consumergroup/%s topics matching %s changed, rebalancing\n", c.groupID, pattern)
+			sess.cancel()
+			return
+		}
+	}
+}
+
 func (c *consumerGroup) retryNewSession(ctx context.Context, topics []string, handler ConsumerGroupHandler, retries int, refreshCoordinator bool) (*consumerGroupSession, error) {
 	select {
 	case <-c.closed:
@@ -288,7 +413,12 @@ func (c *consumerGroup) newSession(ctx context.Context, topics []string, handler
 		}
 	}
 
-	return newConsumerGroupSession(ctx, c, claims, join.MemberId, join.GenerationId, handler)
+	sess, err := newConsumerGroupSession(ctx, c, claims, join.MemberId, join.GenerationId, handler)
+	if err != nil {
+		return nil, err
+	}
+	sess.leader = join.LeaderId == join.MemberId
+	return sess, nil
 }
 
 func (c *consumerGroup) joinGroupRequest(coordinator *Broker, topics []string) (*JoinGroupResponse, error) {
@@ -457,6 +587,14 @@ type ConsumerGroupSession interface {
 	// MarkMessage marks a message as consumed.
 	MarkMessage(msg *ConsumerMessage, metadata string)
 
+	// Commit commits the offsets marked in the session and not committed yet,
+	// blocking until the coordinator acknowledged them. When some partitions
+	// fail to commit, it returns their errors as ConsumerErrors. Use it with
+	// Consumer.Offsets.AutoCommit.Enable disabled to commit once the messages
+	// are processed, the offsets marked but not committed being lost when the
+	// session ends.
+	Commit() error
+
 	// Context returns the session context.
 	Context() context.Context
 }
@@ -468,6 +606,7 @@ type consumerGroupSession struct {
 	handler      ConsumerGroupHandler
 
 	claims  map[string][]int32
+	leader  bool
 	offsets *offsetManager
 	ctx     context.Context
 	cancel  func()
@@ -475,6 +614,9 @@ type consumerGroupSession struct {
 	waitGroup       sync.WaitGroup
 	releaseOnce     sync.Once
 	hbDying, hbDead chan none
+
+	assigned bool
+	lost     int32
 }
 
 func newConsumerGroupSession(ctx context.Context, parent *consumerGroup, claims map[string][]int32, memberID string, generationID int32, handler ConsumerGroupHandler) (*consumerGroupSession, error) {
@@ -527,6 +669,10 @@ func newConsumerGroupSession(ctx context.Context, parent *consumerGroup, claims
 		_ = sess.release(true)
 		return nil, err
 	}
+	if listener, ok := handler.(ConsumerGroupRebalanceListener); ok {
+		listener.OnPartitionsAssigned(sess, claims)
+		sess.assigned = true
+	}
 
 	// start consuming
 	for topic, partitions := range claims {
@@ -568,6 +714,10 @@ func (s *consumerGroupSession) MarkMessage(msg *ConsumerMessage, metadata string
 	s.MarkOffset(msg.Topic, msg.Partition, msg.Offset+1, metadata)
 }
 
+func (s *consumerGroupSession) Commit() error {
+	return s.offsets.Commit()
+}
+
 func (s *consumerGroupSession) Context() context.Context {
 	return s.ctx
 }
@@ -588,6 +738,15 @@ func (s *consumerGroupSession) consume(topic string, partition int32) {
 		offset, _ = pom.NextOffset()
 	}
 
+	// no offset was committed
+	if offset < 0 && s.parent.config.Consumer.Offsets.AutoReset != 0 {
+		var err error
+		if offset, err = autoResetOffset(s.parent.client, topic, partition, -1); err != nil {
+			s.parent.handleError(err, topic, partition)
+			return
+		}
+	}
+
 	// create new claim
 	claim, err := newConsumerGroupClaim(s, topic, partition, offset)
 	if err != nil {
@@ -632,6 +791,14 @@ func (s *consumerGroupSession) release(withCleanup bool) (err error) {
 
 	// perform release
 	s.releaseOnce.Do(func() {
+		if listener, ok := s.handler.(ConsumerGroupRebalanceListener); ok && s.assigned {
+			if atomic.LoadInt32(&s.lost) == 1 {
+				listener.OnPartitionsLost(s, s.claims)
+			} else {
+				listener.OnPartitionsRevoked(s, s.claims)
+			}
+		}
+
 		if withCleanup {
 			if e := s.handler.Cleanup(s); e != nil {
 				s.parent.handleError(e, "", -1)
@@ -691,7 +858,12 @@ func (s *consumerGroupSession) heartbeatLoop() {
 		switch resp.Err {
 		case ErrNoError:
 			retries = s.parent.config.Metadata.Retry.Max
-		case ErrRebalanceInProgress, ErrUnknownMemberId, ErrIllegalGeneration:
+		case ErrRebalanceInProgress:
+			return
+		case ErrUnknownMemberId, ErrIllegalGeneration:
+			// the member was removed from the group, its partitions may
+			// already be consumed by others
+			atomic.StoreInt32(&s.lost, 1)
 			return
 		default:
 			s.parent.handleError(err, "", -1)
@@ -728,6 +900,28 @@ type ConsumerGroupHandler interface {
 	ConsumeClaim(ConsumerGroupSession, ConsumerGroupClaim) error
 }
 
+// ConsumerGroupRebalanceListener can be implemented by ConsumerGroupHandlers to
+// be notified of the partitions assigned to and taken from the member, for
+// instance to set up and flush per partition state. As the partitions are
+// rebalanced eagerly, all the partitions of a session are revoked, or lost, at
+// its end, the ones assigned again being part of the next session.
+type ConsumerGroupRebalanceListener interface {
+	// OnPartitionsAssigned is run with the partitions claimed by a new
+	// session, after Setup and before the ConsumeClaim goroutines start.
+	OnPartitionsAssigned(sess ConsumerGroupSession, partitions map[string][]int32)
+
+	// OnPartitionsRevoked is run with the partitions claimed by the session
+	// once all the ConsumeClaim goroutines have exited, before Cleanup and
+	// the final commit of the offsets, which can still be marked.
+	OnPartitionsRevoked(sess ConsumerGroupSession, partitions map[string][]int32)
+
+	// OnPartitionsLost is run instead of OnPartitionsRevoked when the member
+	// was removed from the group before the session ended, such as when it
+	// missed its heartbeats. The partitions may then already be consumed by
+	// other members, and their offsets can no longer be committed.
+	OnPartitionsLost(sess ConsumerGroupSession, partitions map[string][]int32)
+}
+
 // ConsumerGroupClaim processes Kafka messages from a given topic and partition within a consumer group.
 type ConsumerGroupClaim interface {
 	// Topic returns the consumed topic name.
@@ -3,6 +3,10 @@ package sarama
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
 )
 
 type exampleConsumerGroupHandler struct{}
@@ -56,3 +60,195 @@ func ExampleConsumerGroup() {
 		}
 	}
 }
+
+// rebalanceListener records the calls of its ConsumerGroupRebalanceListener
+// methods
+type rebalanceListener struct {
+	exampleConsumerGroupHandler
+	calls []string
+}
+
+func (l *rebalanceListener) OnPartitionsAssigned(_ ConsumerGroupSession, partitions map[string][]int32) {
+	l.calls = append(l.calls, fmt.Sprint("assigned ", partitions))
+}
+
+func (l *rebalanceListener) OnPartitionsRevoked(_ ConsumerGroupSession, partitions map[string][]int32) {
+	l.calls = append(l.calls, fmt.Sprint("revoked ", partitions))
+}
+
+func (l *rebalanceListener) OnPartitionsLost(_ ConsumerGroupSession, partitions map[string][]int32) {
+	l.calls = append(l.calls, fmt.Sprint("lost ", partitions))
+}
+
+func TestConsumerGroupRebalanceListener(t *testing.T) {
+	for _, tc := range []struct {
+		heartbeat KError
+		expected  []string
+	}{
+		{ErrRebalanceInProgress, []string{"assigned map[my_topic:[0 1]]", "revoked map[my_topic:[0 1]]"}},
+		{ErrUnknownMemberId, []string{"assigned map[my_topic:[0 1]]", "lost map[my_topic:[0 1]]"}},
+	} {
+		broker := NewMockBroker(t, 1)
+		assignment, err := encode(&ConsumerGroupMemberAssignment{Topics: map[string][]int32{"my_topic": {1, 0}}}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		broker.SetHandlerByMap(map[string]MockResponse{
+			"MetadataRequest": NewMockMetadataResponse(t).
+				SetBroker(broker.Addr(), broker.BrokerID()).
+				SetLeader("my_topic", 0, broker.BrokerID()).
+				SetLeader("my_topic", 1, broker.BrokerID()),
+			"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+				SetCoordinator(CoordinatorGroup, "my_group", broker),
+			"JoinGroupRequest": NewMockWrapper(&JoinGroupResponse{
+				GenerationId: 1,
+				LeaderId:     "other_member",
+				MemberId:     "my_member",
+			}),
+			"SyncGroupRequest":  NewMockWrapper(&SyncGroupResponse{MemberAssignment: assignment}),
+			"HeartbeatRequest":  NewMockWrapper(&HeartbeatResponse{Err: tc.heartbeat}),
+			"LeaveGroupRequest": NewMockWrapper(&LeaveGroupResponse{}),
+			"OffsetFetchRequest": NewMockOffsetFetchResponse(t).
+				SetOffset("my_group", "my_topic", 0, -1, "", ErrNoError).
+				SetOffset("my_group", "my_topic", 1, -1, "", ErrNoError),
+			"OffsetRequest": NewMockOffsetResponse(t).
+				SetVersion(1).
+				SetOffset("my_topic", 0, OffsetNewest, 0).
+				SetOffset("my_topic", 0, OffsetOldest, 0).
+				SetOffset("my_topic", 1, OffsetNewest, 0).
+				SetOffset("my_topic", 1, OffsetOldest, 0),
+			"FetchRequest": NewMockFetchResponse(t, 1).SetVersion(3),
+		})
+
+		config := NewConfig()
+		config.Version = V0_10_2_0
+		group, err := NewConsumerGroup([]string{broker.Addr()}, "my_group", config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		listener := &rebalanceListener{}
+		if err := group.Consume(context.Background(), []string{"my_topic"}, listener); err != nil {
+			t.Error(err)
+		}
+		if !reflect.DeepEqual(listener.calls, tc.expected) {
+			t.Errorf("heartbeat %v: expected %v, got %v", tc.heartbeat, tc.expected, listener.calls)
+		}
+
+		safeClose(t, group)
+		broker.Close()
+	}
+}
+
+func TestConsumerGroupConsumeRegexRebalancesOnNewTopics(t *testing.T) {
+	broker := NewMockBroker(t, 1)
+	defer broker.Close()
+
+	member, err := encode(&ConsumerGroupMemberMetadata{Topics: []string{"tenant_1"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assignment, err := encode(&ConsumerGroupMemberAssignment{Topics: map[string][]int32{"tenant_1": {0}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handlers := func(topics ...string) map[string]MockResponse {
+		metadata := NewMockMetadataResponse(t).SetBroker(broker.Addr(), broker.BrokerID())
+		for _, topic := range topics {
+			metadata.SetLeader(topic, 0, broker.BrokerID())
+		}
+		return map[string]MockResponse{
+			"MetadataRequest": metadata,
+			"FindCoordinatorRequest": NewMockFindCoordinatorResponse(t).
+				SetCoordinator(CoordinatorGroup, "my_group", broker),
+			"JoinGroupRequest": NewMockWrapper(&JoinGroupResponse{
+				GenerationId: 1,
+				LeaderId:     "my_member",
+				MemberId:     "my_member",
+				Members:      map[string][]byte{"my_member": member},
+			}),
+			"SyncGroupRequest":  NewMockWrapper(&SyncGroupResponse{MemberAssignment: assignment}),
+			"HeartbeatRequest":  NewMockWrapper(&HeartbeatResponse{}),
+			"LeaveGroupRequest": NewMockWrapper(&LeaveGroupResponse{}),
+			"OffsetFetchRequest": NewMockOffsetFetchResponse(t).
+				SetOffset("my_group", "tenant_1", 0, -1, "", ErrNoError),
+			"OffsetRequest": NewMockOffsetResponse(t).
+				SetVersion(1).
+				SetOffset("tenant_1", 0, OffsetNewest, 0).
+				SetOffset("tenant_1", 0, OffsetOldest, 0),
+			"FetchRequest": NewMockFetchResponse(t, 1).SetVersion(3),
+		}
+	}
+	broker.SetHandlerByMap(handlers("tenant_1", "other"))
+
+	config := NewConfig()
+	config.Version = V0_10_2_0
+	config.Metadata.RefreshFrequency = 10 * time.Millisecond
+	group, err := NewConsumerGroup([]string{broker.Addr()}, "my_group", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, group)
+
+	// a tenant topic is created once the session started
+	time.AfterFunc(50*time.Millisecond, func() {
+		broker.SetHandlerByMap(handlers("tenant_1", "tenant_2", "other"))
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := group.(RegexConsumerGroup).ConsumeRegex(ctx, regexp.MustCompile("^tenant_"), exampleConsumerGroupHandler{}); err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Err() != nil {
+		t.Fatal("Expected the session to end once the new topic was found")
+	}
+
+	var joined []string
+	for _, rr := range broker.History() {
+		if req, ok := rr.Request.(*JoinGroupRequest); ok {
+			meta := new(ConsumerGroupMemberMetadata)
+			if err := decode(req.GroupProtocols[config.Consumer.Group.Rebalance.Strategy.Name()], meta); err != nil {
+				t.Fatal(err)
+			}
+			joined = meta.Topics
+		}
+	}
+	if !reflect.DeepEqual(joined, []string{"tenant_1"}) {
+		t.Error("Expected to join with the matching topics, got", joined)
+	}
+}
+
+func TestConsumerGroupMatchTopicsSkipsInternalTopics(t *testing.T) {
+	broker := NewMockBroker(t, 1)
+	defer broker.Close()
+	broker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("__consumer_offsets", 0, broker.BrokerID()).
+			SetLeader("tenant_1", 0, broker.BrokerID()).
+			SetLeader("tenant_1", 1, broker.BrokerID()).
+			SetInternal("__consumer_offsets"),
+	})
+
+	config := NewConfig()
+	config.Version = V0_10_2_0
+	client, err := NewClient([]string{broker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, client)
+	group, err := NewConsumerGroupFromClient("my_group", client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, group)
+
+	matched, err := group.(*consumerGroup).matchTopics(regexp.MustCompile(".*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(matched, map[string]int{"tenant_1": 2}) {
+		t.Error("Expected the internal topics not to be matched, got", matched)
+	}
+}
@@ -402,6 +402,203 @@ func TestConsumerShutsDownOutOfRange(t *testing.T) {
 	broker0.Close()
 }
 
+func TestConsumerDetectsLogTruncation(t *testing.T) {
+	// Given: the records from offset 11 on, appended by the leader of epoch 4,
+	// were replaced by the leader of epoch 5
+	fetchResponse1 := &FetchResponse{Version: 9}
+	for _, offset := range []int64{10, 11, 12} {
+		fetchResponse1.AddRecord("my_topic", 0, nil, testMsg, offset)
+	}
+	fetchResponse1.SetPartitionLeaderEpoch("my_topic", 0, 4)
+	fetchResponse2 := &FetchResponse{Version: 9}
+	fetchResponse2.AddError("my_topic", 0, ErrOffsetOutOfRange)
+	fetchResponse3 := &FetchResponse{Version: 9}
+	fetchResponse3.AddRecord("my_topic", 0, nil, testMsg, 11)
+	fetchResponse3.SetPartitionLeaderEpoch("my_topic", 0, 5)
+
+	cfg := NewConfig()
+	cfg.Consumer.Return.Errors = true
+	cfg.Version = V2_1_0_0
+
+	broker0 := NewMockBroker(t, 0)
+	broker0.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(broker0.Addr(), broker0.BrokerID()).
+			SetLeader("my_topic", 0, broker0.BrokerID()).
+			SetLeaderEpoch("my_topic", 0, 5),
+		"OffsetRequest": NewMockOffsetResponse(t).
+			SetVersion(1).
+			SetOffset("my_topic", 0, OffsetNewest, 1234).
+			SetOffset("my_topic", 0, OffsetOldest, 0),
+		"FetchRequest": NewMockSequence(fetchResponse1, fetchResponse2, fetchResponse3),
+		"OffsetForLeaderEpochRequest": NewMockOffsetForLeaderEpochResponse(t).
+			SetEndOffset("my_topic", 0, 4, 11),
+	})
+
+	master, err := NewConsumer([]string{broker0.Addr()}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	consumer, err := master.ConsumePartition("my_topic", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then: the truncation is reported and the replaced records consumed again
+	for _, offset := range []int64{10, 11, 12} {
+		select {
+		case msg := <-consumer.Messages():
+			assertMessageOffset(t, msg, offset)
+		case err := <-consumer.Errors():
+			t.Fatal(err)
+		}
+	}
+	select {
+	case msg := <-consumer.Messages():
+		t.Fatal("Expected the log truncation to be reported, got message", msg.Offset)
+	case err := <-consumer.Errors():
+		truncation, ok := err.Err.(*LogTruncationError)
+		if !ok {
+			t.Fatal("Expected a log truncation error, got", err)
+		}
+		if truncation.FetchOffset != 13 || truncation.DivergingOffset != 11 || truncation.LeaderEpoch != 4 {
+			t.Errorf("Unexpected log truncation error: %+v", truncation)
+		}
+	}
+	select {
+	case msg := <-consumer.Messages():
+		assertMessageOffset(t, msg, 11)
+	case err := <-consumer.Errors():
+		t.Fatal(err)
+	}
+
+	safeClose(t, consumer)
+	safeClose(t, master)
+	broker0.Close()
+
+	for _, rr := range broker0.History() {
+		if request, ok := rr.Request.(*FetchRequest); ok {
+			if request.Version != 9 {
+				t.Error("Expected fetch requests of version 9, got", request.Version)
+			}
+			if epoch := request.blocks["my_topic"][0].currentLeaderEpoch; epoch != 5 {
+				t.Error("Expected the current leader epoch to be sent, got", epoch)
+			}
+		}
+	}
+}
+
+func TestConsumerAutoResetOutOfRange(t *testing.T) {
+	// Given: the messages before offset 200 are deleted while consuming
+	fetchResponse1 := new(FetchResponse)
+	fetchResponse1.AddError("my_topic", 0, ErrOffsetOutOfRange)
+	fetchResponse2 := new(FetchResponse)
+	fetchResponse2.AddMessage("my_topic", 0, nil, testMsg, 200)
+
+	broker0 := NewMockBroker(t, 0)
+	offsetResponse := func(oldest int64) *MockOffsetResponse {
+		return NewMockOffsetResponse(t).
+			SetOffset("my_topic", 0, OffsetNewest, 1234).
+			SetOffset("my_topic", 0, OffsetOldest, oldest)
+	}
+	broker0.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(broker0.Addr(), broker0.BrokerID()).
+			SetLeader("my_topic", 0, broker0.BrokerID()),
+		"OffsetRequest": NewMockSequence(offsetResponse(0), offsetResponse(0), offsetResponse(200)),
+		"FetchRequest":  NewMockSequence(fetchResponse1, fetchResponse2),
+	})
+
+	config := NewConfig()
+	config.Consumer.Return.Errors = true
+	config.Consumer.Retry.Backoff = 0
+	config.Consumer.Offsets.AutoReset = OffsetResetEarliest
+	master, err := NewConsumer([]string{broker0.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When
+	consumer, err := master.ConsumePartition("my_topic", 0, 101)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then: consuming resumes from the oldest offset
+	select {
+	case msg := <-consumer.Messages():
+		assertMessageOffset(t, msg, 200)
+	case err := <-consumer.Errors():
+		t.Fatal(err)
+	}
+
+	safeClose(t, consumer)
+	safeClose(t, master)
+	broker0.Close()
+}
+
+func TestConsumerAutoResetNone(t *testing.T) {
+	// Given
+	fetchResponse := new(FetchResponse)
+	fetchResponse.AddError("my_topic", 0, ErrOffsetOutOfRange)
+
+	broker0 := NewMockBroker(t, 0)
+	offsetResponse := func(oldest int64) *MockOffsetResponse {
+		return NewMockOffsetResponse(t).
+			SetOffset("my_topic", 0, OffsetNewest, 1234).
+			SetOffset("my_topic", 0, OffsetOldest, oldest)
+	}
+	broker0.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t).
+			SetBroker(broker0.Addr(), broker0.BrokerID()).
+			SetLeader("my_topic", 0, broker0.BrokerID()),
+		"OffsetRequest": NewMockSequence(offsetResponse(0), offsetResponse(0), offsetResponse(0), offsetResponse(0), offsetResponse(200)),
+		"FetchRequest":  NewMockWrapper(fetchResponse),
+	})
+
+	config := NewConfig()
+	config.Consumer.Return.Errors = true
+	config.Consumer.Retry.Backoff = 0
+	config.Consumer.Offsets.AutoReset = OffsetResetNone
+	master, err := NewConsumer([]string{broker0.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// When: the offset is out of range from the start
+	_, err = master.ConsumePartition("my_topic", 0, 2000)
+
+	// Then
+	if err, ok := err.(*OffsetOutOfRangeError); !ok || *err != (OffsetOutOfRangeError{Offset: 2000, Oldest: 0, Newest: 1234}) {
+		t.Fatal("Expected an offset out of range error, got", err)
+	}
+
+	// When: the offset gets out of range while consuming
+	consumer, err := master.ConsumePartition("my_topic", 0, 101)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Then: the range is reported and the consumer shuts down
+	select {
+	case msg := <-consumer.Messages():
+		t.Fatal("Expected no message, got", msg.Offset)
+	case err := <-consumer.Errors():
+		if err, ok := err.Err.(*OffsetOutOfRangeError); !ok || *err != (OffsetOutOfRangeError{Offset: 101, Oldest: 200, Newest: 1234}) {
+			t.Fatal("Expected an offset out of range error, got", err)
+		}
+	}
+	if _, ok := <-consumer.Messages(); ok {
+		t.Error("Expected the consumer to shut down")
+	}
+	safeClose(t, consumer)
+
+	safeClose(t, master)
+	broker0.Close()
+}
+
 // If a fetch response contains messages with offsets that are smaller then
 // requested, then such messages are ignored.
 func TestConsumerExtraOffsets(t *testing.T) {
@@ -0,0 +1,124 @@
+package sarama
+
+import "time"
+
+// KafkaPrincipal is the identity of a client, such as User:alice.
+type KafkaPrincipal struct {
+	Type string
+	Name string
+}
+
+func (p KafkaPrincipal) String() string {
+	return p.Type + ":" + p.Name
+}
+
+func (p *KafkaPrincipal) encode(pe packetEncoder) error {
+	if err := pe.putString(p.Type); err != nil {
+		return err
+	}
+	return pe.putString(p.Name)
+}
+
+func (p *KafkaPrincipal) decode(pd packetDecoder) (err error) {
+	if p.Type, err = pd.getString(); err != nil {
+		return err
+	}
+	p.Name, err = pd.getString()
+	return err
+}
+
+func encodePrincipals(pe packetEncoder, principals []KafkaPrincipal) error {
+	if err := pe.putArrayLength(len(principals)); err != nil {
+		return err
+	}
+	for i := range principals {
+		if err := principals[i].encode(pe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decodePrincipals returns nil for an empty or null array of principals.
+func decodePrincipals(pd packetDecoder) ([]KafkaPrincipal, error) {
+	n, err := pd.getArrayLength()
+	if err != nil || n <= 0 {
+		return nil, err
+	}
+
+	principals := make([]KafkaPrincipal, n)
+	for i := range principals {
+		if err := principals[i].decode(pd); err != nil {
+			return nil, err
+		}
+	}
+	return principals, nil
+}
+
+// CreateDelegationTokenRequest is a request to create a delegation token
+// (KIP-48) owned by the authenticated principal.
+type CreateDelegationTokenRequest struct {
+	Version int16
+
+	// The principals allowed to renew the token, along with its owner.
+	Renewers []KafkaPrincipal
+
+	// The maximum lifetime of the token, -1 (or -1ms) for the
+	// delegation.token.max.lifetime.ms of the brokers.
+	MaxLifetime time.Duration
+}
+
+func (c *CreateDelegationTokenRequest) encode(pe packetEncoder) error {
+	if err := encodePrincipals(pe, c.Renewers); err != nil {
+		return err
+	}
+	pe.putInt64(durationToMillis(c.MaxLifetime))
+	return nil
+}
+
+func (c *CreateDelegationTokenRequest) decode(pd packetDecoder, version int16) (err error) {
+	c.Version = version
+	if c.Renewers, err = decodePrincipals(pd); err != nil {
+		return err
+	}
+
+	maxLifetime, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	c.MaxLifetime = time.Duration(maxLifetime) * time.Millisecond
+	return nil
+}
+
+func (c *CreateDelegationTokenRequest) key() int16 {
+	return 38
+}
+
+func (c *CreateDelegationTokenRequest) version() int16 {
+	return c.Version
+}
+
+func (c *CreateDelegationTokenRequest) requiredVersion() KafkaVersion {
+	if c.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
+
+// durationToMillis converts a period of a delegation token request, keeping
+// -1 as is since it asks for the default of the brokers.
+func durationToMillis(d time.Duration) int64 {
+	if d < 0 {
+		return -1
+	}
+	return int64(d / time.Millisecond)
+}
+
+// millisToTime converts a timestamp of a delegation token response.
+func millisToTime(ms int64) time.Time {
+	return time.Unix(ms/1000, (ms%1000)*int64(time.Millisecond))
+}
+
+func timeToMillis(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,34 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	createDelegationTokenRequestNoRenewers = []byte{
+		0, 0, 0, 0, // no renewers
+		255, 255, 255, 255, 255, 255, 255, 255, // max lifetime: broker default
+	}
+
+	createDelegationTokenRequestRenewers = []byte{
+		0, 0, 0, 1, // 1 renewer
+		0, 4, 'U', 's', 'e', 'r', // principal type
+		0, 5, 'a', 'l', 'i', 'c', 'e', // principal name
+		0, 0, 0, 0, 5, 38, 92, 0, // max lifetime: 1 day
+	}
+)
+
+func TestCreateDelegationTokenRequest(t *testing.T) {
+	request := &CreateDelegationTokenRequest{
+		MaxLifetime: -1 * time.Millisecond,
+	}
+	testRequest(t, "no renewers", request, createDelegationTokenRequestNoRenewers)
+
+	request = &CreateDelegationTokenRequest{
+		Version:     1,
+		Renewers:    []KafkaPrincipal{{Type: "User", Name: "alice"}},
+		MaxLifetime: 24 * time.Hour,
+	}
+	testRequest(t, "one renewer", request, createDelegationTokenRequestRenewers)
+}
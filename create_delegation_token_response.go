@@ -0,0 +1,100 @@
+package sarama
+
+import "time"
+
+// DelegationToken is a delegation token (KIP-48). Clients authenticate with
+// it over SASL/SCRAM by setting it in Net.SASL.DelegationToken, for which only
+// its TokenID and HMAC are needed.
+type DelegationToken struct {
+	Owner      KafkaPrincipal
+	IssueTime  time.Time
+	ExpiryTime time.Time
+	MaxTime    time.Time
+	TokenID    string
+	HMAC       []byte
+
+	// Only set by DescribeDelegationToken.
+	Renewers []KafkaPrincipal
+}
+
+type CreateDelegationTokenResponse struct {
+	Version      int16
+	ErrorCode    KError
+	Token        DelegationToken
+	ThrottleTime time.Duration
+}
+
+func (c *CreateDelegationTokenResponse) encode(pe packetEncoder) error {
+	pe.putInt16(int16(c.ErrorCode))
+	if err := c.Token.Owner.encode(pe); err != nil {
+		return err
+	}
+	pe.putInt64(timeToMillis(c.Token.IssueTime))
+	pe.putInt64(timeToMillis(c.Token.ExpiryTime))
+	pe.putInt64(timeToMillis(c.Token.MaxTime))
+	if err := pe.putString(c.Token.TokenID); err != nil {
+		return err
+	}
+	if err := pe.putBytes(c.Token.HMAC); err != nil {
+		return err
+	}
+	pe.putInt32(int32(c.ThrottleTime / time.Millisecond))
+	return nil
+}
+
+func (c *CreateDelegationTokenResponse) decode(pd packetDecoder, version int16) error {
+	c.Version = version
+
+	kerr, err := pd.getInt16()
+	if err != nil {
+		return err
+	}
+	c.ErrorCode = KError(kerr)
+
+	if err := c.Token.Owner.decode(pd); err != nil {
+		return err
+	}
+	issueTime, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	c.Token.IssueTime = millisToTime(issueTime)
+	expiryTime, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	c.Token.ExpiryTime = millisToTime(expiryTime)
+	maxTime, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	c.Token.MaxTime = millisToTime(maxTime)
+	if c.Token.TokenID, err = pd.getString(); err != nil {
+		return err
+	}
+	if c.Token.HMAC, err = pd.getBytes(); err != nil {
+		return err
+	}
+
+	throttleTime, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	c.ThrottleTime = time.Duration(throttleTime) * time.Millisecond
+	return nil
+}
+
+func (c *CreateDelegationTokenResponse) key() int16 {
+	return 38
+}
+
+func (c *CreateDelegationTokenResponse) version() int16 {
+	return c.Version
+}
+
+func (c *CreateDelegationTokenResponse) requiredVersion() KafkaVersion {
+	if c.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
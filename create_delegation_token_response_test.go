@@ -0,0 +1,34 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var createDelegationTokenResponse = []byte{
+	0, 0, // no error
+	0, 4, 'U', 's', 'e', 'r', // owner principal type
+	0, 3, 'b', 'o', 'b', // owner principal name
+	0, 0, 0, 0, 0, 0, 3, 232, // issue time: 1000
+	0, 0, 0, 0, 0, 0, 7, 208, // expiry time: 2000
+	0, 0, 0, 0, 0, 0, 11, 184, // max time: 3000
+	0, 2, 'i', 'd', // token id
+	0, 0, 0, 3, 1, 2, 3, // hmac
+	0, 0, 0, 100, // throttle time
+}
+
+func TestCreateDelegationTokenResponse(t *testing.T) {
+	response := &CreateDelegationTokenResponse{
+		Version: 1,
+		Token: DelegationToken{
+			Owner:      KafkaPrincipal{Type: "User", Name: "bob"},
+			IssueTime:  millisToTime(1000),
+			ExpiryTime: millisToTime(2000),
+			MaxTime:    millisToTime(3000),
+			TokenID:    "id",
+			HMAC:       []byte{1, 2, 3},
+		},
+		ThrottleTime: 100 * time.Millisecond,
+	}
+	testResponse(t, "token", response, createDelegationTokenResponse)
+}
@@ -0,0 +1,59 @@
+package sarama
+
+import (
+	"testing"
+)
+
+var (
+	emptyDeleteOffsetsResponse = []byte{
+		0, 0, // no error
+		0, 0, 0, 0, // 0 throttle
+		0, 0, 0, 0, // 0 topics
+	}
+
+	noErrorDeleteOffsetsResponse = []byte{
+		0, 0, // no error
+		0, 0, 0, 0, // 0 throttle
+		0, 0, 0, 1, // 1 topic
+		0, 3, 'b', 'a', 'r', // topic name: bar
+		0, 0, 0, 1, // 1 partition
+		0, 0, 0, 6, // partition 6
+		0, 0, // no error
+	}
+
+	errorDeleteOffsetsResponse = []byte{
+		0, 16, // error 16 : ErrNotCoordinatorForConsumer
+		0, 0, 0, 0, // 0 throttle
+		0, 0, 0, 0, // 0 topics
+	}
+
+	subscribedDeleteOffsetsResponse = []byte{
+		0, 0, // no error
+		0, 0, 0, 0, // 0 throttle
+		0, 0, 0, 1, // 1 topic
+		0, 3, 'b', 'a', 'r', // topic name: bar
+		0, 0, 0, 1, // 1 partition
+		0, 0, 0, 7, // partition 7
+		0, 86, // ErrGroupSubscribedToTopic
+	}
+)
+
+func TestDeleteOffsetsResponse(t *testing.T) {
+	var response *DeleteOffsetsResponse
+
+	response = &DeleteOffsetsResponse{}
+	testResponse(t, "empty no error", response, emptyDeleteOffsetsResponse)
+
+	response = &DeleteOffsetsResponse{}
+	response.AddError("bar", 6, ErrNoError)
+	testResponse(t, "no error", response, noErrorDeleteOffsetsResponse)
+
+	response = &DeleteOffsetsResponse{
+		ErrorCode: ErrNotCoordinatorForConsumer,
+	}
+	testResponse(t, "error", response, errorDeleteOffsetsResponse)
+
+	response = &DeleteOffsetsResponse{}
+	response.AddError("bar", 7, ErrGroupSubscribedToTopic)
+	testResponse(t, "subscribed", response, subscribedDeleteOffsetsResponse)
+}
@@ -0,0 +1,141 @@
+package sarama
+
+// QuotaEntityType is the type of an entity quotas are applied to (KIP-546).
+type QuotaEntityType string
+
+const (
+	QuotaEntityUser     QuotaEntityType = "user"
+	QuotaEntityClientID QuotaEntityType = "client-id"
+	QuotaEntityIP       QuotaEntityType = "ip"
+)
+
+// The quota keys supported by the brokers, with their value in bytes per second
+// for the byte rates and in percentage of a request handler thread for the
+// request percentage.
+const (
+	QuotaProducerByteRate  = "producer_byte_rate"
+	QuotaConsumerByteRate  = "consumer_byte_rate"
+	QuotaRequestPercentage = "request_percentage"
+)
+
+// QuotaMatchType tells how the name of an entity is matched.
+type QuotaMatchType int
+
+const (
+	// QuotaMatchExact matches the entity of the given name.
+	QuotaMatchExact QuotaMatchType = iota // 0
+	// QuotaMatchDefault matches the default entity, applied when no quota is
+	// set for the entity itself.
+	QuotaMatchDefault // 1
+	// QuotaMatchAny matches any entity of the given type.
+	QuotaMatchAny // 2
+)
+
+// QuotaFilterComponent is a filter on the entities of a type, whose Match is
+// only used by QuotaMatchExact.
+type QuotaFilterComponent struct {
+	EntityType QuotaEntityType
+	MatchType  QuotaMatchType
+	Match      string
+}
+
+// DescribeClientQuotasRequest is a request to get the quotas of the entities
+// matching all the filter components. With Strict, only the entities without
+// any other component than the filtered ones are returned.
+type DescribeClientQuotasRequest struct {
+	Components []QuotaFilterComponent
+	Strict     bool
+}
+
+// NewDescribeClientQuotasRequest returns a request filtering on the given
+// components, or an error if one of them is invalid.
+func NewDescribeClientQuotasRequest(components []QuotaFilterComponent, strict bool) (*DescribeClientQuotasRequest, error) {
+	for _, component := range components {
+		if component.MatchType == QuotaMatchExact && component.Match == "" {
+			return nil, ConfigurationError("an exact quota filter component requires a match on " + string(component.EntityType))
+		}
+	}
+	return &DescribeClientQuotasRequest{
+		Components: components,
+		Strict:     strict,
+	}, nil
+}
+
+func (d *DescribeClientQuotasRequest) encode(pe packetEncoder) error {
+	if err := pe.putArrayLength(len(d.Components)); err != nil {
+		return err
+	}
+	for _, c := range d.Components {
+		if err := c.encode(pe); err != nil {
+			return err
+		}
+	}
+
+	pe.putBool(d.Strict)
+	return nil
+}
+
+func (d *DescribeClientQuotasRequest) decode(pd packetDecoder, version int16) error {
+	componentCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	if componentCount > 0 {
+		d.Components = make([]QuotaFilterComponent, componentCount)
+		for i := range d.Components {
+			if err = d.Components[i].decode(pd, version); err != nil {
+				return err
+			}
+		}
+	}
+
+	d.Strict, err = pd.getBool()
+	return err
+}
+
+func (d *QuotaFilterComponent) encode(pe packetEncoder) error {
+	if err := pe.putString(string(d.EntityType)); err != nil {
+		return err
+	}
+
+	pe.putInt8(int8(d.MatchType))
+	if d.MatchType == QuotaMatchExact {
+		return pe.putString(d.Match)
+	}
+	return pe.putNullableString(nil)
+}
+
+func (d *QuotaFilterComponent) decode(pd packetDecoder, version int16) error {
+	entityType, err := pd.getString()
+	if err != nil {
+		return err
+	}
+	d.EntityType = QuotaEntityType(entityType)
+
+	matchType, err := pd.getInt8()
+	if err != nil {
+		return err
+	}
+	d.MatchType = QuotaMatchType(matchType)
+
+	match, err := pd.getNullableString()
+	if err != nil {
+		return err
+	}
+	if match != nil {
+		d.Match = *match
+	}
+	return nil
+}
+
+func (d *DescribeClientQuotasRequest) key() int16 {
+	return 48
+}
+
+func (d *DescribeClientQuotasRequest) version() int16 {
+	return 0
+}
+
+func (d *DescribeClientQuotasRequest) requiredVersion() KafkaVersion {
+	return V2_6_0_0
+}
@@ -0,0 +1,84 @@
+package sarama
+
+import "testing"
+
+var (
+	describeClientQuotasRequestAll = []byte{
+		0, 0, 0, 0, // components len
+		0, // strict
+	}
+
+	describeClientQuotasRequestDefaultUser = []byte{
+		0, 0, 0, 1, // components len
+		0, 4, 'u', 's', 'e', 'r', // entity type
+		1,        // match type (default)
+		255, 255, // match *string
+		0, // strict
+	}
+
+	describeClientQuotasRequestOnlySpecificUser = []byte{
+		0, 0, 0, 1, // components len
+		0, 4, 'u', 's', 'e', 'r', // entity type
+		0,                             // match type (exact)
+		0, 5, 'a', 'l', 'i', 'c', 'e', // match *string
+		1, // strict
+	}
+
+	describeClientQuotasRequestMultiComponents = []byte{
+		0, 0, 0, 2, // components len
+		0, 4, 'u', 's', 'e', 'r', // entity type
+		2,        // match type (any)
+		255, 255, // match *string
+		0, 9, 'c', 'l', 'i', 'e', 'n', 't', '-', 'i', 'd', // entity type
+		0,                             // match type (exact)
+		0, 5, 'a', 'l', 'i', 'c', 'e', // match *string
+		0, // strict
+	}
+)
+
+func TestDescribeClientQuotasRequest(t *testing.T) {
+	// Match All
+	req := &DescribeClientQuotasRequest{}
+	testRequest(t, "Match All", req, describeClientQuotasRequestAll)
+
+	// Match Default User
+	defaultUser := QuotaFilterComponent{
+		EntityType: QuotaEntityUser,
+		MatchType:  QuotaMatchDefault,
+	}
+	req = &DescribeClientQuotasRequest{
+		Components: []QuotaFilterComponent{defaultUser},
+	}
+	testRequest(t, "Default User", req, describeClientQuotasRequestDefaultUser)
+
+	// Match Only Specific User
+	specificUser := QuotaFilterComponent{
+		EntityType: QuotaEntityUser,
+		MatchType:  QuotaMatchExact,
+		Match:      "alice",
+	}
+	req = &DescribeClientQuotasRequest{
+		Components: []QuotaFilterComponent{specificUser},
+		Strict:     true,
+	}
+	testRequest(t, "Only Specific User", req, describeClientQuotasRequestOnlySpecificUser)
+
+	// Match Any User and Specific Client ID
+	anyUser := QuotaFilterComponent{
+		EntityType: QuotaEntityUser,
+		MatchType:  QuotaMatchAny,
+	}
+	specificClientID := QuotaFilterComponent{
+		EntityType: QuotaEntityClientID,
+		MatchType:  QuotaMatchExact,
+		Match:      "alice",
+	}
+	req = &DescribeClientQuotasRequest{
+		Components: []QuotaFilterComponent{anyUser, specificClientID},
+	}
+	testRequest(t, "Multi Components", req, describeClientQuotasRequestMultiComponents)
+
+	if _, err := NewDescribeClientQuotasRequest([]QuotaFilterComponent{{EntityType: QuotaEntityIP}}, false); err == nil {
+		t.Error("Expected an error for an exact filter component without match")
+	}
+}
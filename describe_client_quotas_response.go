@@ -0,0 +1,179 @@
+package sarama
+
+import "time"
+
+type DescribeClientQuotasResponse struct {
+	ThrottleTime time.Duration
+	ErrorCode    KError
+	ErrorMsg     *string
+	Entries      []DescribeClientQuotasEntry
+}
+
+// DescribeClientQuotasEntry holds the quotas of an entity, by quota key.
+type DescribeClientQuotasEntry struct {
+	Entity []QuotaEntityComponent
+	Values map[string]float64
+}
+
+// QuotaEntityComponent is a part of an entity, which is either named or the
+// default entity of its type, for instance the user "alice" or the default
+// client-id. Quotas set on an entity made of a user and a client-id apply to
+// the clients with this ID authenticated as this user.
+type QuotaEntityComponent struct {
+	EntityType QuotaEntityType
+	MatchType  QuotaMatchType
+	Name       string
+}
+
+func (d *DescribeClientQuotasResponse) encode(pe packetEncoder) error {
+	pe.putInt32(int32(d.ThrottleTime / time.Millisecond))
+	pe.putInt16(int16(d.ErrorCode))
+	if err := pe.putNullableString(d.ErrorMsg); err != nil {
+		return err
+	}
+
+	if d.Entries == nil {
+		return pe.putArrayLength(-1)
+	}
+	if err := pe.putArrayLength(len(d.Entries)); err != nil {
+		return err
+	}
+	for _, e := range d.Entries {
+		if err := e.encode(pe); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DescribeClientQuotasResponse) decode(pd packetDecoder, version int16) error {
+	throttleTime, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	d.ThrottleTime = time.Duration(throttleTime) * time.Millisecond
+
+	errorCode, err := pd.getInt16()
+	if err != nil {
+		return err
+	}
+	d.ErrorCode = KError(errorCode)
+
+	if d.ErrorMsg, err = pd.getNullableString(); err != nil {
+		return err
+	}
+
+	entryCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	if entryCount >= 0 {
+		d.Entries = make([]DescribeClientQuotasEntry, entryCount)
+		for i := range d.Entries {
+			if err = d.Entries[i].decode(pd, version); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *DescribeClientQuotasEntry) encode(pe packetEncoder) error {
+	if err := encodeQuotaEntity(pe, d.Entity); err != nil {
+		return err
+	}
+
+	if err := pe.putArrayLength(len(d.Values)); err != nil {
+		return err
+	}
+	for key, value := range d.Values {
+		if err := pe.putString(key); err != nil {
+			return err
+		}
+		pe.putFloat64(value)
+	}
+	return nil
+}
+
+func (d *DescribeClientQuotasEntry) decode(pd packetDecoder, version int16) (err error) {
+	if d.Entity, err = decodeQuotaEntity(pd, version); err != nil {
+		return err
+	}
+
+	valueCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	d.Values = make(map[string]float64, valueCount)
+	for i := 0; i < valueCount; i++ {
+		key, err := pd.getString()
+		if err != nil {
+			return err
+		}
+		if d.Values[key], err = pd.getFloat64(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encodeQuotaEntity writes the components of an entity, whose default
+// components have a null name.
+func encodeQuotaEntity(pe packetEncoder, entity []QuotaEntityComponent) error {
+	if err := pe.putArrayLength(len(entity)); err != nil {
+		return err
+	}
+	for _, c := range entity {
+		if err := pe.putString(string(c.EntityType)); err != nil {
+			return err
+		}
+		if c.MatchType == QuotaMatchDefault {
+			if err := pe.putNullableString(nil); err != nil {
+				return err
+			}
+		} else if err := pe.putString(c.Name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeQuotaEntity(pd packetDecoder, version int16) ([]QuotaEntityComponent, error) {
+	componentCount, err := pd.getArrayLength()
+	if err != nil {
+		return nil, err
+	}
+
+	entity := make([]QuotaEntityComponent, componentCount)
+	for i := range entity {
+		entityType, err := pd.getString()
+		if err != nil {
+			return nil, err
+		}
+		entity[i].EntityType = QuotaEntityType(entityType)
+
+		name, err := pd.getNullableString()
+		if err != nil {
+			return nil, err
+		}
+		if name == nil {
+			entity[i].MatchType = QuotaMatchDefault
+		} else {
+			entity[i].MatchType = QuotaMatchExact
+			entity[i].Name = *name
+		}
+	}
+	return entity, nil
+}
+
+func (d *DescribeClientQuotasResponse) key() int16 {
+	return 48
+}
+
+func (d *DescribeClientQuotasResponse) version() int16 {
+	return 0
+}
+
+func (d *DescribeClientQuotasResponse) requiredVersion() KafkaVersion {
+	return V2_6_0_0
+}
@@ -0,0 +1,83 @@
+package sarama
+
+import "testing"
+
+var (
+	describeClientQuotasResponseError = []byte{
+		0, 0, 0, 0, // ThrottleTime
+		0, 35, // ErrorCode
+		0, 41, 'C', 'u', 's', 't', 'o', 'm', ' ', 'e', 'n', 't', 'i', 't', 'y', ' ', 't', 'y', 'p', 'e', ' ', '\'', 'f', 'a', 'u', 'l', 't', 'y', '\'', ' ', 'n', 'o', 't', ' ', 's', 'u', 'p', 'p', 'o', 'r', 't', 'e', 'd',
+		255, 255, 255, 255, // Entries (null)
+	}
+
+	describeClientQuotasResponseSingleValue = []byte{
+		0, 0, 0, 0, // ThrottleTime
+		0, 0, // ErrorCode
+		255, 255, // ErrorMsg (nullable)
+		0, 0, 0, 1, // Entries
+		0, 0, 0, 1, // Entity
+		0, 4, 'u', 's', 'e', 'r', // Entity type
+		255, 255, // Entity name (nullable)
+		0, 0, 0, 1, // Values
+		0, 18, 'p', 'r', 'o', 'd', 'u', 'c', 'e', 'r', '_', 'b', 'y', 't', 'e', '_', 'r', 'a', 't', 'e',
+		65, 46, 132, 128, 0, 0, 0, 0, // 1000000
+	}
+
+	describeClientQuotasResponseComplexEntity = []byte{
+		0, 0, 0, 0, // ThrottleTime
+		0, 0, // ErrorCode
+		255, 255, // ErrorMsg (nullable)
+		0, 0, 0, 1, // Entries
+		0, 0, 0, 2, // Entity
+		0, 4, 'u', 's', 'e', 'r', // Entity type
+		0, 5, 'a', 'l', 'i', 'c', 'e', // Entity name
+		0, 9, 'c', 'l', 'i', 'e', 'n', 't', '-', 'i', 'd', // Entity type
+		255, 255, // Entity name (nullable)
+		0, 0, 0, 1, // Values
+		0, 18, 'r', 'e', 'q', 'u', 'e', 's', 't', '_', 'p', 'e', 'r', 'c', 'e', 'n', 't', 'a', 'g', 'e',
+		64, 89, 0, 0, 0, 0, 0, 0, // 100
+	}
+)
+
+func TestDescribeClientQuotasResponse(t *testing.T) {
+	// Response With Error
+	errMsg := "Custom entity type 'faulty' not supported"
+	res := &DescribeClientQuotasResponse{
+		ErrorCode: ErrUnsupportedVersion,
+		ErrorMsg:  &errMsg,
+	}
+	testResponse(t, "Response With Error", res, describeClientQuotasResponseError)
+
+	// Single Quota entry
+	defaultUserComponent := QuotaEntityComponent{
+		EntityType: QuotaEntityUser,
+		MatchType:  QuotaMatchDefault,
+	}
+	entry := DescribeClientQuotasEntry{
+		Entity: []QuotaEntityComponent{defaultUserComponent},
+		Values: map[string]float64{QuotaProducerByteRate: 1000000},
+	}
+	res = &DescribeClientQuotasResponse{
+		Entries: []DescribeClientQuotasEntry{entry},
+	}
+	testResponse(t, "Single Value", res, describeClientQuotasResponseSingleValue)
+
+	// Complex Quota entry
+	userComponent := QuotaEntityComponent{
+		EntityType: QuotaEntityUser,
+		MatchType:  QuotaMatchExact,
+		Name:       "alice",
+	}
+	defaultClientIDComponent := QuotaEntityComponent{
+		EntityType: QuotaEntityClientID,
+		MatchType:  QuotaMatchDefault,
+	}
+	entry = DescribeClientQuotasEntry{
+		Entity: []QuotaEntityComponent{userComponent, defaultClientIDComponent},
+		Values: map[string]float64{QuotaRequestPercentage: 100},
+	}
+	res = &DescribeClientQuotasResponse{
+		Entries: []DescribeClientQuotasEntry{entry},
+	}
+	testResponse(t, "Complex Entity", res, describeClientQuotasResponseComplexEntity)
+}
@@ -0,0 +1,47 @@
+package sarama
+
+// DescribeDelegationTokenRequest is a request to get the delegation tokens
+// owned by some principals, or all the tokens the authenticated principal may
+// describe when Owners is nil.
+type DescribeDelegationTokenRequest struct {
+	Version int16
+	Owners  []KafkaPrincipal
+}
+
+func (d *DescribeDelegationTokenRequest) encode(pe packetEncoder) error {
+	if d.Owners == nil {
+		return pe.putArrayLength(-1)
+	}
+	return encodePrincipals(pe, d.Owners)
+}
+
+func (d *DescribeDelegationTokenRequest) decode(pd packetDecoder, version int16) (err error) {
+	d.Version = version
+	n, err := pd.getArrayLength()
+	if err != nil || n < 0 {
+		return err
+	}
+
+	d.Owners = make([]KafkaPrincipal, n)
+	for i := range d.Owners {
+		if err := d.Owners[i].decode(pd); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *DescribeDelegationTokenRequest) key() int16 {
+	return 41
+}
+
+func (d *DescribeDelegationTokenRequest) version() int16 {
+	return d.Version
+}
+
+func (d *DescribeDelegationTokenRequest) requiredVersion() KafkaVersion {
+	if d.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
@@ -0,0 +1,108 @@
+package sarama
+
+import "time"
+
+type DescribeDelegationTokenResponse struct {
+	Version      int16
+	ErrorCode    KError
+	Tokens       []DelegationToken
+	ThrottleTime time.Duration
+}
+
+func (d *DescribeDelegationTokenResponse) encode(pe packetEncoder) error {
+	pe.putInt16(int16(d.ErrorCode))
+
+	if err := pe.putArrayLength(len(d.Tokens)); err != nil {
+		return err
+	}
+	for _, token := range d.Tokens {
+		if err := token.Owner.encode(pe); err != nil {
+			return err
+		}
+		pe.putInt64(timeToMillis(token.IssueTime))
+		pe.putInt64(timeToMillis(token.ExpiryTime))
+		pe.putInt64(timeToMillis(token.MaxTime))
+		if err := pe.putString(token.TokenID); err != nil {
+			return err
+		}
+		if err := pe.putBytes(token.HMAC); err != nil {
+			return err
+		}
+		if err := encodePrincipals(pe, token.Renewers); err != nil {
+			return err
+		}
+	}
+
+	pe.putInt32(int32(d.ThrottleTime / time.Millisecond))
+	return nil
+}
+
+func (d *DescribeDelegationTokenResponse) decode(pd packetDecoder, version int16) error {
+	d.Version = version
+
+	kerr, err := pd.getInt16()
+	if err != nil {
+		return err
+	}
+	d.ErrorCode = KError(kerr)
+
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	if n > 0 {
+		d.Tokens = make([]DelegationToken, n)
+		for i := range d.Tokens {
+			token := &d.Tokens[i]
+			if err := token.Owner.decode(pd); err != nil {
+				return err
+			}
+			issueTime, err := pd.getInt64()
+			if err != nil {
+				return err
+			}
+			token.IssueTime = millisToTime(issueTime)
+			expiryTime, err := pd.getInt64()
+			if err != nil {
+				return err
+			}
+			token.ExpiryTime = millisToTime(expiryTime)
+			maxTime, err := pd.getInt64()
+			if err != nil {
+				return err
+			}
+			token.MaxTime = millisToTime(maxTime)
+			if token.TokenID, err = pd.getString(); err != nil {
+				return err
+			}
+			if token.HMAC, err = pd.getBytes(); err != nil {
+				return err
+			}
+			if token.Renewers, err = decodePrincipals(pd); err != nil {
+				return err
+			}
+		}
+	}
+
+	throttleTime, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	d.ThrottleTime = time.Duration(throttleTime) * time.Millisecond
+	return nil
+}
+
+func (d *DescribeDelegationTokenResponse) key() int16 {
+	return 41
+}
+
+func (d *DescribeDelegationTokenResponse) version() int16 {
+	return d.Version
+}
+
+func (d *DescribeDelegationTokenResponse) requiredVersion() KafkaVersion {
+	if d.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
@@ -0,0 +1,48 @@
+package sarama
+
+import "testing"
+
+var (
+	describeDelegationTokenResponseEmpty = []byte{
+		0, 65, // ErrDelegationTokenAuthorizationFailed
+		0, 0, 0, 0, // no tokens
+		0, 0, 0, 0, // throttle time
+	}
+
+	describeDelegationTokenResponse = []byte{
+		0, 0, // no error
+		0, 0, 0, 1, // 1 token
+		0, 4, 'U', 's', 'e', 'r', // owner principal type
+		0, 3, 'b', 'o', 'b', // owner principal name
+		0, 0, 0, 0, 0, 0, 3, 232, // issue time: 1000
+		0, 0, 0, 0, 0, 0, 7, 208, // expiry time: 2000
+		0, 0, 0, 0, 0, 0, 11, 184, // max time: 3000
+		0, 2, 'i', 'd', // token id
+		0, 0, 0, 3, 1, 2, 3, // hmac
+		0, 0, 0, 1, // 1 renewer
+		0, 4, 'U', 's', 'e', 'r', // principal type
+		0, 5, 'a', 'l', 'i', 'c', 'e', // principal name
+		0, 0, 0, 0, // throttle time
+	}
+)
+
+func TestDescribeDelegationTokenResponse(t *testing.T) {
+	response := &DescribeDelegationTokenResponse{
+		ErrorCode: ErrDelegationTokenAuthorizationFailed,
+	}
+	testResponse(t, "empty", response, describeDelegationTokenResponseEmpty)
+
+	response = &DescribeDelegationTokenResponse{
+		Version: 1,
+		Tokens: []DelegationToken{{
+			Owner:      KafkaPrincipal{Type: "User", Name: "bob"},
+			IssueTime:  millisToTime(1000),
+			ExpiryTime: millisToTime(2000),
+			MaxTime:    millisToTime(3000),
+			TokenID:    "id",
+			HMAC:       []byte{1, 2, 3},
+			Renewers:   []KafkaPrincipal{{Type: "User", Name: "alice"}},
+		}},
+	}
+	testResponse(t, "one token", response, describeDelegationTokenResponse)
+}
@@ -0,0 +1,59 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	describeLogDirsResponseEmpty = []byte{
+		0, 0, 0, 0, // no throttle time
+		0, 0, 0, 0, // no log dirs
+	}
+
+	describeLogDirsResponseTwoPartitions = []byte{
+		0, 0, 0, 100, // throttle time 100 ms
+		0, 0, 0, 1, // One describe log dir (array length)
+		0, 0, // No error code
+		0, 6, // Character length of path (6 chars)
+		'/', 'k', 'a', 'f', 'k', 'a',
+		0, 0, 0, 1, // One DescribeLogDirsResponseTopic (array length)
+		0, 6, // Character length of "random" topic (6 chars)
+		'r', 'a', 'n', 'd', 'o', 'm', // Topic name
+		0, 0, 0, 2, // Two DescribeLogDirsResponsePartition (array length)
+		0, 0, 0, 25, // PartitionID 25
+		0, 0, 0, 0, 0, 0, 0, 125, // Log Size
+		0, 0, 0, 0, 0, 0, 0, 0, // OffsetLag
+		0,           // IsTemporary = false
+		0, 0, 0, 26, // PartitionID 25
+		0, 0, 0, 0, 0, 0, 0, 100, // Log Size
+		0, 0, 0, 0, 0, 0, 0, 5, // OffsetLag
+		1, // IsTemporary = true
+	}
+)
+
+func TestDescribeLogDirsResponse(t *testing.T) {
+	response := &DescribeLogDirsResponse{
+		LogDirs: []DescribeLogDirsResponseDirMetadata{},
+	}
+	testResponse(t, "empty", response, describeLogDirsResponseEmpty)
+
+	response = &DescribeLogDirsResponse{
+		ThrottleTime: 100 * time.Millisecond,
+		LogDirs: []DescribeLogDirsResponseDirMetadata{
+			{
+				Path: "/kafka",
+				Topics: []DescribeLogDirsResponseTopic{
+					{
+						Topic: "random",
+						Partitions: []DescribeLogDirsResponsePartition{
+							{PartitionID: 25, Size: 125},
+							{PartitionID: 26, Size: 100, OffsetLag: 5, IsTemporary: true},
+						},
+					},
+				},
+			},
+		},
+	}
+	testResponse(t, "two partitions", response, describeLogDirsResponseTwoPartitions)
+}
@@ -0,0 +1,71 @@
+package sarama
+
+// DescribeUserScramCredentialsRequest is a request to get the SCRAM
+// credentials of some users, or of all users when DescribeUsers is nil (KIP-554).
+type DescribeUserScramCredentialsRequest struct {
+	// Version 0 is currently only supported
+	Version int16
+
+	DescribeUsers []DescribeUserScramCredentialsRequestUser
+}
+
+type DescribeUserScramCredentialsRequestUser struct {
+	Name string
+}
+
+func (r *DescribeUserScramCredentialsRequest) encode(pe packetEncoder) error {
+	if r.DescribeUsers == nil {
+		pe.putCompactArrayLength(-1)
+	} else {
+		pe.putCompactArrayLength(len(r.DescribeUsers))
+	}
+
+	for _, d := range r.DescribeUsers {
+		if err := pe.putCompactString(d.Name); err != nil {
+			return err
+		}
+		pe.putEmptyTaggedFieldArray()
+	}
+
+	pe.putEmptyTaggedFieldArray()
+	return nil
+}
+
+func (r *DescribeUserScramCredentialsRequest) decode(pd packetDecoder, version int16) error {
+	r.Version = version
+
+	n, err := pd.getCompactArrayLength()
+	if err != nil {
+		return err
+	}
+	if n >= 0 {
+		r.DescribeUsers = make([]DescribeUserScramCredentialsRequestUser, n)
+		for i := 0; i < n; i++ {
+			if r.DescribeUsers[i].Name, err = pd.getCompactString(); err != nil {
+				return err
+			}
+			if _, err = pd.getEmptyTaggedFieldArray(); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = pd.getEmptyTaggedFieldArray()
+	return err
+}
+
+func (r *DescribeUserScramCredentialsRequest) key() int16 {
+	return 50
+}
+
+func (r *DescribeUserScramCredentialsRequest) version() int16 {
+	return r.Version
+}
+
+func (r *DescribeUserScramCredentialsRequest) headerVersion() int16 {
+	return 2
+}
+
+func (r *DescribeUserScramCredentialsRequest) requiredVersion() KafkaVersion {
+	return V2_7_0_0
+}
@@ -0,0 +1,32 @@
+package sarama
+
+import "testing"
+
+var (
+	emptyDescribeUserScramCredentialsRequest = []byte{
+		0, // null array: all users
+		0, // empty tagged fields
+	}
+
+	userDescribeUserScramCredentialsRequest = []byte{
+		2,                // 1 user
+		4, 'f', 'o', 'o', // user name: foo
+		0, // empty tagged fields
+		0, // empty tagged fields
+	}
+)
+
+func TestDescribeUserScramCredentialsRequest(t *testing.T) {
+	request := &DescribeUserScramCredentialsRequest{
+		Version: 0,
+	}
+	testRequest(t, "all users", request, emptyDescribeUserScramCredentialsRequest)
+
+	request = &DescribeUserScramCredentialsRequest{
+		Version: 0,
+		DescribeUsers: []DescribeUserScramCredentialsRequestUser{
+			{Name: "foo"},
+		},
+	}
+	testRequest(t, "one user", request, userDescribeUserScramCredentialsRequest)
+}
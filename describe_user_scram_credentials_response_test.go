@@ -0,0 +1,39 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var describeUserScramCredentialsResponse = []byte{
+	0, 0, 0, 100, // throttle time: 100ms
+	0, 0, // no error
+	0,                // null error message
+	2,                // 1 result
+	4, 'f', 'o', 'o', // user name: foo
+	0, 0, // no error
+	0,           // null error message
+	2,           // 1 credential
+	1,           // SCRAM-SHA-256
+	0, 0, 16, 0, // 4096 iterations
+	0, // empty tagged fields
+	0, // empty tagged fields
+	0, // empty tagged fields
+}
+
+func TestDescribeUserScramCredentialsResponse(t *testing.T) {
+	response := &DescribeUserScramCredentialsResponse{
+		Version:      0,
+		ThrottleTime: 100 * time.Millisecond,
+		Results: []*DescribeUserScramCredentialsResult{
+			{
+				User: "foo",
+				CredentialInfos: []*UserScramCredentialsResponseInfo{
+					{Mechanism: ScramMechanismSHA256, Iterations: 4096},
+				},
+			},
+		},
+	}
+
+	testResponse(t, "one user", response, describeUserScramCredentialsResponse)
+}
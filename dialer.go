@@ -0,0 +1,245 @@
+package sarama
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// ContextDialer dials the connections to the brokers. Dialers can be layered,
+// each one opening its connection through the one it forwards to, so that for
+// instance TLS can be used over a proxy.
+type ContextDialer interface {
+	// DialContext connects to the address on the named network, the context
+	// bounding the time it takes to connect.
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// NewTLSDialer returns a dialer wrapping in TLS the connections opened by
+// forward, or directly when forward is nil. When the configuration sets no
+// ServerName, the host the connection is opened to is used.
+func NewTLSDialer(config *tls.Config, forward ContextDialer) ContextDialer {
+	return &tlsDialer{config: config, forward: orDirect(forward)}
+}
+
+type tlsDialer struct {
+	config  *tls.Config
+	forward ContextDialer
+}
+
+func (d *tlsDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	config := d.config
+	if config == nil {
+		config = &tls.Config{}
+	}
+	if config.ServerName == "" {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			_ = conn.Close()
+			return nil, err
+		}
+		config = config.Clone()
+		config.ServerName = host
+	}
+
+	tlsConn := tls.Client(conn, config)
+	if err := handshake(ctx, conn, tlsConn); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// handshake runs the TLS handshake of tlsConn over conn, closing conn to
+// interrupt it once the context is done
+func handshake(ctx context.Context, conn net.Conn, tlsConn *tls.Conn) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	stop := make(chan none)
+	stopped := make(chan none)
+	go withRecover(func() {
+		defer close(stopped)
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-stop:
+		}
+	})
+
+	err := tlsConn.Handshake()
+	close(stop)
+	<-stopped
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// the connection may have been closed, even past the handshake
+		return ctxErr
+	}
+	return err
+}
+
+// NewHTTPConnectDialer returns a dialer opening the connections through the
+// HTTP proxy at proxyAddr with the CONNECT method, authenticating with basic
+// authentication when auth is set. The connection to the proxy is opened by
+// forward, or directly when forward is nil; use NewTLSDialer as forward for
+// proxies only accepting HTTPS.
+func NewHTTPConnectDialer(proxyAddr string, auth *proxy.Auth, forward ContextDialer) ContextDialer {
+	return &httpConnectDialer{proxyAddr: proxyAddr, auth: auth, forward: orDirect(forward)}
+}
+
+type httpConnectDialer struct {
+	proxyAddr string
+	auth      *proxy.Auth
+	forward   ContextDialer
+}
+
+func (d *httpConnectDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, "tcp", d.proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.connect(ctx, conn, addr); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (d *httpConnectDialer) connect(ctx context.Context, conn net.Conn, addr string) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return err
+		}
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: addr},
+		Host:   addr,
+		Header: make(http.Header),
+	}
+	if d.auth != nil {
+		credentials := base64.StdEncoding.EncodeToString([]byte(d.auth.User + ":" + d.auth.Password))
+		req.Header.Set("Proxy-Authorization", "Basic "+credentials)
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	res, err := http.ReadResponse(reader, req)
+	if err != nil {
+		return err
+	}
+	_ = res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("proxy %s refused to connect to %s: %s", d.proxyAddr, addr, res.Status)
+	}
+	// the client speaks first with Kafka, so nothing may have been read past
+	// the response of the proxy
+	if reader.Buffered() > 0 {
+		return errors.New("proxy sent unexpected data after the CONNECT response")
+	}
+	return nil
+}
+
+// NewSOCKS5Dialer returns a dialer opening the connections through the SOCKS5
+// proxy at proxyAddr, authenticating with a username and a password when auth
+// is set. The connection to the proxy is opened by forward, or directly when
+// forward is nil.
+func NewSOCKS5Dialer(proxyAddr string, auth *proxy.Auth, forward ContextDialer) (ContextDialer, error) {
+	dialer, err := proxy.SOCKS5("tcp", proxyAddr, auth, nil)
+	if err != nil {
+		return nil, err
+	}
+	socks, ok := dialer.(socksConnector)
+	if !ok {
+		return nil, errors.New("the SOCKS5 dialer cannot use an open connection")
+	}
+	return &socks5Dialer{proxyAddr: proxyAddr, socks: socks, forward: orDirect(forward)}, nil
+}
+
+// socksConnector asks the SOCKS server a connection is open to connect to the
+// target address
+type socksConnector interface {
+	DialWithConn(ctx context.Context, conn net.Conn, network, addr string) (net.Addr, error)
+}
+
+type socks5Dialer struct {
+	proxyAddr string
+	socks     socksConnector
+	forward   ContextDialer
+}
+
+func (d *socks5Dialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	conn, err := d.forward.DialContext(ctx, "tcp", d.proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := d.socks.DialWithConn(ctx, conn, network, addr); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// proxyDialer adapts the dialers of the proxy package which are not aware of
+// the context, like the one of Net.Proxy
+type proxyDialer struct {
+	dialer proxy.Dialer
+}
+
+func (d proxyDialer) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if dialer, ok := d.dialer.(ContextDialer); ok {
+		return dialer.DialContext(ctx, network, addr)
+	}
+	return d.dialer.Dial(network, addr)
+}
+
+func orDirect(dialer ContextDialer) ContextDialer {
+	if dialer == nil {
+		return &net.Dialer{}
+	}
+	return dialer
+}
+
+// newBrokerDialer returns the dialer of the connections to the brokers, TLS
+// being layered over the one configured by Net.Dialer or Net.Proxy if any
+func newBrokerDialer(conf *Config, tlsConfig *tls.Config) ContextDialer {
+	var dialer ContextDialer
+	switch {
+	case conf.Net.Dialer != nil:
+		dialer = conf.Net.Dialer
+	case conf.Net.Proxy.Enable:
+		dialer = proxyDialer{conf.Net.Proxy.Dialer}
+	default:
+		dialer = &net.Dialer{
+			Timeout:   conf.Net.DialTimeout,
+			KeepAlive: conf.Net.KeepAlive,
+			LocalAddr: conf.Net.LocalAddr,
+		}
+	}
+
+	if conf.Net.TLS.Enable {
+		dialer = NewTLSDialer(tlsConfig, dialer)
+	}
+	return dialer
+}
@@ -0,0 +1,227 @@
+package sarama
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// testProxy is a proxy relaying the connections it accepts once handshake
+// connected them to their target, counting them
+type testProxy struct {
+	t         *testing.T
+	listener  net.Listener
+	handshake func(conn net.Conn, reader *bufio.Reader) (string, error)
+	relayed   int32
+}
+
+func newTestProxy(t *testing.T, handshake func(conn net.Conn, reader *bufio.Reader) (string, error)) *testProxy {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &testProxy{t: t, listener: listener, handshake: handshake}
+	go p.serve()
+	return p
+}
+
+func (p *testProxy) Addr() string {
+	return p.listener.Addr().String()
+}
+
+func (p *testProxy) Close() error {
+	return p.listener.Close()
+}
+
+func (p *testProxy) serve() {
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			reader := bufio.NewReader(conn)
+			target, err := p.handshake(conn, reader)
+			if err != nil {
+				return
+			}
+			upstream, err := net.Dial("tcp", target)
+			if err != nil {
+				return
+			}
+			defer upstream.Close()
+			atomic.AddInt32(&p.relayed, 1)
+			go func() { _, _ = io.Copy(upstream, reader) }()
+			_, _ = io.Copy(conn, upstream)
+		}()
+	}
+}
+
+func httpConnectHandshake(conn net.Conn, reader *bufio.Reader) (string, error) {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		return "", err
+	}
+	if req.Method != http.MethodConnect || req.Header.Get("Proxy-Authorization") != "Basic dXNlcjpzZWNyZXQ=" {
+		_, _ = conn.Write([]byte("HTTP/1.1 407 Proxy Authentication Required\r\n\r\n"))
+		return "", io.EOF
+	}
+	_, err = conn.Write([]byte("HTTP/1.1 200 Connection established\r\n\r\n"))
+	return req.Host, err
+}
+
+// socks5Handshake accepts the connect command without authentication
+func socks5Handshake(conn net.Conn, reader *bufio.Reader) (string, error) {
+	greeting := make([]byte, 2)
+	if _, err := io.ReadFull(reader, greeting); err != nil {
+		return "", err
+	}
+	if _, err := io.ReadFull(reader, make([]byte, greeting[1])); err != nil {
+		return "", err
+	}
+	if _, err := conn.Write([]byte{5, 0}); err != nil {
+		return "", err
+	}
+
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return "", err
+	}
+	var host string
+	switch header[3] {
+	case 1:
+		ip := make([]byte, 4)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return "", err
+		}
+		host = net.IP(ip).String()
+	case 3:
+		length, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		name := make([]byte, length)
+		if _, err := io.ReadFull(reader, name); err != nil {
+			return "", err
+		}
+		host = string(name)
+	default:
+		return "", io.EOF
+	}
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(reader, port); err != nil {
+		return "", err
+	}
+
+	_, err := conn.Write([]byte{5, 0, 0, 1, 0, 0, 0, 0, 0, 0})
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), err
+}
+
+func newTLSMockBroker(t *testing.T) (*MockBroker, *tls.Config) {
+	ca := newTestCertificateAuthority(t)
+	hostKey, hostCert := ca.issue(t, "host", 2, time.Now().Add(time.Hour))
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{hostCert}, PrivateKey: hostKey}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockBroker := NewMockBrokerListener(t, 1, listener)
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	return mockBroker, &tls.Config{RootCAs: pool}
+}
+
+func TestTLSOverProxyDialers(t *testing.T) {
+	httpProxy := newTestProxy(t, httpConnectHandshake)
+	defer httpProxy.Close()
+	socksProxy := newTestProxy(t, socks5Handshake)
+	defer socksProxy.Close()
+
+	socksDialer, err := NewSOCKS5Dialer(socksProxy.Addr(), nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		proxy  *testProxy
+		dialer ContextDialer
+	}{
+		{"HTTP CONNECT", httpProxy, NewHTTPConnectDialer(httpProxy.Addr(), &proxy.Auth{User: "user", Password: "secret"}, nil)},
+		{"SOCKS5", socksProxy, socksDialer},
+	} {
+		mockBroker, tlsConfig := newTLSMockBroker(t)
+
+		conf := NewConfig()
+		conf.Net.TLS.Enable = true
+		conf.Net.TLS.Config = tlsConfig
+		conf.Net.Dialer = tc.dialer
+
+		broker := NewBroker(mockBroker.Addr())
+		if err := broker.Open(conf); err != nil {
+			t.Fatal(tc.name, err)
+		}
+		if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+			t.Error(tc.name, err)
+		}
+		if relayed := atomic.LoadInt32(&tc.proxy.relayed); relayed != 1 {
+			t.Errorf("%s: expected the connection to be relayed by the proxy, got %d", tc.name, relayed)
+		}
+		safeClose(t, broker)
+		mockBroker.Close()
+	}
+}
+
+func TestHTTPConnectDialerRefused(t *testing.T) {
+	httpProxy := newTestProxy(t, httpConnectHandshake)
+	defer httpProxy.Close()
+
+	dialer := NewHTTPConnectDialer(httpProxy.Addr(), &proxy.Auth{User: "user", Password: "wrong"}, nil)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := dialer.DialContext(ctx, "tcp", "127.0.0.1:9092"); err == nil {
+		t.Error("expected the proxy to refuse the connection")
+	}
+}
+
+func TestTLSDialerHandshakeCanceled(t *testing.T) {
+	// a server accepting connections but never answering the handshake
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	dialer := NewTLSDialer(&tls.Config{ServerName: "kafka"}, nil)
+	if _, err := dialer.DialContext(ctx, "tcp", listener.Addr().String()); err != context.Canceled {
+		t.Error("expected the handshake to be canceled, got", err)
+	}
+}
@@ -0,0 +1,121 @@
+package sarama
+
+import (
+	"sort"
+	"time"
+)
+
+// ElectionType is the type of leader election to perform on a partition.
+type ElectionType int8
+
+const (
+	// PreferredElection elects the preferred replica, the first replica of the
+	// replica assignment, as the leader of the partition.
+	PreferredElection ElectionType = 0
+	// UncleanElection elects a live replica as the leader of a partition that
+	// has no leader, even if it is not in the ISR. This can lose data.
+	UncleanElection ElectionType = 1
+)
+
+// request message format is:
+// [election_type(int8)] [topic] timeout(int32)
+// where topic is:
+//  name(string) [partition_id(int32)]
+// election_type is only present from version 1
+
+type ElectLeadersRequest struct {
+	Version int16
+	Type    ElectionType
+	// TopicPartitions lists the partitions to elect leaders for, or nil for
+	// all the partitions of the cluster.
+	TopicPartitions map[string][]int32
+	Timeout         time.Duration
+}
+
+func (r *ElectLeadersRequest) encode(pe packetEncoder) error {
+	if r.Version >= 1 {
+		pe.putInt8(int8(r.Type))
+	}
+
+	if r.TopicPartitions == nil {
+		pe.putInt32(-1)
+	} else {
+		if err := pe.putArrayLength(len(r.TopicPartitions)); err != nil {
+			return err
+		}
+
+		topics := make([]string, 0, len(r.TopicPartitions))
+		for topic := range r.TopicPartitions {
+			topics = append(topics, topic)
+		}
+		sort.Strings(topics)
+
+		for _, topic := range topics {
+			if err := pe.putString(topic); err != nil {
+				return err
+			}
+			if err := pe.putInt32Array(r.TopicPartitions[topic]); err != nil {
+				return err
+			}
+		}
+	}
+
+	pe.putInt32(int32(r.Timeout / time.Millisecond))
+
+	return nil
+}
+
+func (r *ElectLeadersRequest) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+
+	if version >= 1 {
+		electionType, err := pd.getInt8()
+		if err != nil {
+			return err
+		}
+		r.Type = ElectionType(electionType)
+	}
+
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+
+	if n >= 0 {
+		r.TopicPartitions = make(map[string][]int32, n)
+		for i := 0; i < n; i++ {
+			topic, err := pd.getString()
+			if err != nil {
+				return err
+			}
+			if r.TopicPartitions[topic], err = pd.getInt32Array(); err != nil {
+				return err
+			}
+		}
+	}
+
+	timeout, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	r.Timeout = time.Duration(timeout) * time.Millisecond
+
+	return nil
+}
+
+func (r *ElectLeadersRequest) key() int16 {
+	return 43
+}
+
+func (r *ElectLeadersRequest) version() int16 {
+	return r.Version
+}
+
+func (r *ElectLeadersRequest) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V2_4_0_0
+	default:
+		return V2_2_0_0
+	}
+}
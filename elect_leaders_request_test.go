@@ -0,0 +1,38 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	electLeadersRequestAllPartitions = []byte{
+		255, 255, 255, 255, // all partitions
+		0, 0, 39, 16, // timeout 10000 ms
+	}
+
+	electLeadersRequestOneTopicV1 = []byte{
+		1,          // unclean election
+		0, 0, 0, 1, // 1 topic
+		0, 5, 't', 'o', 'p', 'i', 'c',
+		0, 0, 0, 2, // 2 partitions
+		0, 0, 0, 0, // partition 0
+		0, 0, 0, 1, // partition 1
+		0, 0, 39, 16, // timeout 10000 ms
+	}
+)
+
+func TestElectLeadersRequest(t *testing.T) {
+	request := &ElectLeadersRequest{
+		Timeout: 10 * time.Second,
+	}
+	testRequest(t, "all partitions", request, electLeadersRequestAllPartitions)
+
+	request = &ElectLeadersRequest{
+		Version:         1,
+		Type:            UncleanElection,
+		TopicPartitions: map[string][]int32{"topic": {0, 1}},
+		Timeout:         10 * time.Second,
+	}
+	testRequest(t, "one topic v1", request, electLeadersRequestOneTopicV1)
+}
@@ -0,0 +1,49 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	electLeadersResponseNoError = []byte{
+		0, 0, 0, 100, // throttle time 100 ms
+		0, 0, 0, 1, // 1 topic
+		0, 5, 't', 'o', 'p', 'i', 'c',
+		0, 0, 0, 1, // 1 partition
+		0, 0, 0, 0, // partition 0
+		0, 0, // no error
+		255, 255, // no error message
+	}
+
+	electLeadersResponseWithErrorsV1 = []byte{
+		0, 0, 0, 100, // throttle time 100 ms
+		0, 0, // no top level error
+		0, 0, 0, 1, // 1 topic
+		0, 5, 't', 'o', 'p', 'i', 'c',
+		0, 0, 0, 1, // 1 partition
+		0, 0, 0, 1, // partition 1
+		0, 84, // ErrElectionNotNeeded
+		0, 5, 'e', 'r', 'r', 'o', 'r',
+	}
+)
+
+func TestElectLeadersResponse(t *testing.T) {
+	response := &ElectLeadersResponse{
+		ThrottleTime: 100 * time.Millisecond,
+		ReplicaElectionResults: map[string]map[int32]*TopicPartitionError{
+			"topic": {0: {}},
+		},
+	}
+	testResponse(t, "no error", response, electLeadersResponseNoError)
+
+	errMsg := "error"
+	response = &ElectLeadersResponse{
+		Version:      1,
+		ThrottleTime: 100 * time.Millisecond,
+		ReplicaElectionResults: map[string]map[int32]*TopicPartitionError{
+			"topic": {1: {Err: ErrElectionNotNeeded, ErrMsg: &errMsg}},
+		},
+	}
+	testResponse(t, "with errors v1", response, electLeadersResponseWithErrorsV1)
+}
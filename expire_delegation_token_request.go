@@ -0,0 +1,50 @@
+package sarama
+
+import "time"
+
+// ExpireDelegationTokenRequest is a request to change the expiry time of a
+// delegation token, which can only be brought forward.
+type ExpireDelegationTokenRequest struct {
+	Version int16
+	HMAC    []byte
+
+	// The expiry time from now, -1 (or -1ms) to expire the token immediately.
+	ExpiryPeriod time.Duration
+}
+
+func (e *ExpireDelegationTokenRequest) encode(pe packetEncoder) error {
+	if err := pe.putBytes(e.HMAC); err != nil {
+		return err
+	}
+	pe.putInt64(durationToMillis(e.ExpiryPeriod))
+	return nil
+}
+
+func (e *ExpireDelegationTokenRequest) decode(pd packetDecoder, version int16) (err error) {
+	e.Version = version
+	if e.HMAC, err = pd.getBytes(); err != nil {
+		return err
+	}
+
+	expiryPeriod, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	e.ExpiryPeriod = time.Duration(expiryPeriod) * time.Millisecond
+	return nil
+}
+
+func (e *ExpireDelegationTokenRequest) key() int16 {
+	return 40
+}
+
+func (e *ExpireDelegationTokenRequest) version() int16 {
+	return e.Version
+}
+
+func (e *ExpireDelegationTokenRequest) requiredVersion() KafkaVersion {
+	if e.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
@@ -0,0 +1,19 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var expireDelegationTokenRequest = []byte{
+	0, 0, 0, 3, 1, 2, 3, // hmac
+	255, 255, 255, 255, 255, 255, 255, 255, // expiry period: immediately
+}
+
+func TestExpireDelegationTokenRequest(t *testing.T) {
+	request := &ExpireDelegationTokenRequest{
+		HMAC:         []byte{1, 2, 3},
+		ExpiryPeriod: -1 * time.Millisecond,
+	}
+	testRequest(t, "expire", request, expireDelegationTokenRequest)
+}
@@ -0,0 +1,57 @@
+package sarama
+
+import "time"
+
+// ExpireDelegationTokenResponse holds the new expiry time of the delegation
+// token.
+type ExpireDelegationTokenResponse struct {
+	Version      int16
+	ErrorCode    KError
+	ExpiryTime   time.Time
+	ThrottleTime time.Duration
+}
+
+func (e *ExpireDelegationTokenResponse) encode(pe packetEncoder) error {
+	pe.putInt16(int16(e.ErrorCode))
+	pe.putInt64(timeToMillis(e.ExpiryTime))
+	pe.putInt32(int32(e.ThrottleTime / time.Millisecond))
+	return nil
+}
+
+func (e *ExpireDelegationTokenResponse) decode(pd packetDecoder, version int16) error {
+	e.Version = version
+
+	kerr, err := pd.getInt16()
+	if err != nil {
+		return err
+	}
+	e.ErrorCode = KError(kerr)
+
+	expiryTime, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	e.ExpiryTime = millisToTime(expiryTime)
+
+	throttleTime, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	e.ThrottleTime = time.Duration(throttleTime) * time.Millisecond
+	return nil
+}
+
+func (e *ExpireDelegationTokenResponse) key() int16 {
+	return 40
+}
+
+func (e *ExpireDelegationTokenResponse) version() int16 {
+	return e.Version
+}
+
+func (e *ExpireDelegationTokenResponse) requiredVersion() KafkaVersion {
+	if e.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
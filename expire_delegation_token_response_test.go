@@ -0,0 +1,21 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var expireDelegationTokenResponse = []byte{
+	0, 0, // no error
+	0, 0, 0, 0, 0, 0, 3, 232, // expiry time: 1000
+	0, 0, 0, 50, // throttle time
+}
+
+func TestExpireDelegationTokenResponse(t *testing.T) {
+	response := &ExpireDelegationTokenResponse{
+		Version:      1,
+		ExpiryTime:   millisToTime(1000),
+		ThrottleTime: 50 * time.Millisecond,
+	}
+	testResponse(t, "expire", response, expireDelegationTokenResponse)
+}
@@ -26,6 +26,20 @@ var (
 		0x00, 0x05, 't', 'o', 'p', 'i', 'c',
 		0x00, 0x00, 0x00, 0x01,
 		0x00, 0x00, 0x00, 0x12, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x34, 0x00, 0x00, 0x00, 0x56}
+
+	fetchRequestOneBlockV9 = []byte{
+		0xFF, 0xFF, 0xFF, 0xFF, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0xFF,
+		0x01,
+		0x00, 0x00, 0x00, 0x00, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x05, 't', 'o', 'p', 'i', 'c',
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x12, 0x00, 0x00, 0x00, 0x05,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x34,
+		0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		0x00, 0x00, 0x00, 0x56,
+		0x00, 0x00, 0x00, 0x00}
 )
 
 func TestFetchRequest(t *testing.T) {
@@ -46,3 +60,13 @@ func TestFetchRequest(t *testing.T) {
 	request.Isolation = ReadCommitted
 	testRequest(t, "one block v4", request, fetchRequestOneBlockV4)
 }
+
+func TestFetchRequestWithLeaderEpoch(t *testing.T) {
+	request := new(FetchRequest)
+	request.Version = 9
+	request.MaxBytes = 0xFF
+	request.Isolation = ReadCommitted
+	request.SessionEpoch = -1
+	request.AddBlockWithLeaderEpoch("topic", 0x12, 0x34, 0x56, 5)
+	testRequest(t, "one block v9", request, fetchRequestOneBlockV9)
+}
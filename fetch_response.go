@@ -33,6 +33,7 @@ type FetchResponseBlock struct {
 	Err                 KError
 	HighWaterMarkOffset int64
 	LastStableOffset    int64
+	LogStartOffset      int64 // Only valid for Version >= 5
 	AbortedTransactions []*AbortedTransaction
 	Records             *Records // deprecated: use FetchResponseBlock.RecordsSet
 	RecordsSet          []*Records
@@ -57,6 +58,13 @@ func (b *FetchResponseBlock) decode(pd packetDecoder, version int16) (err error)
 			return err
 		}
 
+		if version >= 5 {
+			b.LogStartOffset, err = pd.getInt64()
+			if err != nil {
+				return err
+			}
+		}
+
 		numTransact, err := pd.getArrayLength()
 		if err != nil {
 			return err
@@ -166,6 +174,10 @@ func (b *FetchResponseBlock) encode(pe packetEncoder, version int16) (err error)
 	if version >= 4 {
 		pe.putInt64(b.LastStableOffset)
 
+		if version >= 5 {
+			pe.putInt64(b.LogStartOffset)
+		}
+
 		if err = pe.putArrayLength(len(b.AbortedTransactions)); err != nil {
 			return err
 		}
@@ -200,7 +212,9 @@ func (b *FetchResponseBlock) getAbortedTransactions() []*AbortedTransaction {
 type FetchResponse struct {
 	Blocks        map[string]map[int32]*FetchResponseBlock
 	ThrottleTime  time.Duration
-	Version       int16 // v1 requires 0.9+, v2 requires 0.10+
+	Err           KError // Only valid for Version >= 7
+	SessionID     int32  // Only valid for Version >= 7
+	Version       int16  // v1 requires 0.9+, v2 requires 0.10+
 	LogAppendTime bool
 	Timestamp     time.Time
 }
@@ -216,6 +230,19 @@ func (r *FetchResponse) decode(pd packetDecoder, version int16) (err error) {
 		r.ThrottleTime = time.Duration(throttle) * time.Millisecond
 	}
 
+	if r.Version >= 7 {
+		tmp, err := pd.getInt16()
+		if err != nil {
+			return err
+		}
+		r.Err = KError(tmp)
+
+		r.SessionID, err = pd.getInt32()
+		if err != nil {
+			return err
+		}
+	}
+
 	numTopics, err := pd.getArrayLength()
 	if err != nil {
 		return err
@@ -258,6 +285,11 @@ func (r *FetchResponse) encode(pe packetEncoder) (err error) {
 		pe.putInt32(int32(r.ThrottleTime / time.Millisecond))
 	}
 
+	if r.Version >= 7 {
+		pe.putInt16(int16(r.Err))
+		pe.putInt32(r.SessionID)
+	}
+
 	err = pe.putArrayLength(len(r.Blocks))
 	if err != nil {
 		return err
@@ -304,6 +336,14 @@ func (r *FetchResponse) requiredVersion() KafkaVersion {
 		return V0_10_1_0
 	case 4:
 		return V0_11_0_0
+	case 5, 6:
+		return V1_0_0_0
+	case 7:
+		return V1_1_0_0
+	case 8:
+		return V2_0_0_0
+	case 9:
+		return V2_1_0_0
 	default:
 		return MinVersion
 	}
@@ -487,3 +527,12 @@ func (r *FetchResponse) SetLastStableOffset(topic string, partition int32, offse
 	frb := r.getOrCreateBlock(topic, partition)
 	frb.LastStableOffset = offset
 }
+
+func (r *FetchResponse) SetPartitionLeaderEpoch(topic string, partition int32, epoch int32) {
+	frb := r.getOrCreateBlock(topic, partition)
+	for _, records := range frb.RecordsSet {
+		if records.RecordBatch != nil {
+			records.RecordBatch.PartitionLeaderEpoch = epoch
+		}
+	}
+}
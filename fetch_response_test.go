@@ -3,6 +3,7 @@ package sarama
 import (
 	"bytes"
 	"testing"
+	"time"
 )
 
 var (
@@ -106,6 +107,21 @@ var (
 		0x00,
 		0xFF, 0xFF, 0xFF, 0xFF,
 		0x00, 0x00, 0x00, 0x02, 0x00, 0xEE}
+
+	emptyRecordsFetchResponseV9 = []byte{
+		0x00, 0x00, 0x00, 0x64, // throttle time
+		0x00, 0x00, // error code
+		0x00, 0x00, 0x00, 0x00, // session id
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x05, 't', 'o', 'p', 'i', 'c',
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x05,
+		0x00, 0x4A, // fenced leader epoch
+		0x00, 0x00, 0x00, 0x00, 0x10, 0x10, 0x10, 0x10,
+		0x00, 0x00, 0x00, 0x00, 0x10, 0x10, 0x10, 0x10,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x20, // log start offset
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00}
 )
 
 func TestEmptyFetchResponse(t *testing.T) {
@@ -329,3 +345,27 @@ func TestOneMessageFetchResponseV4(t *testing.T) {
 		t.Error("Decoding produced incorrect message value.")
 	}
 }
+
+func TestFetchResponseV9(t *testing.T) {
+	response := FetchResponse{}
+	testVersionDecodable(t, "empty records v9", &response, emptyRecordsFetchResponseV9, 9)
+
+	if response.ThrottleTime != 100*time.Millisecond {
+		t.Error("Decoding produced incorrect throttle time", response.ThrottleTime)
+	}
+	block := response.GetBlock("topic", 5)
+	if block == nil {
+		t.Fatal("GetBlock didn't return block.")
+	}
+	if block.Err != ErrFencedLeaderEpoch {
+		t.Error("Decoding didn't produce correct error code.")
+	}
+	if block.LastStableOffset != 0x10101010 {
+		t.Error("Decoding didn't produce correct last stable offset.")
+	}
+	if block.LogStartOffset != 0x20 {
+		t.Error("Decoding didn't produce correct log start offset.")
+	}
+
+	testEncodable(t, "empty records v9", &response, emptyRecordsFetchResponseV9)
+}
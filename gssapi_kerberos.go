@@ -214,7 +214,10 @@ func (krbAuth *GSSAPIKerberosAuth) Authorize(broker *Broker) error {
 	// Construct SPN using serviceName and host
 	// SPN format: <SERVICE>/<FQDN>
 
-	host := strings.SplitN(broker.addr, ":", 2)[0] // Strip port part
+	host := broker.hostName
+	if host == "" {
+		host = strings.SplitN(broker.addr, ":", 2)[0] // Strip port part
+	}
 	spn := fmt.Sprintf("%s/%s", broker.conf.Net.SASL.GSSAPI.ServiceName, host)
 
 	ticket, encKey, err := kerberosClient.GetServiceTicket(spn)
@@ -0,0 +1,169 @@
+package sarama
+
+import "sort"
+
+// IncrementalAlterConfigsOperation is the operation applied to a config entry
+// by an IncrementalAlterConfigsRequest.
+type IncrementalAlterConfigsOperation int8
+
+const (
+	// IncrementalAlterConfigsOperationSet sets the value of the entry.
+	IncrementalAlterConfigsOperationSet IncrementalAlterConfigsOperation = iota
+	// IncrementalAlterConfigsOperationDelete reverts the entry to its default value.
+	IncrementalAlterConfigsOperationDelete
+	// IncrementalAlterConfigsOperationAppend adds the value to a list entry.
+	IncrementalAlterConfigsOperationAppend
+	// IncrementalAlterConfigsOperationSubtract removes the value from a list entry.
+	IncrementalAlterConfigsOperationSubtract
+)
+
+// IncrementalAlterConfigsRequest is an incremental alter config request type.
+// Unlike AlterConfigsRequest, the config entries that are not part of the
+// request keep their current value.
+type IncrementalAlterConfigsRequest struct {
+	Resources    []*IncrementalAlterConfigsResource
+	ValidateOnly bool
+}
+
+// IncrementalAlterConfigsResource is an incremental alter config resource type
+type IncrementalAlterConfigsResource struct {
+	Type          ConfigResourceType
+	Name          string
+	ConfigEntries map[string]IncrementalAlterConfigsEntry
+}
+
+// IncrementalAlterConfigsEntry is an operation on a config entry. The value is
+// ignored by IncrementalAlterConfigsOperationDelete.
+type IncrementalAlterConfigsEntry struct {
+	Operation IncrementalAlterConfigsOperation
+	Value     *string
+}
+
+func (a *IncrementalAlterConfigsRequest) encode(pe packetEncoder) error {
+	if err := pe.putArrayLength(len(a.Resources)); err != nil {
+		return err
+	}
+
+	for _, r := range a.Resources {
+		if err := r.encode(pe); err != nil {
+			return err
+		}
+	}
+
+	pe.putBool(a.ValidateOnly)
+	return nil
+}
+
+func (a *IncrementalAlterConfigsRequest) decode(pd packetDecoder, version int16) error {
+	resourceCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+
+	a.Resources = make([]*IncrementalAlterConfigsResource, resourceCount)
+	for i := range a.Resources {
+		r := &IncrementalAlterConfigsResource{}
+		err = r.decode(pd, version)
+		if err != nil {
+			return err
+		}
+		a.Resources[i] = r
+	}
+
+	validateOnly, err := pd.getBool()
+	if err != nil {
+		return err
+	}
+
+	a.ValidateOnly = validateOnly
+
+	return nil
+}
+
+func (a *IncrementalAlterConfigsResource) encode(pe packetEncoder) error {
+	pe.putInt8(int8(a.Type))
+
+	if err := pe.putString(a.Name); err != nil {
+		return err
+	}
+
+	if err := pe.putArrayLength(len(a.ConfigEntries)); err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(a.ConfigEntries))
+	for name := range a.ConfigEntries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := pe.putString(name); err != nil {
+			return err
+		}
+		entry := a.ConfigEntries[name]
+		pe.putInt8(int8(entry.Operation))
+		if err := pe.putNullableString(entry.Value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *IncrementalAlterConfigsResource) decode(pd packetDecoder, version int16) error {
+	t, err := pd.getInt8()
+	if err != nil {
+		return err
+	}
+	a.Type = ConfigResourceType(t)
+
+	name, err := pd.getString()
+	if err != nil {
+		return err
+	}
+	a.Name = name
+
+	n, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+
+	if n > 0 {
+		a.ConfigEntries = make(map[string]IncrementalAlterConfigsEntry, n)
+		for i := 0; i < n; i++ {
+			configName, err := pd.getString()
+			if err != nil {
+				return err
+			}
+
+			operation, err := pd.getInt8()
+			if err != nil {
+				return err
+			}
+
+			value, err := pd.getNullableString()
+			if err != nil {
+				return err
+			}
+
+			a.ConfigEntries[configName] = IncrementalAlterConfigsEntry{
+				Operation: IncrementalAlterConfigsOperation(operation),
+				Value:     value,
+			}
+		}
+	}
+	return err
+}
+
+func (a *IncrementalAlterConfigsRequest) key() int16 {
+	return 44
+}
+
+func (a *IncrementalAlterConfigsRequest) version() int16 {
+	return 0
+}
+
+func (a *IncrementalAlterConfigsRequest) requiredVersion() KafkaVersion {
+	return V2_3_0_0
+}
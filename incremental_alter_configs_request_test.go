@@ -0,0 +1,78 @@
+package sarama
+
+import "testing"
+
+var (
+	emptyIncrementalAlterConfigsRequest = []byte{
+		0, 0, 0, 0, // 0 configs
+		0, // don't Validate
+	}
+
+	singleIncrementalAlterConfigsRequest = []byte{
+		0, 0, 0, 1, // 1 config
+		2,                   // a topic
+		0, 3, 'f', 'o', 'o', // topic name: foo
+		0, 0, 0, 2, // 2 config entries
+		0, 12, // 12 chars
+		'r', 'e', 't', 'e', 'n', 't', 'i', 'o', 'n', '.', 'm', 's',
+		0,    // SET
+		0, 4, // 4 chars
+		'1', '0', '0', '0',
+		0, 10, // 10 chars
+		's', 'e', 'g', 'm', 'e', 'n', 't', '.', 'm', 's',
+		1,        // DELETE
+		255, 255, // no value
+		1, // Validate
+	}
+
+	brokerLoggerIncrementalAlterConfigsRequest = []byte{
+		0, 0, 0, 1, // 1 config
+		8,         // a broker logger
+		0, 1, '1', // broker ID: 1
+		0, 0, 0, 1, // 1 config entry
+		0, 4, 'r', 'o', 'o', 't',
+		0,    // SET
+		0, 4, // 4 chars
+		'W', 'A', 'R', 'N',
+		0, // don't Validate
+	}
+)
+
+func TestIncrementalAlterConfigsRequest(t *testing.T) {
+	var request *IncrementalAlterConfigsRequest
+
+	request = &IncrementalAlterConfigsRequest{
+		Resources: []*IncrementalAlterConfigsResource{},
+	}
+	testRequest(t, "no requests", request, emptyIncrementalAlterConfigsRequest)
+
+	configValue := "1000"
+	request = &IncrementalAlterConfigsRequest{
+		Resources: []*IncrementalAlterConfigsResource{
+			{
+				Type: TopicResource,
+				Name: "foo",
+				ConfigEntries: map[string]IncrementalAlterConfigsEntry{
+					"retention.ms": {Operation: IncrementalAlterConfigsOperationSet, Value: &configValue},
+					"segment.ms":   {Operation: IncrementalAlterConfigsOperationDelete},
+				},
+			},
+		},
+		ValidateOnly: true,
+	}
+	testRequest(t, "one topic", request, singleIncrementalAlterConfigsRequest)
+
+	level := "WARN"
+	request = &IncrementalAlterConfigsRequest{
+		Resources: []*IncrementalAlterConfigsResource{
+			{
+				Type: BrokerLoggerResource,
+				Name: "1",
+				ConfigEntries: map[string]IncrementalAlterConfigsEntry{
+					"root": {Operation: IncrementalAlterConfigsOperationSet, Value: &level},
+				},
+			},
+		},
+	}
+	testRequest(t, "broker logger", request, brokerLoggerIncrementalAlterConfigsRequest)
+}
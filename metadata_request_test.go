@@ -60,6 +60,9 @@ var (
 	metadataRequestNoTopicsV5     = append(metadataRequestNoTopicsV1, byte(0))
 	metadataRequestAutoCreateV5   = append(metadataRequestOneTopicV3, byte(1))
 	metadataRequestNoAutoCreateV5 = append(metadataRequestOneTopicV3, byte(0))
+
+	// The v6 and v7 metadata requests are the same as v5. An additional field
+	// for the leader epoch has been added to the v7 metadata response
 )
 
 func TestMetadataRequestV0(t *testing.T) {
@@ -133,3 +136,17 @@ func TestMetadataRequestV5(t *testing.T) {
 	request.AllowAutoTopicCreation = false
 	testRequest(t, "one topic", request, metadataRequestNoAutoCreateV5)
 }
+
+func TestMetadataRequestV7(t *testing.T) {
+	request := new(MetadataRequest)
+	request.Version = 7
+	testRequest(t, "no topics", request, metadataRequestNoTopicsV5)
+
+	request.Topics = []string{"topic1"}
+
+	request.AllowAutoTopicCreation = true
+	testRequest(t, "one topic", request, metadataRequestAutoCreateV5)
+
+	request.AllowAutoTopicCreation = false
+	testRequest(t, "one topic", request, metadataRequestNoAutoCreateV5)
+}
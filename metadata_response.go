@@ -4,6 +4,7 @@ type PartitionMetadata struct {
 	Err             KError
 	ID              int32
 	Leader          int32
+	LeaderEpoch     int32 // Only valid for Version >= 7
 	Replicas        []int32
 	Isr             []int32
 	OfflineReplicas []int32
@@ -26,6 +27,13 @@ func (pm *PartitionMetadata) decode(pd packetDecoder, version int16) (err error)
 		return err
 	}
 
+	if version >= 7 {
+		pm.LeaderEpoch, err = pd.getInt32()
+		if err != nil {
+			return err
+		}
+	}
+
 	pm.Replicas, err = pd.getInt32Array()
 	if err != nil {
 		return err
@@ -51,6 +59,10 @@ func (pm *PartitionMetadata) encode(pe packetEncoder, version int16) (err error)
 	pe.putInt32(pm.ID)
 	pe.putInt32(pm.Leader)
 
+	if version >= 7 {
+		pe.putInt32(pm.LeaderEpoch)
+	}
+
 	err = pe.putInt32Array(pm.Replicas)
 	if err != nil {
 		return err
@@ -265,6 +277,10 @@ func (r *MetadataResponse) requiredVersion() KafkaVersion {
 		return V0_11_0_0
 	case 5:
 		return V1_0_0_0
+	case 6:
+		return V2_0_0_0
+	case 7:
+		return V2_1_0_0
 	default:
 		return MinVersion
 	}
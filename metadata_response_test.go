@@ -100,6 +100,25 @@ var (
 		0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
 		0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x03,
 	}
+
+	noBrokersOneTopicWithLeaderEpochV7 = []byte{
+		0x00, 0x00, 0x00, 0x05,
+		0x00, 0x00, 0x00, 0x00,
+		0x00, 0x09, 'c', 'l', 'u', 's', 't', 'e', 'r', 'I', 'd',
+		0x00, 0x00, 0x00, 0x02,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00,
+		0x00, 0x03, 'f', 'o', 'o',
+		0x00,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x07,
+		0x00, 0x00, 0x00, 0x09,
+		0x00, 0x00, 0x00, 0x03, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x03,
+		0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x02,
+		0x00, 0x00, 0x00, 0x00,
+	}
 )
 
 func TestEmptyMetadataResponseV0(t *testing.T) {
@@ -288,3 +307,24 @@ func TestMetadataResponseWithOfflineReplicasV5(t *testing.T) {
 		t.Error("Decoding produced", len(response.Topics[0].Partitions[0].OfflineReplicas), "should have been 1!")
 	}
 }
+
+func TestMetadataResponseWithLeaderEpochV7(t *testing.T) {
+	response := MetadataResponse{}
+
+	testVersionDecodable(t, "no brokers, 1 topic with leader epoch V7", &response, noBrokersOneTopicWithLeaderEpochV7, 7)
+	if len(response.Topics) != 1 || len(response.Topics[0].Partitions) != 1 {
+		t.Fatal("Decoding produced", response.Topics, "should have been 1 topic with 1 partition!")
+	}
+	partition := response.Topics[0].Partitions[0]
+	if partition.Leader != 7 {
+		t.Error("Decoding produced", partition.Leader, "should have been 7!")
+	}
+	if partition.LeaderEpoch != 9 {
+		t.Error("Decoding produced", partition.LeaderEpoch, "should have been 9!")
+	}
+	if len(partition.Isr) != 2 {
+		t.Error("Decoding produced", partition.Isr, "should have been 2 replicas!")
+	}
+
+	testResponse(t, "no brokers, 1 topic with leader epoch V7", &response, noBrokersOneTopicWithLeaderEpochV7)
+}
@@ -282,6 +282,10 @@ func (b *MockBroker) handleRequests(conn net.Conn, idx int, wg *sync.WaitGroup)
 				b.lock.Unlock()
 				continue
 			}
+			if headerVersion(res, 0) >= 1 {
+				// no tagged fields in the response header
+				encodedRes = append([]byte{0}, encodedRes...)
+			}
 
 			binary.BigEndian.PutUint32(resHeader, uint32(len(encodedRes)+4))
 			binary.BigEndian.PutUint32(resHeader[4:], uint32(req.correlationID))
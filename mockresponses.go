@@ -2,7 +2,9 @@ package sarama
 
 import (
 	"fmt"
+	"sort"
 	"strings"
+	"time"
 )
 
 // TestReporter has methods matching go's testing.T to avoid importing
@@ -134,15 +136,19 @@ func (m *MockDescribeGroupsResponse) For(reqBody versionedDecoder) encoder {
 type MockMetadataResponse struct {
 	controllerID int32
 	leaders      map[string]map[int32]int32
+	leaderEpochs map[string]map[int32]int32
+	internal     map[string]bool
 	brokers      map[string]int32
 	t            TestReporter
 }
 
 func NewMockMetadataResponse(t TestReporter) *MockMetadataResponse {
 	return &MockMetadataResponse{
-		leaders: make(map[string]map[int32]int32),
-		brokers: make(map[string]int32),
-		t:       t,
+		leaders:      make(map[string]map[int32]int32),
+		leaderEpochs: make(map[string]map[int32]int32),
+		internal:     make(map[string]bool),
+		brokers:      make(map[string]int32),
+		t:            t,
 	}
 }
 
@@ -156,6 +162,25 @@ func (mmr *MockMetadataResponse) SetLeader(topic string, partition, brokerID int
 	return mmr
 }
 
+// SetLeaderEpoch sets the epoch of the leader of the partition, returned by
+// the version 7 and later of the response.
+func (mmr *MockMetadataResponse) SetLeaderEpoch(topic string, partition, epoch int32) *MockMetadataResponse {
+	partitions := mmr.leaderEpochs[topic]
+	if partitions == nil {
+		partitions = make(map[int32]int32)
+		mmr.leaderEpochs[topic] = partitions
+	}
+	partitions[partition] = epoch
+	return mmr
+}
+
+// SetInternal marks the topic as internal to Kafka, as returned by the version
+// 1 and later of the response.
+func (mmr *MockMetadataResponse) SetInternal(topic string) *MockMetadataResponse {
+	mmr.internal[topic] = true
+	return mmr
+}
+
 func (mmr *MockMetadataResponse) SetBroker(addr string, brokerID int32) *MockMetadataResponse {
 	mmr.brokers[addr] = brokerID
 	return mmr
@@ -189,6 +214,7 @@ func (mmr *MockMetadataResponse) For(reqBody versionedDecoder) encoder {
 				metadataResponse.AddTopicPartition(topic, partition, brokerID, replicas, replicas, offlineReplicas, ErrNoError)
 			}
 		}
+		mmr.setTopicDetails(metadataResponse)
 		return metadataResponse
 	}
 	for _, topic := range metadataRequest.Topics {
@@ -196,9 +222,21 @@ func (mmr *MockMetadataResponse) For(reqBody versionedDecoder) encoder {
 			metadataResponse.AddTopicPartition(topic, partition, brokerID, replicas, replicas, offlineReplicas, ErrNoError)
 		}
 	}
+	mmr.setTopicDetails(metadataResponse)
 	return metadataResponse
 }
 
+// setTopicDetails sets whether the topics of the response are internal and
+// the epochs of the leaders of their partitions
+func (mmr *MockMetadataResponse) setTopicDetails(metadataResponse *MetadataResponse) {
+	for _, topic := range metadataResponse.Topics {
+		topic.IsInternal = mmr.internal[topic.Name]
+		for _, partition := range topic.Partitions {
+			partition.LeaderEpoch = mmr.leaderEpochs[topic.Name][partition.ID]
+		}
+	}
+}
+
 // MockOffsetResponse is an `OffsetResponse` builder.
 type MockOffsetResponse struct {
 	offsets map[string]map[int32]map[int64]int64
@@ -261,6 +299,52 @@ func (mor *MockOffsetResponse) getOffset(topic string, partition int32, time int
 	return offset
 }
 
+// MockOffsetForLeaderEpochResponse is an `OffsetForLeaderEpochResponse` builder.
+type MockOffsetForLeaderEpochResponse struct {
+	endOffsets map[string]map[int32]map[int32]int64
+	t          TestReporter
+}
+
+func NewMockOffsetForLeaderEpochResponse(t TestReporter) *MockOffsetForLeaderEpochResponse {
+	return &MockOffsetForLeaderEpochResponse{
+		endOffsets: make(map[string]map[int32]map[int32]int64),
+		t:          t,
+	}
+}
+
+// SetEndOffset sets the end offset of the epoch in the log of the partition.
+// The epochs which were not set are unknown to the leader.
+func (mor *MockOffsetForLeaderEpochResponse) SetEndOffset(topic string, partition, leaderEpoch int32, endOffset int64) *MockOffsetForLeaderEpochResponse {
+	partitions := mor.endOffsets[topic]
+	if partitions == nil {
+		partitions = make(map[int32]map[int32]int64)
+		mor.endOffsets[topic] = partitions
+	}
+	epochs := partitions[partition]
+	if epochs == nil {
+		epochs = make(map[int32]int64)
+		partitions[partition] = epochs
+	}
+	epochs[leaderEpoch] = endOffset
+	return mor
+}
+
+func (mor *MockOffsetForLeaderEpochResponse) For(reqBody versionedDecoder) encoder {
+	request := reqBody.(*OffsetForLeaderEpochRequest)
+	response := &OffsetForLeaderEpochResponse{Version: request.Version}
+	for topic, partitions := range request.blocks {
+		for partition, block := range partitions {
+			endOffset, ok := mor.endOffsets[topic][partition][block.leaderEpoch]
+			if !ok {
+				response.AddBlock(topic, partition, &OffsetForLeaderEpochResponseBlock{LeaderEpoch: -1, EndOffset: -1})
+				continue
+			}
+			response.AddBlock(topic, partition, &OffsetForLeaderEpochResponseBlock{LeaderEpoch: block.leaderEpoch, EndOffset: endOffset})
+		}
+	}
+	return response
+}
+
 // MockFetchResponse is a `FetchResponse` builder.
 type MockFetchResponse struct {
 	messages       map[string]map[int32]map[int64]Encoder
@@ -602,7 +686,7 @@ func (mr *MockOffsetFetchResponse) SetOffset(group, topic string, partition int3
 func (mr *MockOffsetFetchResponse) For(reqBody versionedDecoder) encoder {
 	req := reqBody.(*OffsetFetchRequest)
 	group := req.ConsumerGroup
-	res := &OffsetFetchResponse{}
+	res := &OffsetFetchResponse{Version: req.Version}
 	for topic, partitions := range mr.offsets[group] {
 		for partition, block := range partitions {
 			res.AddBlock(topic, partition, block)
@@ -686,6 +770,53 @@ func (mr *MockCreatePartitionsResponse) For(reqBody versionedDecoder) encoder {
 	return res
 }
 
+// MockElectLeadersResponse is an `ElectLeadersResponse` builder. Every
+// requested partition is reported as successfully elected unless an error was
+// set for it.
+type MockElectLeadersResponse struct {
+	errors map[string]map[int32]KError
+	t      TestReporter
+}
+
+func NewMockElectLeadersResponse(t TestReporter) *MockElectLeadersResponse {
+	return &MockElectLeadersResponse{t: t}
+}
+
+func (mr *MockElectLeadersResponse) SetPartitionError(topic string, partition int32, kerror KError) *MockElectLeadersResponse {
+	if mr.errors == nil {
+		mr.errors = make(map[string]map[int32]KError)
+	}
+	if mr.errors[topic] == nil {
+		mr.errors[topic] = make(map[int32]KError)
+	}
+	mr.errors[topic][partition] = kerror
+	return mr
+}
+
+func (mr *MockElectLeadersResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*ElectLeadersRequest)
+	res := &ElectLeadersResponse{Version: req.Version}
+	res.ReplicaElectionResults = make(map[string]map[int32]*TopicPartitionError)
+
+	topicPartitions := req.TopicPartitions
+	if topicPartitions == nil {
+		topicPartitions = make(map[string][]int32)
+		for topic, partitions := range mr.errors {
+			for partition := range partitions {
+				topicPartitions[topic] = append(topicPartitions[topic], partition)
+			}
+		}
+	}
+
+	for topic, partitions := range topicPartitions {
+		res.ReplicaElectionResults[topic] = make(map[int32]*TopicPartitionError)
+		for _, partition := range partitions {
+			res.ReplicaElectionResults[topic][partition] = &TopicPartitionError{Err: mr.errors[topic][partition]}
+		}
+	}
+	return res
+}
+
 type MockDeleteRecordsResponse struct {
 	t TestReporter
 }
@@ -772,6 +903,27 @@ func (mr *MockAlterConfigsResponse) For(reqBody versionedDecoder) encoder {
 	return res
 }
 
+type MockIncrementalAlterConfigsResponse struct {
+	t TestReporter
+}
+
+func NewMockIncrementalAlterConfigsResponse(t TestReporter) *MockIncrementalAlterConfigsResponse {
+	return &MockIncrementalAlterConfigsResponse{t: t}
+}
+
+func (mr *MockIncrementalAlterConfigsResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*IncrementalAlterConfigsRequest)
+	res := &IncrementalAlterConfigsResponse{}
+
+	for _, r := range req.Resources {
+		res.Resources = append(res.Resources, &AlterConfigsResourceResponse{Name: r.Name,
+			Type:     r.Type,
+			ErrorMsg: "",
+		})
+	}
+	return res
+}
+
 type MockCreateAclsResponse struct {
 	t TestReporter
 }
@@ -813,9 +965,10 @@ func (mr *MockListAclsResponse) For(reqBody versionedDecoder) encoder {
 }
 
 type MockSaslAuthenticateResponse struct {
-	t             TestReporter
-	kerror        KError
-	saslAuthBytes []byte
+	t                 TestReporter
+	kerror            KError
+	saslAuthBytes     []byte
+	sessionLifetimeMs int64
 }
 
 func NewMockSaslAuthenticateResponse(t TestReporter) *MockSaslAuthenticateResponse {
@@ -823,9 +976,11 @@ func NewMockSaslAuthenticateResponse(t TestReporter) *MockSaslAuthenticateRespon
 }
 
 func (msar *MockSaslAuthenticateResponse) For(reqBody versionedDecoder) encoder {
-	res := &SaslAuthenticateResponse{}
+	req := reqBody.(*SaslAuthenticateRequest)
+	res := &SaslAuthenticateResponse{Version: req.Version}
 	res.Err = msar.kerror
 	res.SaslAuthBytes = msar.saslAuthBytes
+	res.SessionLifetimeMs = msar.sessionLifetimeMs
 	return res
 }
 
@@ -839,6 +994,11 @@ func (msar *MockSaslAuthenticateResponse) SetAuthBytes(saslAuthBytes []byte) *Mo
 	return msar
 }
 
+func (msar *MockSaslAuthenticateResponse) SetSessionLifetimeMs(sessionLifetimeMs int64) *MockSaslAuthenticateResponse {
+	msar.sessionLifetimeMs = sessionLifetimeMs
+	return msar
+}
+
 type MockDeleteAclsResponse struct {
 	t TestReporter
 }
@@ -885,3 +1045,464 @@ func (mr *MockDeleteAclsResponse) For(reqBody versionedDecoder) encoder {
 	}
 	return res
 }
+
+type MockDeleteGroupsResponse struct {
+	deletedGroups []string
+	t             TestReporter
+}
+
+func NewMockDeleteGroupsResponse(t TestReporter) *MockDeleteGroupsResponse {
+	return &MockDeleteGroupsResponse{t: t}
+}
+
+func (m *MockDeleteGroupsResponse) SetDeletedGroups(groups []string) *MockDeleteGroupsResponse {
+	m.deletedGroups = groups
+	return m
+}
+
+func (m *MockDeleteGroupsResponse) For(reqBody versionedDecoder) encoder {
+	resp := &DeleteGroupsResponse{
+		GroupErrorCodes: map[string]KError{},
+	}
+	for _, group := range m.deletedGroups {
+		resp.GroupErrorCodes[group] = ErrNoError
+	}
+	return resp
+}
+
+// MockDescribeLogDirsResponse is a `DescribeLogDirsResponse` builder.
+type MockDescribeLogDirsResponse struct {
+	t       TestReporter
+	logDirs []DescribeLogDirsResponseDirMetadata
+}
+
+func NewMockDescribeLogDirsResponse(t TestReporter) *MockDescribeLogDirsResponse {
+	return &MockDescribeLogDirsResponse{t: t}
+}
+
+// SetLogDirs adds a log directory at logDirPath holding, for each topic, the
+// given number of partitions of 1000 bytes each.
+func (m *MockDescribeLogDirsResponse) SetLogDirs(logDirPath string, topicPartitions map[string]int) *MockDescribeLogDirsResponse {
+	var topics []DescribeLogDirsResponseTopic
+	for topic := range topicPartitions {
+		var partitions []DescribeLogDirsResponsePartition
+		for i := 0; i < topicPartitions[topic]; i++ {
+			partitions = append(partitions, DescribeLogDirsResponsePartition{
+				PartitionID: int32(i),
+				IsTemporary: false,
+				OffsetLag:   int64(0),
+				Size:        int64(1000),
+			})
+		}
+		topics = append(topics, DescribeLogDirsResponseTopic{
+			Topic:      topic,
+			Partitions: partitions,
+		})
+	}
+	m.logDirs = append(m.logDirs, DescribeLogDirsResponseDirMetadata{
+		ErrorCode: ErrNoError,
+		Path:      logDirPath,
+		Topics:    topics,
+	})
+	return m
+}
+
+func (m *MockDescribeLogDirsResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*DescribeLogDirsRequest)
+	return &DescribeLogDirsResponse{
+		Version: req.Version,
+		LogDirs: m.logDirs,
+	}
+}
+
+// MockAlterReplicaLogDirsResponse is an `AlterReplicaLogDirsResponse` builder.
+// Every requested replica is reported as moved unless an error was set for it.
+type MockAlterReplicaLogDirsResponse struct {
+	t      TestReporter
+	errors map[string]map[int32]KError
+}
+
+func NewMockAlterReplicaLogDirsResponse(t TestReporter) *MockAlterReplicaLogDirsResponse {
+	return &MockAlterReplicaLogDirsResponse{t: t}
+}
+
+func (m *MockAlterReplicaLogDirsResponse) SetError(topic string, partition int32, kerror KError) *MockAlterReplicaLogDirsResponse {
+	if m.errors == nil {
+		m.errors = make(map[string]map[int32]KError)
+	}
+	if m.errors[topic] == nil {
+		m.errors[topic] = make(map[int32]KError)
+	}
+	m.errors[topic][partition] = kerror
+	return m
+}
+
+func (m *MockAlterReplicaLogDirsResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*AlterReplicaLogDirsRequest)
+	res := &AlterReplicaLogDirsResponse{
+		Version: req.Version,
+		Results: make(map[string]map[int32]KError),
+	}
+	for _, topics := range req.LogDirs {
+		for topic, partitions := range topics {
+			if res.Results[topic] == nil {
+				res.Results[topic] = make(map[int32]KError)
+			}
+			for _, partition := range partitions {
+				res.Results[topic][partition] = m.errors[topic][partition]
+			}
+		}
+	}
+	return res
+}
+
+// MockDeleteOffsetResponse is a `DeleteOffsetsResponse` builder.
+type MockDeleteOffsetResponse struct {
+	errorCode      KError
+	topic          string
+	partition      int32
+	errorPartition KError
+}
+
+func NewMockDeleteOffsetResponse(t TestReporter) *MockDeleteOffsetResponse {
+	return &MockDeleteOffsetResponse{}
+}
+
+func (m *MockDeleteOffsetResponse) SetDeletedOffset(errorCode KError, topic string, partition int32, errorPartition KError) *MockDeleteOffsetResponse {
+	m.errorCode = errorCode
+	m.topic = topic
+	m.partition = partition
+	m.errorPartition = errorPartition
+	return m
+}
+
+func (m *MockDeleteOffsetResponse) For(reqBody versionedDecoder) encoder {
+	resp := &DeleteOffsetsResponse{
+		ErrorCode: m.errorCode,
+		Errors: map[string]map[int32]KError{
+			m.topic: {m.partition: m.errorPartition},
+		},
+	}
+	return resp
+}
+
+// MockDescribeUserScramCredentialsResponse is a `DescribeUserScramCredentialsResponse` builder.
+// Requested users without any credential are reported with ErrResourceNotFound.
+type MockDescribeUserScramCredentialsResponse struct {
+	t           TestReporter
+	credentials map[string][]*UserScramCredentialsResponseInfo
+}
+
+func NewMockDescribeUserScramCredentialsResponse(t TestReporter) *MockDescribeUserScramCredentialsResponse {
+	return &MockDescribeUserScramCredentialsResponse{t: t}
+}
+
+func (m *MockDescribeUserScramCredentialsResponse) SetCredential(user string, mechanism ScramMechanismType, iterations int32) *MockDescribeUserScramCredentialsResponse {
+	if m.credentials == nil {
+		m.credentials = make(map[string][]*UserScramCredentialsResponseInfo)
+	}
+	m.credentials[user] = append(m.credentials[user], &UserScramCredentialsResponseInfo{
+		Mechanism:  mechanism,
+		Iterations: iterations,
+	})
+	return m
+}
+
+func (m *MockDescribeUserScramCredentialsResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*DescribeUserScramCredentialsRequest)
+	res := &DescribeUserScramCredentialsResponse{Version: req.Version}
+
+	var users []string
+	if req.DescribeUsers == nil {
+		for user := range m.credentials {
+			users = append(users, user)
+		}
+		sort.Strings(users)
+	} else {
+		for _, user := range req.DescribeUsers {
+			users = append(users, user.Name)
+		}
+	}
+
+	for _, user := range users {
+		result := &DescribeUserScramCredentialsResult{
+			User:            user,
+			CredentialInfos: m.credentials[user],
+		}
+		if len(result.CredentialInfos) == 0 {
+			result.ErrorCode = ErrResourceNotFound
+		}
+		res.Results = append(res.Results, result)
+	}
+	return res
+}
+
+// MockAlterUserScramCredentialsResponse is an `AlterUserScramCredentialsResponse` builder.
+// The credentials of every requested user are reported as altered unless an error was set for it.
+type MockAlterUserScramCredentialsResponse struct {
+	t      TestReporter
+	errors map[string]KError
+}
+
+func NewMockAlterUserScramCredentialsResponse(t TestReporter) *MockAlterUserScramCredentialsResponse {
+	return &MockAlterUserScramCredentialsResponse{t: t}
+}
+
+func (m *MockAlterUserScramCredentialsResponse) SetError(user string, kerror KError) *MockAlterUserScramCredentialsResponse {
+	if m.errors == nil {
+		m.errors = make(map[string]KError)
+	}
+	m.errors[user] = kerror
+	return m
+}
+
+func (m *MockAlterUserScramCredentialsResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*AlterUserScramCredentialsRequest)
+	res := &AlterUserScramCredentialsResponse{Version: req.Version}
+
+	seen := make(map[string]bool)
+	addResult := func(user string) {
+		if seen[user] {
+			return
+		}
+		seen[user] = true
+		res.Results = append(res.Results, &AlterUserScramCredentialsResult{
+			User:      user,
+			ErrorCode: m.errors[user],
+		})
+	}
+	for _, d := range req.Deletions {
+		addResult(d.Name)
+	}
+	for _, u := range req.Upsertions {
+		addResult(u.Name)
+	}
+	return res
+}
+
+// MockDescribeClientQuotasResponse is a `DescribeClientQuotasResponse` builder.
+// It returns the entries matching the filter of the request.
+type MockDescribeClientQuotasResponse struct {
+	t       TestReporter
+	entries []DescribeClientQuotasEntry
+}
+
+func NewMockDescribeClientQuotasResponse(t TestReporter) *MockDescribeClientQuotasResponse {
+	return &MockDescribeClientQuotasResponse{t: t}
+}
+
+func (m *MockDescribeClientQuotasResponse) AddEntry(entity []QuotaEntityComponent, values map[string]float64) *MockDescribeClientQuotasResponse {
+	m.entries = append(m.entries, DescribeClientQuotasEntry{
+		Entity: entity,
+		Values: values,
+	})
+	return m
+}
+
+func (m *MockDescribeClientQuotasResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*DescribeClientQuotasRequest)
+	res := &DescribeClientQuotasResponse{Entries: []DescribeClientQuotasEntry{}}
+	for _, entry := range m.entries {
+		if quotaEntityMatches(entry.Entity, req.Components, req.Strict) {
+			res.Entries = append(res.Entries, entry)
+		}
+	}
+	return res
+}
+
+func quotaEntityMatches(entity []QuotaEntityComponent, components []QuotaFilterComponent, strict bool) bool {
+	if strict && len(entity) != len(components) {
+		return false
+	}
+	for _, filter := range components {
+		matched := false
+		for _, c := range entity {
+			if c.EntityType != filter.EntityType {
+				continue
+			}
+			switch filter.MatchType {
+			case QuotaMatchExact:
+				matched = c.MatchType == QuotaMatchExact && c.Name == filter.Match
+			case QuotaMatchDefault:
+				matched = c.MatchType == QuotaMatchDefault
+			case QuotaMatchAny:
+				matched = true
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// MockAlterClientQuotasResponse is an `AlterClientQuotasResponse` builder.
+// Every requested entity is reported as altered unless an error was set for it.
+type MockAlterClientQuotasResponse struct {
+	t      TestReporter
+	errors map[string]KError
+}
+
+func NewMockAlterClientQuotasResponse(t TestReporter) *MockAlterClientQuotasResponse {
+	return &MockAlterClientQuotasResponse{t: t}
+}
+
+func (m *MockAlterClientQuotasResponse) SetError(entity []QuotaEntityComponent, kerror KError) *MockAlterClientQuotasResponse {
+	if m.errors == nil {
+		m.errors = make(map[string]KError)
+	}
+	m.errors[fmt.Sprint(entity)] = kerror
+	return m
+}
+
+func (m *MockAlterClientQuotasResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*AlterClientQuotasRequest)
+	res := &AlterClientQuotasResponse{}
+	for _, entry := range req.Entries {
+		res.Entries = append(res.Entries, AlterClientQuotasEntryResponse{
+			ErrorCode: m.errors[fmt.Sprint(entry.Entity)],
+			Entity:    entry.Entity,
+		})
+	}
+	return res
+}
+
+// MockCreateDelegationTokenResponse is a `CreateDelegationTokenResponse` builder.
+// The token expires in a day and lives at most for the requested lifetime, or a week.
+type MockCreateDelegationTokenResponse struct {
+	t       TestReporter
+	owner   KafkaPrincipal
+	tokenID string
+	hmac    []byte
+	kerror  KError
+}
+
+func NewMockCreateDelegationTokenResponse(t TestReporter) *MockCreateDelegationTokenResponse {
+	return &MockCreateDelegationTokenResponse{t: t}
+}
+
+func (m *MockCreateDelegationTokenResponse) SetToken(owner KafkaPrincipal, tokenID string, hmac []byte) *MockCreateDelegationTokenResponse {
+	m.owner = owner
+	m.tokenID = tokenID
+	m.hmac = hmac
+	return m
+}
+
+func (m *MockCreateDelegationTokenResponse) SetError(kerror KError) *MockCreateDelegationTokenResponse {
+	m.kerror = kerror
+	return m
+}
+
+func (m *MockCreateDelegationTokenResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*CreateDelegationTokenRequest)
+	res := &CreateDelegationTokenResponse{Version: req.Version, ErrorCode: m.kerror}
+	if m.kerror != ErrNoError {
+		return res
+	}
+
+	now := time.Now()
+	maxLifetime := 7 * 24 * time.Hour
+	if req.MaxLifetime > 0 {
+		maxLifetime = req.MaxLifetime
+	}
+	res.Token = DelegationToken{
+		Owner:      m.owner,
+		IssueTime:  now,
+		ExpiryTime: now.Add(24 * time.Hour),
+		MaxTime:    now.Add(maxLifetime),
+		TokenID:    m.tokenID,
+		HMAC:       m.hmac,
+	}
+	return res
+}
+
+// MockRenewDelegationTokenResponse is a `RenewDelegationTokenResponse` builder.
+// The token expires after the requested period, or a day.
+type MockRenewDelegationTokenResponse struct {
+	t      TestReporter
+	kerror KError
+}
+
+func NewMockRenewDelegationTokenResponse(t TestReporter) *MockRenewDelegationTokenResponse {
+	return &MockRenewDelegationTokenResponse{t: t}
+}
+
+func (m *MockRenewDelegationTokenResponse) SetError(kerror KError) *MockRenewDelegationTokenResponse {
+	m.kerror = kerror
+	return m
+}
+
+func (m *MockRenewDelegationTokenResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*RenewDelegationTokenRequest)
+	res := &RenewDelegationTokenResponse{Version: req.Version, ErrorCode: m.kerror}
+	if m.kerror == ErrNoError {
+		period := 24 * time.Hour
+		if req.RenewPeriod >= 0 {
+			period = req.RenewPeriod
+		}
+		res.ExpiryTime = time.Now().Add(period)
+	}
+	return res
+}
+
+// MockExpireDelegationTokenResponse is an `ExpireDelegationTokenResponse` builder.
+// The token expires after the requested period, or immediately.
+type MockExpireDelegationTokenResponse struct {
+	t      TestReporter
+	kerror KError
+}
+
+func NewMockExpireDelegationTokenResponse(t TestReporter) *MockExpireDelegationTokenResponse {
+	return &MockExpireDelegationTokenResponse{t: t}
+}
+
+func (m *MockExpireDelegationTokenResponse) SetError(kerror KError) *MockExpireDelegationTokenResponse {
+	m.kerror = kerror
+	return m
+}
+
+func (m *MockExpireDelegationTokenResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*ExpireDelegationTokenRequest)
+	res := &ExpireDelegationTokenResponse{Version: req.Version, ErrorCode: m.kerror}
+	if m.kerror == ErrNoError {
+		res.ExpiryTime = time.Now()
+		if req.ExpiryPeriod > 0 {
+			res.ExpiryTime = res.ExpiryTime.Add(req.ExpiryPeriod)
+		}
+	}
+	return res
+}
+
+// MockDescribeDelegationTokenResponse is a `DescribeDelegationTokenResponse` builder.
+// It returns the tokens of the requested owners.
+type MockDescribeDelegationTokenResponse struct {
+	t      TestReporter
+	tokens []DelegationToken
+}
+
+func NewMockDescribeDelegationTokenResponse(t TestReporter) *MockDescribeDelegationTokenResponse {
+	return &MockDescribeDelegationTokenResponse{t: t}
+}
+
+func (m *MockDescribeDelegationTokenResponse) AddToken(token DelegationToken) *MockDescribeDelegationTokenResponse {
+	m.tokens = append(m.tokens, token)
+	return m
+}
+
+func (m *MockDescribeDelegationTokenResponse) For(reqBody versionedDecoder) encoder {
+	req := reqBody.(*DescribeDelegationTokenRequest)
+	res := &DescribeDelegationTokenResponse{Version: req.Version}
+	for _, token := range m.tokens {
+		if req.Owners == nil {
+			res.Tokens = append(res.Tokens, token)
+			continue
+		}
+		for _, owner := range req.Owners {
+			if owner == token.Owner {
+				res.Tokens = append(res.Tokens, token)
+				break
+			}
+		}
+	}
+	return res
+}
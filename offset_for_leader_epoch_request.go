@@ -0,0 +1,130 @@
+package sarama
+
+type offsetForLeaderEpochRequestBlock struct {
+	currentLeaderEpoch int32
+	leaderEpoch        int32
+}
+
+func (b *offsetForLeaderEpochRequestBlock) encode(pe packetEncoder, version int16) error {
+	if version >= 2 {
+		pe.putInt32(b.currentLeaderEpoch)
+	}
+	pe.putInt32(b.leaderEpoch)
+	return nil
+}
+
+func (b *offsetForLeaderEpochRequestBlock) decode(pd packetDecoder, version int16) (err error) {
+	b.currentLeaderEpoch = -1
+	if version >= 2 {
+		if b.currentLeaderEpoch, err = pd.getInt32(); err != nil {
+			return err
+		}
+	}
+	if b.leaderEpoch, err = pd.getInt32(); err != nil {
+		return err
+	}
+	return nil
+}
+
+// OffsetForLeaderEpochRequest (API key 23) asks the leaders of partitions the
+// end offset of an epoch in their log, that is the offset following the last
+// record appended by the leader of that epoch, so that the consumers can tell
+// whether the log was truncated past their position (KIP-101 and KIP-320).
+// Version 2 introduced the current leader epoch, fencing the requests sent to
+// stale leaders.
+type OffsetForLeaderEpochRequest struct {
+	Version int16
+	blocks  map[string]map[int32]*offsetForLeaderEpochRequestBlock
+}
+
+func (r *OffsetForLeaderEpochRequest) encode(pe packetEncoder) (err error) {
+	if err = pe.putArrayLength(len(r.blocks)); err != nil {
+		return err
+	}
+	for topic, partitions := range r.blocks {
+		if err = pe.putString(topic); err != nil {
+			return err
+		}
+		if err = pe.putArrayLength(len(partitions)); err != nil {
+			return err
+		}
+		for partition, block := range partitions {
+			pe.putInt32(partition)
+			if err = block.encode(pe, r.Version); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (r *OffsetForLeaderEpochRequest) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	topicCount, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+	if topicCount > 0 {
+		r.blocks = make(map[string]map[int32]*offsetForLeaderEpochRequestBlock, topicCount)
+	}
+	for i := 0; i < topicCount; i++ {
+		topic, err := pd.getString()
+		if err != nil {
+			return err
+		}
+		partitionCount, err := pd.getArrayLength()
+		if err != nil {
+			return err
+		}
+		r.blocks[topic] = make(map[int32]*offsetForLeaderEpochRequestBlock, partitionCount)
+		for j := 0; j < partitionCount; j++ {
+			partition, err := pd.getInt32()
+			if err != nil {
+				return err
+			}
+			block := &offsetForLeaderEpochRequestBlock{}
+			if err = block.decode(pd, version); err != nil {
+				return err
+			}
+			r.blocks[topic][partition] = block
+		}
+	}
+	return nil
+}
+
+func (r *OffsetForLeaderEpochRequest) key() int16 {
+	return 23
+}
+
+func (r *OffsetForLeaderEpochRequest) version() int16 {
+	return r.Version
+}
+
+func (r *OffsetForLeaderEpochRequest) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V2_0_0_0
+	case 2:
+		return V2_1_0_0
+	default:
+		return V0_11_0_0
+	}
+}
+
+// AddBlock asks the end offset of leaderEpoch in the log of the partition,
+// currentLeaderEpoch being the epoch of its leader known to the client, or -1
+// to disable the fencing.
+func (r *OffsetForLeaderEpochRequest) AddBlock(topic string, partitionID int32, currentLeaderEpoch, leaderEpoch int32) {
+	if r.blocks == nil {
+		r.blocks = make(map[string]map[int32]*offsetForLeaderEpochRequestBlock)
+	}
+
+	if r.blocks[topic] == nil {
+		r.blocks[topic] = make(map[int32]*offsetForLeaderEpochRequestBlock)
+	}
+
+	r.blocks[topic][partitionID] = &offsetForLeaderEpochRequestBlock{
+		currentLeaderEpoch: currentLeaderEpoch,
+		leaderEpoch:        leaderEpoch,
+	}
+}
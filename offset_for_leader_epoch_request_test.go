@@ -0,0 +1,41 @@
+package sarama
+
+import "testing"
+
+var (
+	offsetForLeaderEpochRequestNoBlocks = []byte{
+		0x00, 0x00, 0x00, 0x00}
+
+	offsetForLeaderEpochRequestOneBlock = []byte{
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x05, 't', 'o', 'p', 'i', 'c',
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x04,
+		0x00, 0x00, 0x00, 0x03}
+
+	offsetForLeaderEpochRequestOneBlockV2 = []byte{
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x05, 't', 'o', 'p', 'i', 'c',
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00, 0x00, 0x04,
+		0x00, 0x00, 0x00, 0x05,
+		0x00, 0x00, 0x00, 0x03}
+)
+
+func TestOffsetForLeaderEpochRequest(t *testing.T) {
+	request := new(OffsetForLeaderEpochRequest)
+	testRequest(t, "no blocks", request, offsetForLeaderEpochRequestNoBlocks)
+
+	request.AddBlock("topic", 4, -1, 3)
+	testRequest(t, "one block", request, offsetForLeaderEpochRequestOneBlock)
+
+	request.Version = 1
+	testRequest(t, "one block v1", request, offsetForLeaderEpochRequestOneBlock)
+}
+
+func TestOffsetForLeaderEpochRequestV2(t *testing.T) {
+	request := new(OffsetForLeaderEpochRequest)
+	request.Version = 2
+	request.AddBlock("topic", 4, 5, 3)
+	testRequest(t, "one block v2", request, offsetForLeaderEpochRequestOneBlockV2)
+}
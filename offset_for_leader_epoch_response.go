@@ -0,0 +1,163 @@
+package sarama
+
+import "time"
+
+type OffsetForLeaderEpochResponseBlock struct {
+	Err         KError
+	LeaderEpoch int32 // Only valid for Version >= 1
+	EndOffset   int64
+}
+
+func (b *OffsetForLeaderEpochResponseBlock) encode(pe packetEncoder, partition int32, version int16) error {
+	pe.putInt16(int16(b.Err))
+	pe.putInt32(partition)
+	if version >= 1 {
+		pe.putInt32(b.LeaderEpoch)
+	}
+	pe.putInt64(b.EndOffset)
+	return nil
+}
+
+func (b *OffsetForLeaderEpochResponseBlock) decode(pd packetDecoder, version int16) (partition int32, err error) {
+	tmp, err := pd.getInt16()
+	if err != nil {
+		return 0, err
+	}
+	b.Err = KError(tmp)
+
+	if partition, err = pd.getInt32(); err != nil {
+		return 0, err
+	}
+
+	if version >= 1 {
+		if b.LeaderEpoch, err = pd.getInt32(); err != nil {
+			return 0, err
+		}
+	}
+
+	if b.EndOffset, err = pd.getInt64(); err != nil {
+		return 0, err
+	}
+
+	return partition, nil
+}
+
+// OffsetForLeaderEpochResponse holds the end offsets of the epochs asked by
+// an OffsetForLeaderEpochRequest. The end offset is -1 when the leader does
+// not know the epoch, and the epoch returned from version 1 on is the largest
+// one of the log not greater than the one asked.
+type OffsetForLeaderEpochResponse struct {
+	Version      int16
+	ThrottleTime time.Duration // Only valid for Version >= 2
+	Blocks       map[string]map[int32]*OffsetForLeaderEpochResponseBlock
+}
+
+func (r *OffsetForLeaderEpochResponse) encode(pe packetEncoder) (err error) {
+	if r.Version >= 2 {
+		pe.putInt32(int32(r.ThrottleTime / time.Millisecond))
+	}
+
+	if err = pe.putArrayLength(len(r.Blocks)); err != nil {
+		return err
+	}
+	for topic, partitions := range r.Blocks {
+		if err = pe.putString(topic); err != nil {
+			return err
+		}
+		if err = pe.putArrayLength(len(partitions)); err != nil {
+			return err
+		}
+		for partition, block := range partitions {
+			if err = block.encode(pe, partition, r.Version); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (r *OffsetForLeaderEpochResponse) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+
+	if r.Version >= 2 {
+		throttle, err := pd.getInt32()
+		if err != nil {
+			return err
+		}
+		r.ThrottleTime = time.Duration(throttle) * time.Millisecond
+	}
+
+	numTopics, err := pd.getArrayLength()
+	if err != nil {
+		return err
+	}
+
+	r.Blocks = make(map[string]map[int32]*OffsetForLeaderEpochResponseBlock, numTopics)
+	for i := 0; i < numTopics; i++ {
+		name, err := pd.getString()
+		if err != nil {
+			return err
+		}
+
+		numBlocks, err := pd.getArrayLength()
+		if err != nil {
+			return err
+		}
+
+		r.Blocks[name] = make(map[int32]*OffsetForLeaderEpochResponseBlock, numBlocks)
+		for j := 0; j < numBlocks; j++ {
+			block := new(OffsetForLeaderEpochResponseBlock)
+			partition, err := block.decode(pd, version)
+			if err != nil {
+				return err
+			}
+			r.Blocks[name][partition] = block
+		}
+	}
+
+	return nil
+}
+
+func (r *OffsetForLeaderEpochResponse) key() int16 {
+	return 23
+}
+
+func (r *OffsetForLeaderEpochResponse) version() int16 {
+	return r.Version
+}
+
+func (r *OffsetForLeaderEpochResponse) requiredVersion() KafkaVersion {
+	switch r.Version {
+	case 1:
+		return V2_0_0_0
+	case 2:
+		return V2_1_0_0
+	default:
+		return V0_11_0_0
+	}
+}
+
+func (r *OffsetForLeaderEpochResponse) GetBlock(topic string, partition int32) *OffsetForLeaderEpochResponseBlock {
+	if r.Blocks == nil {
+		return nil
+	}
+
+	if r.Blocks[topic] == nil {
+		return nil
+	}
+
+	return r.Blocks[topic][partition]
+}
+
+// testing API
+
+func (r *OffsetForLeaderEpochResponse) AddBlock(topic string, partition int32, block *OffsetForLeaderEpochResponseBlock) {
+	if r.Blocks == nil {
+		r.Blocks = make(map[string]map[int32]*OffsetForLeaderEpochResponseBlock)
+	}
+	if r.Blocks[topic] == nil {
+		r.Blocks[topic] = make(map[int32]*OffsetForLeaderEpochResponseBlock)
+	}
+	r.Blocks[topic][partition] = block
+}
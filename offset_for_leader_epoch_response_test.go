@@ -0,0 +1,53 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var (
+	offsetForLeaderEpochResponseOneBlock = []byte{
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x05, 't', 'o', 'p', 'i', 'c',
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x00,
+		0x00, 0x00, 0x00, 0x04,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2A}
+
+	offsetForLeaderEpochResponseOneBlockV2 = []byte{
+		0x00, 0x00, 0x00, 0x64,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x05, 't', 'o', 'p', 'i', 'c',
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x4A,
+		0x00, 0x00, 0x00, 0x04,
+		0x00, 0x00, 0x00, 0x03,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x2A}
+)
+
+func TestOffsetForLeaderEpochResponse(t *testing.T) {
+	response := new(OffsetForLeaderEpochResponse)
+	response.AddBlock("topic", 4, &OffsetForLeaderEpochResponseBlock{EndOffset: 42})
+	testResponse(t, "one block", response, offsetForLeaderEpochResponseOneBlock)
+}
+
+func TestOffsetForLeaderEpochResponseV2(t *testing.T) {
+	response := OffsetForLeaderEpochResponse{}
+	testVersionDecodable(t, "one block v2", &response, offsetForLeaderEpochResponseOneBlockV2, 2)
+
+	if response.ThrottleTime != 100*time.Millisecond {
+		t.Error("Decoding produced incorrect throttle time", response.ThrottleTime)
+	}
+	block := response.GetBlock("topic", 4)
+	if block == nil {
+		t.Fatal("GetBlock didn't return block.")
+	}
+	if block.Err != ErrFencedLeaderEpoch {
+		t.Error("Decoding produced", block.Err, "should have been", ErrFencedLeaderEpoch)
+	}
+	if block.LeaderEpoch != 3 || block.EndOffset != 42 {
+		t.Error("Decoding produced epoch", block.LeaderEpoch, "end offset", block.EndOffset, "should have been 3 and 42")
+	}
+
+	testResponse(t, "one block v2", &response, offsetForLeaderEpochResponseOneBlockV2)
+}
@@ -7,13 +7,21 @@ import (
 
 // Offset Manager
 
-// OffsetManager uses Kafka to store and fetch consumed partition offsets.
+// OffsetManager uses Kafka, or the Consumer.Offsets.Store if set, to store and
+// fetch consumed partition offsets.
 type OffsetManager interface {
 	// ManagePartition creates a PartitionOffsetManager on the given topic/partition.
 	// It will return an error if this OffsetManager is already managing the given
 	// topic/partition.
 	ManagePartition(topic string, partition int32) (PartitionOffsetManager, error)
 
+	// Commit commits the offsets marked by the PartitionOffsetManagers and not
+	// committed yet, blocking until the coordinator acknowledged them. When
+	// some partitions fail to commit, it returns their errors as
+	// ConsumerErrors. It is meant to be used with
+	// Consumer.Offsets.AutoCommit.Enable disabled.
+	Commit() error
+
 	// Close stops the OffsetManager from managing offsets. It is required to call
 	// this function before an OffsetManager object passes out of scope, as it
 	// will otherwise leak memory. You must call this after all the
@@ -36,6 +44,8 @@ type offsetManager struct {
 	poms     map[string]map[int32]*partitionOffsetManager
 	pomsLock sync.RWMutex
 
+	commitLock sync.Mutex
+
 	closeOnce sync.Once
 	closing   chan none
 	closed    chan none
@@ -105,10 +115,12 @@ func (om *offsetManager) Close() error {
 		om.asyncClosePOMs()
 
 		// flush one last time
-		for attempt := 0; attempt <= om.conf.Consumer.Offsets.Retry.Max; attempt++ {
-			om.flushToBroker()
-			if om.releasePOMs(false) == 0 {
-				break
+		if om.conf.Consumer.Offsets.AutoCommit.Enable {
+			for attempt := 0; attempt <= om.conf.Consumer.Offsets.Retry.Max; attempt++ {
+				om.flushToBroker()
+				if om.releasePOMs(false) == 0 {
+					break
+				}
 			}
 		}
 
@@ -129,6 +141,10 @@ func (om *offsetManager) computeBackoff(retries int) time.Duration {
 }
 
 func (om *offsetManager) fetchInitialOffset(topic string, partition int32, retries int) (int64, string, error) {
+	if store := om.conf.Consumer.Offsets.Store; store != nil {
+		return store.FetchOffset(om.group, topic, partition)
+	}
+
 	broker, err := om.coordinator()
 	if err != nil {
 		if retries <= 0 {
@@ -225,20 +241,74 @@ func (om *offsetManager) mainLoop() {
 	for {
 		select {
 		case <-om.ticker.C:
-			om.flushToBroker()
-			om.releasePOMs(false)
+			if om.conf.Consumer.Offsets.AutoCommit.Enable {
+				om.flushToBroker()
+				om.releasePOMs(false)
+			} else {
+				// the offsets are only committed by Commit, so the closed
+				// POMs are released with the offsets marked since dropped
+				om.releasePOMs(true)
+			}
 		case <-om.closing:
 			return
 		}
 	}
 }
 
+func (om *offsetManager) Commit() error {
+	return om.commit(nil)
+}
+
+// commit synchronously commits the dirty offset of pom, or of all the
+// partitions when nil, returning the errors of the partitions which failed
+func (om *offsetManager) commit(pom *partitionOffsetManager) error {
+	om.commitLock.Lock()
+	defer om.commitLock.Unlock()
+
+	req := om.constructRequest(pom)
+	if req == nil {
+		return nil
+	}
+
+	if store := om.conf.Consumer.Offsets.Store; store != nil {
+		if errs := om.commitToStore(store, req, true); len(errs) > 0 {
+			return errs
+		}
+		return nil
+	}
+
+	broker, err := om.coordinator()
+	if err != nil {
+		return err
+	}
+
+	resp, err := broker.CommitOffset(req)
+	if err != nil {
+		om.releaseCoordinator(broker)
+		_ = broker.Close()
+		return err
+	}
+
+	if errs := om.handleResponse(broker, req, resp, true); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
 func (om *offsetManager) flushToBroker() {
-	req := om.constructRequest()
+	om.commitLock.Lock()
+	defer om.commitLock.Unlock()
+
+	req := om.constructRequest(nil)
 	if req == nil {
 		return
 	}
 
+	if store := om.conf.Consumer.Offsets.Store; store != nil {
+		om.commitToStore(store, req, false)
+		return
+	}
+
 	broker, err := om.coordinator()
 	if err != nil {
 		om.handleError(err)
@@ -253,10 +323,12 @@ func (om *offsetManager) flushToBroker() {
 		return
 	}
 
-	om.handleResponse(broker, req, resp)
+	om.handleResponse(broker, req, resp, false)
 }
 
-func (om *offsetManager) constructRequest() *OffsetCommitRequest {
+// constructRequest returns the request committing the dirty offset of only,
+// or of all the partitions when nil
+func (om *offsetManager) constructRequest(only *partitionOffsetManager) *OffsetCommitRequest {
 	var r *OffsetCommitRequest
 	var perPartitionTimestamp int64
 	if om.conf.Consumer.Offsets.Retention == 0 {
@@ -283,6 +355,9 @@ func (om *offsetManager) constructRequest() *OffsetCommitRequest {
 
 	for _, topicManagers := range om.poms {
 		for _, pom := range topicManagers {
+			if only != nil && pom != only {
+				continue
+			}
 			pom.lock.Lock()
 			if pom.dirty {
 				r.AddBlock(pom.topic, pom.partition, pom.offset, perPartitionTimestamp, pom.metadata)
@@ -298,10 +373,21 @@ func (om *offsetManager) constructRequest() *OffsetCommitRequest {
 	return nil
 }
 
-func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest, resp *OffsetCommitResponse) {
+// handleResponse marks the committed offsets as such. When sync, the errors of
+// all the partitions which failed to commit are returned instead of being
+// reported, the ones retried on the next flush included.
+func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest, resp *OffsetCommitResponse, sync bool) (errs ConsumerErrors) {
 	om.pomsLock.RLock()
 	defer om.pomsLock.RUnlock()
 
+	fail := func(pom *partitionOffsetManager, err error, report bool) {
+		if sync {
+			errs = append(errs, &ConsumerError{Topic: pom.topic, Partition: pom.partition, Err: err})
+		} else if report {
+			pom.handleError(err)
+		}
+	}
+
 	for _, topicManagers := range om.poms {
 		for _, pom := range topicManagers {
 			if req.blocks[pom.topic] == nil || req.blocks[pom.topic][pom.partition] == nil {
@@ -312,11 +398,11 @@ func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest
 			var ok bool
 
 			if resp.Errors[pom.topic] == nil {
-				pom.handleError(ErrIncompleteResponse)
+				fail(pom, ErrIncompleteResponse, true)
 				continue
 			}
 			if err, ok = resp.Errors[pom.topic][pom.partition]; !ok {
-				pom.handleError(ErrIncompleteResponse)
+				fail(pom, ErrIncompleteResponse, true)
 				continue
 			}
 
@@ -328,11 +414,13 @@ func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest
 				ErrConsumerCoordinatorNotAvailable, ErrNotCoordinatorForConsumer:
 				// not a critical error, we just need to redispatch
 				om.releaseCoordinator(broker)
+				fail(pom, err, false)
 			case ErrOffsetMetadataTooLarge, ErrInvalidCommitOffsetSize:
 				// nothing we can do about this, just tell the user and carry on
-				pom.handleError(err)
+				fail(pom, err, true)
 			case ErrOffsetsLoadInProgress:
 				// nothing wrong but we didn't commit, we'll get it next time round
+				fail(pom, err, false)
 			case ErrUnknownTopicOrPartition:
 				// let the user know *and* try redispatching - if topic-auto-create is
 				// enabled, redispatching should trigger a metadata req and create the
@@ -341,11 +429,42 @@ func (om *offsetManager) handleResponse(broker *Broker, req *OffsetCommitRequest
 				fallthrough
 			default:
 				// dunno, tell the user and try redispatching
-				pom.handleError(err)
+				fail(pom, err, true)
 				om.releaseCoordinator(broker)
 			}
 		}
 	}
+
+	return errs
+}
+
+// commitToStore commits the offsets of req with store, marking the ones
+// committed as such. When sync, the errors of the partitions which failed to
+// commit are returned instead of being reported.
+func (om *offsetManager) commitToStore(store OffsetStore, req *OffsetCommitRequest, sync bool) (errs ConsumerErrors) {
+	om.pomsLock.RLock()
+	defer om.pomsLock.RUnlock()
+
+	for _, topicManagers := range om.poms {
+		for _, pom := range topicManagers {
+			block := req.blocks[pom.topic][pom.partition]
+			if block == nil {
+				continue
+			}
+
+			if err := store.CommitOffset(om.group, pom.topic, pom.partition, block.offset, block.metadata); err != nil {
+				if sync {
+					errs = append(errs, &ConsumerError{Topic: pom.topic, Partition: pom.partition, Err: err})
+				} else {
+					pom.handleError(err)
+				}
+				continue
+			}
+			pom.updateCommitted(block.offset, block.metadata)
+		}
+	}
+
+	return errs
 }
 
 func (om *offsetManager) handleError(err error) {
@@ -442,6 +561,12 @@ type PartitionOffsetManager interface {
 	// allows incrementing the offset. cf MarkOffset for more details.
 	ResetOffset(offset int64, metadata string)
 
+	// Commit commits the offset marked for the partition if it was not yet,
+	// blocking until the coordinator acknowledged it, and returns the error
+	// of the commit. It is meant to be used with
+	// Consumer.Offsets.AutoCommit.Enable disabled.
+	Commit() error
+
 	// Errors returns a read channel of errors that occur during offset management, if
 	// enabled. By default, errors are logged and not returned over this channel. If
 	// you want to implement any custom error handling, set your config's
@@ -519,6 +644,14 @@ func (pom *partitionOffsetManager) ResetOffset(offset int64, metadata string) {
 	}
 }
 
+func (pom *partitionOffsetManager) Commit() error {
+	err := pom.parent.commit(pom)
+	if errs, ok := err.(ConsumerErrors); ok {
+		return errs[0].Err
+	}
+	return err
+}
+
 func (pom *partitionOffsetManager) updateCommitted(offset int64, metadata string) {
 	pom.lock.Lock()
 	defer pom.lock.Unlock()
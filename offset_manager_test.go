@@ -21,6 +21,12 @@ func initOffsetManagerWithBackoffFunc(t *testing.T, retention time.Duration,
 		config.Consumer.Offsets.Retention = retention
 	}
 
+	return initOffsetManagerWithConfig(t, config)
+}
+
+func initOffsetManagerWithConfig(t *testing.T, config *Config) (om OffsetManager,
+	testClient Client, broker, coordinator *MockBroker) {
+
 	broker = NewMockBroker(t, 1)
 	coordinator = NewMockBroker(t, 2)
 
@@ -410,6 +416,96 @@ func TestPartitionOffsetManagerCommitErr(t *testing.T) {
 	safeClose(t, testClient)
 }
 
+func TestPartitionOffsetManagerManualCommit(t *testing.T) {
+	config := NewConfig()
+	config.Metadata.Retry.Max = 1
+	config.Consumer.Offsets.CommitInterval = 1 * time.Millisecond
+	config.Consumer.Offsets.AutoCommit.Enable = false
+	config.Version = V0_9_0_0
+	om, testClient, broker, coordinator := initOffsetManagerWithConfig(t, config)
+	pom := initPartitionOffsetManager(t, om, coordinator, 5, "meta")
+
+	// the marked offset is not committed in the background
+	pom.MarkOffset(100, "modified_meta")
+	time.Sleep(10 * time.Millisecond)
+	for _, rr := range coordinator.History() {
+		if _, ok := rr.Request.(*OffsetCommitRequest); ok {
+			t.Fatal("Expected no offset to be committed automatically")
+		}
+	}
+
+	ocResponse := new(OffsetCommitResponse)
+	ocResponse.AddError("my_topic", 0, ErrOffsetMetadataTooLarge)
+	coordinator.Returns(ocResponse)
+	if err := pom.Commit(); err != ErrOffsetMetadataTooLarge {
+		t.Error("Expected the error of the commit, got", err)
+	}
+
+	ocResponse = new(OffsetCommitResponse)
+	ocResponse.AddError("my_topic", 0, ErrNoError)
+	coordinator.Returns(ocResponse)
+	if err := om.Commit(); err != nil {
+		t.Error(err)
+	}
+
+	// nothing left to commit, nor when closing
+	if err := om.Commit(); err != nil {
+		t.Error(err)
+	}
+	safeClose(t, pom)
+	safeClose(t, om)
+
+	commits := 0
+	for _, rr := range coordinator.History() {
+		if _, ok := rr.Request.(*OffsetCommitRequest); ok {
+			commits++
+		}
+	}
+	if commits != 2 {
+		t.Error("Expected 2 offset commits, got", commits)
+	}
+
+	broker.Close()
+	coordinator.Close()
+	safeClose(t, testClient)
+}
+
+func TestPartitionOffsetManagerManualCommitCloseUncommitted(t *testing.T) {
+	config := NewConfig()
+	config.Metadata.Retry.Max = 1
+	config.Consumer.Offsets.CommitInterval = 1 * time.Millisecond
+	config.Consumer.Offsets.AutoCommit.Enable = false
+	config.Version = V0_9_0_0
+	om, testClient, broker, coordinator := initOffsetManagerWithConfig(t, config)
+	pom := initPartitionOffsetManager(t, om, coordinator, 5, "meta")
+
+	// the marked offset is dropped rather than committed
+	pom.MarkOffset(100, "modified_meta")
+	closed := make(chan error)
+	go func() {
+		closed <- pom.Close()
+	}()
+	select {
+	case err := <-closed:
+		if err != nil {
+			t.Error(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the partition offset manager to close with an uncommitted offset")
+	}
+	safeClose(t, om)
+
+	for _, rr := range coordinator.History() {
+		if _, ok := rr.Request.(*OffsetCommitRequest); ok {
+			t.Error("Expected no offset to be committed")
+		}
+	}
+
+	broker.Close()
+	coordinator.Close()
+	safeClose(t, testClient)
+}
+
 // Test of recovery from abort
 func TestAbortPartitionOffsetManager(t *testing.T) {
 	om, testClient, broker, coordinator := initOffsetManager(t, 0)
@@ -0,0 +1,79 @@
+package sarama
+
+import (
+	"fmt"
+	"time"
+)
+
+// OffsetResetPolicy is where to consume a partition from when there is no
+// committed offset for it, or when the offset consumed from is out of the
+// range of the offsets available on the broker, like the `auto.offset.reset`
+// setting of the JVM consumer. Positive values are timestamps in milliseconds.
+type OffsetResetPolicy int64
+
+const (
+	// OffsetResetEarliest consumes from the oldest offset available.
+	OffsetResetEarliest = OffsetResetPolicy(OffsetOldest)
+	// OffsetResetLatest consumes from the offset of the next message produced.
+	OffsetResetLatest = OffsetResetPolicy(OffsetNewest)
+	// OffsetResetNone does not reset the offset, the consumer of the partition
+	// failing with an OffsetOutOfRangeError.
+	OffsetResetNone OffsetResetPolicy = -3
+)
+
+// OffsetResetToTimestamp consumes from the offset of the first message
+// produced at t or later, or from the offset of the next message produced
+// when there is none. It requires Version >= V0_10_1_0.
+func OffsetResetToTimestamp(t time.Time) OffsetResetPolicy {
+	return OffsetResetPolicy(t.UnixNano() / int64(time.Millisecond))
+}
+
+// OffsetOutOfRangeError is the error of the consumers of the partitions whose
+// offset is out of range, or of the partitions of consumer groups without
+// committed offset, when Consumer.Offsets.AutoReset is OffsetResetNone.
+type OffsetOutOfRangeError struct {
+	Offset int64 // the offset out of range, -1 when no offset was committed
+	Oldest int64 // the oldest offset available
+	Newest int64 // the offset of the next message produced
+}
+
+func (err *OffsetOutOfRangeError) Error() string {
+	if err.Offset < 0 {
+		return fmt.Sprintf("kafka: no committed offset, valid offsets are [%d, %d]", err.Oldest, err.Newest)
+	}
+	return fmt.Sprintf("kafka: offset %d out of range, valid offsets are [%d, %d]", err.Offset, err.Oldest, err.Newest)
+}
+
+// autoResetOffset returns the offset to consume the partition from as per
+// Consumer.Offsets.AutoReset, OffsetOldest and OffsetNewest included, offset
+// being the one out of range or -1 when none was committed.
+func autoResetOffset(client Client, topic string, partition int32, offset int64) (int64, error) {
+	switch policy := client.Config().Consumer.Offsets.AutoReset; {
+	case policy == OffsetResetEarliest:
+		return OffsetOldest, nil
+	case policy == OffsetResetLatest:
+		return OffsetNewest, nil
+	case policy > 0:
+		resetOffset, err := client.GetOffset(topic, partition, int64(policy))
+		if err != nil {
+			return 0, err
+		}
+		if resetOffset < 0 {
+			// nothing was produced since the timestamp
+			return OffsetNewest, nil
+		}
+		return resetOffset, nil
+	case policy == OffsetResetNone:
+		oldest, err := client.GetOffset(topic, partition, OffsetOldest)
+		if err != nil {
+			return 0, err
+		}
+		newest, err := client.GetOffset(topic, partition, OffsetNewest)
+		if err != nil {
+			return 0, err
+		}
+		return 0, &OffsetOutOfRangeError{Offset: offset, Oldest: oldest, Newest: newest}
+	default:
+		return 0, ErrOffsetOutOfRange
+	}
+}
@@ -0,0 +1,224 @@
+package sarama
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+)
+
+// OffsetStore stores the offsets consumed by the groups outside of Kafka. When
+// set as Consumer.Offsets.Store, the OffsetManagers, and so the
+// ConsumerGroups, fetch and commit the offsets of their partitions with it
+// instead of the group coordinator. The membership of the ConsumerGroups is
+// still managed by Kafka, but the commits are not fenced by its generations.
+type OffsetStore interface {
+	// FetchOffset returns the offset committed by the group for the partition
+	// and its metadata, or an offset of -1 when none was.
+	FetchOffset(group, topic string, partition int32) (int64, string, error)
+
+	// CommitOffset stores the offset consumed by the group for the
+	// partition, alongside its metadata.
+	CommitOffset(group, topic string, partition int32, offset int64, metadata string) error
+}
+
+// FileOffsetStore is an OffsetStore keeping the offsets in a JSON file, which
+// is rewritten on every commit. It suits the consumers of a single process.
+type FileOffsetStore struct {
+	path string
+
+	lock    sync.Mutex
+	offsets map[string]map[string]map[int32]storedOffset
+}
+
+type storedOffset struct {
+	Offset   int64  `json:"offset"`
+	Metadata string `json:"metadata,omitempty"`
+}
+
+// NewFileOffsetStore returns a FileOffsetStore keeping the offsets in the file
+// at path, which is created on the first commit if it does not exist.
+func NewFileOffsetStore(path string) (*FileOffsetStore, error) {
+	s := &FileOffsetStore{
+		path:    path,
+		offsets: make(map[string]map[string]map[int32]storedOffset),
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &s.offsets); err != nil {
+		return nil, fmt.Errorf("kafka: invalid offsets file %s: %v", path, err)
+	}
+	return s, nil
+}
+
+func (s *FileOffsetStore) FetchOffset(group, topic string, partition int32) (int64, string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if stored, ok := s.offsets[group][topic][partition]; ok {
+		return stored.Offset, stored.Metadata, nil
+	}
+	return -1, "", nil
+}
+
+func (s *FileOffsetStore) CommitOffset(group, topic string, partition int32, offset int64, metadata string) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	topics := s.offsets[group]
+	if topics == nil {
+		topics = make(map[string]map[int32]storedOffset)
+		s.offsets[group] = topics
+	}
+	partitions := topics[topic]
+	if partitions == nil {
+		partitions = make(map[int32]storedOffset)
+		topics[topic] = partitions
+	}
+	previous, existed := partitions[partition]
+	partitions[partition] = storedOffset{Offset: offset, Metadata: metadata}
+
+	if err := s.save(); err != nil {
+		// keep the offsets in line with the file
+		if existed {
+			partitions[partition] = previous
+		} else {
+			delete(partitions, partition)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the offsets to a temporary file renamed over the previous one,
+// so that the file is never left partially written
+func (s *FileOffsetStore) save() error {
+	data, err := json.Marshal(s.offsets)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(s.path), filepath.Base(s.path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// SQLDialect is the SQL dialect of the database of a SQLOffsetStore, which
+// sets the syntax of the parameters of its queries and of its upserts.
+type SQLDialect int
+
+const (
+	// SQLDialectPostgreSQL numbers the parameters `$1`, `$2`... and upserts
+	// with ON CONFLICT, as PostgreSQL does.
+	SQLDialectPostgreSQL SQLDialect = iota
+	// SQLDialectMySQL numbers the parameters `?` and upserts with ON DUPLICATE
+	// KEY UPDATE, as MySQL and MariaDB do.
+	SQLDialectMySQL
+	// SQLDialectSQLite numbers the parameters `?` and upserts with ON
+	// CONFLICT, as SQLite 3.24 and later do.
+	SQLDialectSQLite
+)
+
+// SQLOffsetStore is an OffsetStore keeping the offsets in a table of a SQL
+// database, which must be created beforehand as:
+//
+//	CREATE TABLE kafka_offsets (
+//		consumer_group VARCHAR(255) NOT NULL,
+//		topic VARCHAR(255) NOT NULL,
+//		partition_id INTEGER NOT NULL,
+//		committed_offset BIGINT NOT NULL,
+//		metadata TEXT NOT NULL,
+//		PRIMARY KEY (consumer_group, topic, partition_id)
+//	)
+//
+// Consuming exactly once into the database takes committing the offsets with
+// CommitOffsetTx in the transaction writing the results of the messages.
+type SQLOffsetStore struct {
+	db *sql.DB
+
+	fetchQuery  string
+	upsertQuery string
+}
+
+// NewSQLOffsetStore returns a SQLOffsetStore keeping the offsets in the table
+// of db, whose queries are written in the given dialect.
+func NewSQLOffsetStore(db *sql.DB, table string, dialect SQLDialect) *SQLOffsetStore {
+	p := func(i int) string {
+		if dialect == SQLDialectPostgreSQL {
+			return "$" + strconv.Itoa(i)
+		}
+		return "?"
+	}
+
+	upsert := "ON CONFLICT (consumer_group, topic, partition_id) DO UPDATE SET committed_offset = excluded.committed_offset, metadata = excluded.metadata"
+	if dialect == SQLDialectMySQL {
+		upsert = "ON DUPLICATE KEY UPDATE committed_offset = VALUES(committed_offset), metadata = VALUES(metadata)"
+	}
+
+	return &SQLOffsetStore{
+		db: db,
+		fetchQuery: fmt.Sprintf("SELECT committed_offset, metadata FROM %s WHERE consumer_group = %s AND topic = %s AND partition_id = %s",
+			table, p(1), p(2), p(3)),
+		upsertQuery: fmt.Sprintf("INSERT INTO %s (consumer_group, topic, partition_id, committed_offset, metadata) VALUES (%s, %s, %s, %s, %s) %s",
+			table, p(1), p(2), p(3), p(4), p(5), upsert),
+	}
+}
+
+func (s *SQLOffsetStore) FetchOffset(group, topic string, partition int32) (int64, string, error) {
+	var offset int64
+	var metadata string
+	err := s.db.QueryRow(s.fetchQuery, group, topic, partition).Scan(&offset, &metadata)
+	if err == sql.ErrNoRows {
+		return -1, "", nil
+	} else if err != nil {
+		return 0, "", err
+	}
+	return offset, metadata, nil
+}
+
+func (s *SQLOffsetStore) CommitOffset(group, topic string, partition int32, offset int64, metadata string) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err := s.CommitOffsetTx(tx, group, topic, partition, offset, metadata); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// CommitOffsetTx stores the offset consumed by the group for the partition in
+// tx, so that it is committed along with the rest of the transaction. The
+// offset should then be marked as well, for the consumer to carry on from it.
+func (s *SQLOffsetStore) CommitOffsetTx(tx *sql.Tx, group, topic string, partition int32, offset int64, metadata string) error {
+	// upsert in a single query, as with MySQL an UPDATE leaving the row
+	// unchanged affects no rows, just like when there is none
+	_, err := tx.Exec(s.upsertQuery, group, topic, partition, offset, metadata)
+	return err
+}
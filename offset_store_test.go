@@ -0,0 +1,271 @@
+package sarama
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFileOffsetStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sarama")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "offsets.json")
+
+	store, err := NewFileOffsetStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset, metadata, err := store.FetchOffset("group", "my_topic", 0); err != nil || offset != -1 || metadata != "" {
+		t.Fatal("Expected no offset to be committed, got", offset, metadata, err)
+	}
+	if err := store.CommitOffset("group", "my_topic", 0, 10, "meta"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CommitOffset("group", "my_topic", 0, 20, "modified_meta"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the offsets are read back from the file
+	store, err = NewFileOffsetStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset, metadata, err := store.FetchOffset("group", "my_topic", 0); err != nil || offset != 20 || metadata != "modified_meta" {
+		t.Error("Expected the committed offset, got", offset, metadata, err)
+	}
+	if offset, _, err := store.FetchOffset("other_group", "my_topic", 0); err != nil || offset != -1 {
+		t.Error("Expected no offset to be committed by the other group, got", offset, err)
+	}
+}
+
+func TestSQLOffsetStoreQueries(t *testing.T) {
+	for _, tc := range []struct {
+		dialect SQLDialect
+		fetch   string
+		upsert  string
+	}{
+		{
+			SQLDialectPostgreSQL,
+			"SELECT committed_offset, metadata FROM kafka_offsets WHERE consumer_group = $1 AND topic = $2 AND partition_id = $3",
+			"INSERT INTO kafka_offsets (consumer_group, topic, partition_id, committed_offset, metadata) VALUES ($1, $2, $3, $4, $5) " +
+				"ON CONFLICT (consumer_group, topic, partition_id) DO UPDATE SET committed_offset = excluded.committed_offset, metadata = excluded.metadata",
+		},
+		{
+			SQLDialectMySQL,
+			"SELECT committed_offset, metadata FROM kafka_offsets WHERE consumer_group = ? AND topic = ? AND partition_id = ?",
+			"INSERT INTO kafka_offsets (consumer_group, topic, partition_id, committed_offset, metadata) VALUES (?, ?, ?, ?, ?) " +
+				"ON DUPLICATE KEY UPDATE committed_offset = VALUES(committed_offset), metadata = VALUES(metadata)",
+		},
+		{
+			SQLDialectSQLite,
+			"SELECT committed_offset, metadata FROM kafka_offsets WHERE consumer_group = ? AND topic = ? AND partition_id = ?",
+			"INSERT INTO kafka_offsets (consumer_group, topic, partition_id, committed_offset, metadata) VALUES (?, ?, ?, ?, ?) " +
+				"ON CONFLICT (consumer_group, topic, partition_id) DO UPDATE SET committed_offset = excluded.committed_offset, metadata = excluded.metadata",
+		},
+	} {
+		store := NewSQLOffsetStore(nil, "kafka_offsets", tc.dialect)
+		if store.fetchQuery != tc.fetch {
+			t.Errorf("dialect %d: expected the fetch query %q, got %q", tc.dialect, tc.fetch, store.fetchQuery)
+		}
+		if store.upsertQuery != tc.upsert {
+			t.Errorf("dialect %d: expected the upsert query %q, got %q", tc.dialect, tc.upsert, store.upsertQuery)
+		}
+	}
+}
+
+// testSQLConn is a database/sql connection recording the statements run and
+// the transactions committed or rolled back, returning row to the queries and
+// err to the statements. It is its own driver and connector, for every test to
+// use a database of its own.
+type testSQLConn struct {
+	row []driver.Value
+	err error
+
+	lock sync.Mutex
+	log  []string
+}
+
+func (c *testSQLConn) record(entry string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.log = append(c.log, entry)
+}
+
+func (c *testSQLConn) Open(name string) (driver.Conn, error)            { return c, nil }
+func (c *testSQLConn) Connect(ctx context.Context) (driver.Conn, error) { return c, nil }
+func (c *testSQLConn) Driver() driver.Driver                            { return c }
+
+func (c *testSQLConn) Prepare(query string) (driver.Stmt, error) {
+	return &testSQLStmt{conn: c, query: query}, nil
+}
+
+func (c *testSQLConn) Close() error { return nil }
+
+func (c *testSQLConn) Begin() (driver.Tx, error) {
+	c.record("begin")
+	return c, nil
+}
+
+func (c *testSQLConn) Commit() error {
+	c.record("commit")
+	return nil
+}
+
+func (c *testSQLConn) Rollback() error {
+	c.record("rollback")
+	return nil
+}
+
+type testSQLStmt struct {
+	conn  *testSQLConn
+	query string
+}
+
+func (s *testSQLStmt) Close() error  { return nil }
+func (s *testSQLStmt) NumInput() int { return -1 }
+
+func (s *testSQLStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.conn.record(fmt.Sprint("exec ", args))
+	if s.conn.err != nil {
+		return nil, s.conn.err
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *testSQLStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.conn.record(fmt.Sprint("query ", args))
+	return &testSQLRows{row: s.conn.row}, nil
+}
+
+type testSQLRows struct {
+	row []driver.Value
+}
+
+func (r *testSQLRows) Columns() []string { return []string{"committed_offset", "metadata"} }
+func (r *testSQLRows) Close() error      { return nil }
+
+func (r *testSQLRows) Next(dest []driver.Value) error {
+	if r.row == nil {
+		return io.EOF
+	}
+	copy(dest, r.row)
+	r.row = nil
+	return nil
+}
+
+func TestSQLOffsetStore(t *testing.T) {
+	conn := &testSQLConn{}
+	db := sql.OpenDB(conn)
+	defer safeClose(t, db)
+	store := NewSQLOffsetStore(db, "kafka_offsets", SQLDialectPostgreSQL)
+
+	if offset, metadata, err := store.FetchOffset("group", "my_topic", 0); err != nil || offset != -1 || metadata != "" {
+		t.Error("Expected no offset to be committed, got", offset, metadata, err)
+	}
+	conn.row = []driver.Value{int64(10), "meta"}
+	if offset, metadata, err := store.FetchOffset("group", "my_topic", 0); err != nil || offset != 10 || metadata != "meta" {
+		t.Error("Expected the committed offset, got", offset, metadata, err)
+	}
+
+	// the offset is committed in a transaction of its own
+	if err := store.CommitOffset("group", "my_topic", 0, 20, "modified_meta"); err != nil {
+		t.Error(err)
+	}
+	// which is rolled back when the statement fails
+	conn.err = errors.New("duplicate key")
+	if err := store.CommitOffset("group", "my_topic", 0, 30, "modified_meta"); err != conn.err {
+		t.Error("Expected the error of the statement, got", err)
+	}
+
+	expected := []string{
+		"query [group my_topic 0]",
+		"query [group my_topic 0]",
+		"begin", "exec [group my_topic 0 20 modified_meta]", "commit",
+		"begin", "exec [group my_topic 0 30 modified_meta]", "rollback",
+	}
+	if !reflect.DeepEqual(conn.log, expected) {
+		t.Errorf("Expected the statements %q, got %q", expected, conn.log)
+	}
+}
+
+type testOffsetStore struct {
+	offsets map[int32]int64
+	err     error
+}
+
+func (s *testOffsetStore) FetchOffset(group, topic string, partition int32) (int64, string, error) {
+	if offset, ok := s.offsets[partition]; ok {
+		return offset, "meta", nil
+	}
+	return -1, "", nil
+}
+
+func (s *testOffsetStore) CommitOffset(group, topic string, partition int32, offset int64, metadata string) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.offsets[partition] = offset
+	return nil
+}
+
+func TestOffsetManagerWithStore(t *testing.T) {
+	store := &testOffsetStore{offsets: map[int32]int64{0: 5}}
+	broker := NewMockBroker(t, 1)
+	broker.Returns(new(MetadataResponse))
+
+	config := NewConfig()
+	config.Consumer.Offsets.CommitInterval = 1 * time.Millisecond
+	config.Consumer.Offsets.AutoCommit.Enable = false
+	config.Consumer.Offsets.Store = store
+	testClient, err := NewClient([]string{broker.Addr()}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	om, err := NewOffsetManagerFromClient("group", testClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pom, err := om.ManagePartition("my_topic", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if offset, metadata := pom.NextOffset(); offset != 5 || metadata != "meta" {
+		t.Error("Expected the offset of the store, got", offset, metadata)
+	}
+
+	store.err = ErrOutOfBrokers
+	pom.MarkOffset(100, "modified_meta")
+	if err := pom.Commit(); err != ErrOutOfBrokers {
+		t.Error("Expected the error of the store, got", err)
+	}
+
+	store.err = nil
+	if err := om.Commit(); err != nil {
+		t.Error(err)
+	}
+	if store.offsets[0] != 100 {
+		t.Error("Expected the offset to be committed to the store, got", store.offsets[0])
+	}
+	if history := broker.History(); len(history) != 1 {
+		t.Error("Expected only the metadata to be requested, got", history)
+	}
+
+	safeClose(t, pom)
+	safeClose(t, om)
+	safeClose(t, testClient)
+	broker.Close()
+}
@@ -11,19 +11,26 @@ type packetEncoder interface {
 	putInt16(in int16)
 	putInt32(in int32)
 	putInt64(in int64)
+	putFloat64(in float64)
 	putVarint(in int64)
+	putUVarint(in uint64)
 	putArrayLength(in int) error
+	putCompactArrayLength(in int)
 	putBool(in bool)
 
 	// Collections
 	putBytes(in []byte) error
 	putVarintBytes(in []byte) error
 	putRawBytes(in []byte) error
+	putCompactBytes(in []byte) error
 	putString(in string) error
 	putNullableString(in *string) error
+	putCompactString(in string) error
+	putNullableCompactString(in *string) error
 	putStringArray(in []string) error
 	putInt32Array(in []int32) error
 	putInt64Array(in []int64) error
+	putEmptyTaggedFieldArray()
 
 	// Provide the current offset to record the batch size metric
 	offset() int
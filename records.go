@@ -98,6 +98,17 @@ func (r *Records) decode(pd packetDecoder) error {
 	return fmt.Errorf("unknown records type: %v", r.recordsType)
 }
 
+// DecodeRecords decodes buf, which must hold either a single record batch or a
+// legacy message set, in the format they are stored in log segments. The
+// records are decompressed and the CRC of the batch is verified.
+func DecodeRecords(buf []byte) (*Records, error) {
+	r := &Records{}
+	if err := decode(buf, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
 func (r *Records) numRecords() (int, error) {
 	if r.recordsType == unknownRecords {
 		if empty, err := r.setTypeFromFields(); err != nil || empty {
@@ -142,3 +142,63 @@ func TestDefaultRecords(t *testing.T) {
 		t.Errorf("RecordBatch shouldn't be a control batch")
 	}
 }
+
+func TestDecodeRecords(t *testing.T) {
+	batch := &RecordBatch{
+		Version: 2,
+		Codec:   CompressionGZIP,
+		Records: []*Record{
+			{
+				Key:   []byte{1},
+				Value: []byte{2, 3},
+			},
+		},
+	}
+	buf, err := encode(batch, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := DecodeRecords(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.RecordBatch == nil || r.MsgSet != nil {
+		t.Fatalf("Expected a record batch, got %#+v", r)
+	}
+	if len(r.RecordBatch.Records) != 1 || !bytes.Equal(r.RecordBatch.Records[0].Value, []byte{2, 3}) {
+		t.Errorf("Wrong records decoded: %#+v", r.RecordBatch.Records)
+	}
+
+	buf[len(buf)-1]++
+	if _, err := DecodeRecords(buf); err == nil {
+		t.Error("Expected an error decoding a batch with an invalid CRC")
+	}
+
+	set := &MessageSet{
+		Messages: []*MessageBlock{
+			{
+				Offset: 5,
+				Msg: &Message{
+					Version: 1,
+					Value:   []byte{4},
+				},
+			},
+		},
+	}
+	buf, err = encode(set, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = DecodeRecords(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.MsgSet == nil || r.RecordBatch != nil {
+		t.Fatalf("Expected a message set, got %#+v", r)
+	}
+	if len(r.MsgSet.Messages) != 1 || r.MsgSet.Messages[0].Offset != 5 {
+		t.Errorf("Wrong messages decoded: %#+v", r.MsgSet.Messages)
+	}
+}
@@ -0,0 +1,51 @@
+package sarama
+
+import "time"
+
+// RenewDelegationTokenRequest is a request to extend the expiry time of a
+// delegation token, within its maximum lifetime.
+type RenewDelegationTokenRequest struct {
+	Version int16
+	HMAC    []byte
+
+	// The extension of the expiry time from now, -1 (or -1ms) for the
+	// delegation.token.expiry.time.ms of the brokers.
+	RenewPeriod time.Duration
+}
+
+func (r *RenewDelegationTokenRequest) encode(pe packetEncoder) error {
+	if err := pe.putBytes(r.HMAC); err != nil {
+		return err
+	}
+	pe.putInt64(durationToMillis(r.RenewPeriod))
+	return nil
+}
+
+func (r *RenewDelegationTokenRequest) decode(pd packetDecoder, version int16) (err error) {
+	r.Version = version
+	if r.HMAC, err = pd.getBytes(); err != nil {
+		return err
+	}
+
+	renewPeriod, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	r.RenewPeriod = time.Duration(renewPeriod) * time.Millisecond
+	return nil
+}
+
+func (r *RenewDelegationTokenRequest) key() int16 {
+	return 39
+}
+
+func (r *RenewDelegationTokenRequest) version() int16 {
+	return r.Version
+}
+
+func (r *RenewDelegationTokenRequest) requiredVersion() KafkaVersion {
+	if r.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
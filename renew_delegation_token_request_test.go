@@ -0,0 +1,20 @@
+package sarama
+
+import (
+	"testing"
+	"time"
+)
+
+var renewDelegationTokenRequest = []byte{
+	0, 0, 0, 3, 1, 2, 3, // hmac
+	0, 0, 0, 0, 0, 54, 238, 128, // renew period: 1 hour
+}
+
+func TestRenewDelegationTokenRequest(t *testing.T) {
+	request := &RenewDelegationTokenRequest{
+		Version:     1,
+		HMAC:        []byte{1, 2, 3},
+		RenewPeriod: time.Hour,
+	}
+	testRequest(t, "renew", request, renewDelegationTokenRequest)
+}
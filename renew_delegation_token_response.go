@@ -0,0 +1,57 @@
+package sarama
+
+import "time"
+
+// RenewDelegationTokenResponse holds the new expiry time of the renewed
+// delegation token.
+type RenewDelegationTokenResponse struct {
+	Version      int16
+	ErrorCode    KError
+	ExpiryTime   time.Time
+	ThrottleTime time.Duration
+}
+
+func (r *RenewDelegationTokenResponse) encode(pe packetEncoder) error {
+	pe.putInt16(int16(r.ErrorCode))
+	pe.putInt64(timeToMillis(r.ExpiryTime))
+	pe.putInt32(int32(r.ThrottleTime / time.Millisecond))
+	return nil
+}
+
+func (r *RenewDelegationTokenResponse) decode(pd packetDecoder, version int16) error {
+	r.Version = version
+
+	kerr, err := pd.getInt16()
+	if err != nil {
+		return err
+	}
+	r.ErrorCode = KError(kerr)
+
+	expiryTime, err := pd.getInt64()
+	if err != nil {
+		return err
+	}
+	r.ExpiryTime = millisToTime(expiryTime)
+
+	throttleTime, err := pd.getInt32()
+	if err != nil {
+		return err
+	}
+	r.ThrottleTime = time.Duration(throttleTime) * time.Millisecond
+	return nil
+}
+
+func (r *RenewDelegationTokenResponse) key() int16 {
+	return 39
+}
+
+func (r *RenewDelegationTokenResponse) version() int16 {
+	return r.Version
+}
+
+func (r *RenewDelegationTokenResponse) requiredVersion() KafkaVersion {
+	if r.Version >= 1 {
+		return V2_0_0_0
+	}
+	return V1_1_0_0
+}
@@ -0,0 +1,17 @@
+package sarama
+
+import "testing"
+
+var renewDelegationTokenResponse = []byte{
+	0, 62, // ErrDelegationTokenNotFound
+	0, 0, 0, 0, 0, 0, 7, 208, // expiry time: 2000
+	0, 0, 0, 0, // throttle time
+}
+
+func TestRenewDelegationTokenResponse(t *testing.T) {
+	response := &RenewDelegationTokenResponse{
+		ErrorCode:  ErrDelegationTokenNotFound,
+		ExpiryTime: millisToTime(2000),
+	}
+	testResponse(t, "renew", response, renewDelegationTokenResponse)
+}
@@ -14,6 +14,22 @@ type protocolBody interface {
 	requiredVersion() KafkaVersion
 }
 
+// flexibleBody is implemented by the requests and responses using the flexible
+// versions of KIP-482, whose headers end with tagged fields: version 2 of the
+// request header and version 1 of the response header.
+type flexibleBody interface {
+	headerVersion() int16
+}
+
+// headerVersion returns the version of the header used along with body,
+// 1 for requests and 0 for responses unless body says otherwise.
+func headerVersion(body interface{}, defaultVersion int16) int16 {
+	if flexible, ok := body.(flexibleBody); ok {
+		return flexible.headerVersion()
+	}
+	return defaultVersion
+}
+
 type request struct {
 	correlationID int32
 	clientID      string
@@ -31,6 +47,10 @@ func (r *request) encode(pe packetEncoder) error {
 		return err
 	}
 
+	if headerVersion(r.body, 1) >= 2 {
+		pe.putEmptyTaggedFieldArray()
+	}
+
 	err = r.body.encode(pe)
 	if err != nil {
 		return err
@@ -65,6 +85,12 @@ func (r *request) decode(pd packetDecoder) (err error) {
 		return PacketDecodingError{fmt.Sprintf("unknown request key (%d)", key)}
 	}
 
+	if headerVersion(r.body, 1) >= 2 {
+		if _, err := pd.getEmptyTaggedFieldArray(); err != nil {
+			return err
+		}
+	}
+
 	return r.body.decode(pd, version)
 }
 
@@ -140,6 +166,8 @@ func allocateBody(key, version int16) protocolBody {
 		return &DeleteRecordsRequest{}
 	case 22:
 		return &InitProducerIDRequest{}
+	case 23:
+		return &OffsetForLeaderEpochRequest{}
 	case 24:
 		return &AddPartitionsToTxnRequest{}
 	case 25:
@@ -158,12 +186,38 @@ func allocateBody(key, version int16) protocolBody {
 		return &DescribeConfigsRequest{}
 	case 33:
 		return &AlterConfigsRequest{}
+	case 34:
+		return &AlterReplicaLogDirsRequest{}
+	case 35:
+		return &DescribeLogDirsRequest{}
 	case 36:
-		return &SaslAuthenticateRequest{}
+		return &SaslAuthenticateRequest{Version: version}
 	case 37:
 		return &CreatePartitionsRequest{}
+	case 38:
+		return &CreateDelegationTokenRequest{Version: version}
+	case 39:
+		return &RenewDelegationTokenRequest{Version: version}
+	case 40:
+		return &ExpireDelegationTokenRequest{Version: version}
+	case 41:
+		return &DescribeDelegationTokenRequest{Version: version}
 	case 42:
 		return &DeleteGroupsRequest{}
+	case 43:
+		return &ElectLeadersRequest{}
+	case 44:
+		return &IncrementalAlterConfigsRequest{}
+	case 47:
+		return &DeleteOffsetsRequest{}
+	case 48:
+		return &DescribeClientQuotasRequest{}
+	case 49:
+		return &AlterClientQuotasRequest{}
+	case 50:
+		return &DescribeUserScramCredentialsRequest{Version: version}
+	case 51:
+		return &AlterUserScramCredentialsRequest{Version: version}
 	}
 	return nil
 }
@@ -22,3 +22,13 @@ func (r *responseHeader) decode(pd packetDecoder) (err error) {
 	r.correlationID, err = pd.getInt32()
 	return err
 }
+
+// skipResponseHeaderTaggedFields returns the body of a flexible response, which
+// follows the tagged fields ending version 1 of the response header.
+func skipResponseHeaderTaggedFields(buf []byte) ([]byte, error) {
+	helper := realDecoder{raw: buf}
+	if _, err := helper.getEmptyTaggedFieldArray(); err != nil {
+		return nil, err
+	}
+	return buf[helper.off:], nil
+}
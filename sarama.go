@@ -39,6 +39,9 @@ Broker related metrics:
 	| response-rate-for-broker-<broker-id>         | meter      | Responses/second received from a given broker                 |
 	| response-size                                | histogram  | Distribution of the response size in bytes for all brokers    |
 	| response-size-for-broker-<broker-id>         | histogram  | Distribution of the response size in bytes for a given broker |
+	| connection-creation-rate                     | meter      | Connections/second opened to all brokers                      |
+	| connection-close-rate                        | meter      | Connections/second closed to all brokers                      |
+	| connection-count                             | counter    | Number of connections currently open to all brokers           |
 	+----------------------------------------------+------------+---------------------------------------------------------------+
 
 Note that we do not gather specific metrics for seed brokers but they are part of the "all brokers" metrics.
@@ -8,6 +8,13 @@ var (
 		0, 3, 'e', 'r', 'r',
 		0, 0, 0, 3, 'm', 's', 'g',
 	}
+
+	saslAuthenticatResponseV1 = []byte{
+		0, 0,
+		255, 255,
+		0, 0, 0, 3, 'm', 's', 'g',
+		0, 0, 0, 0, 0, 0, 0x0e, 0x10,
+	}
 )
 
 func TestSaslAuthenticateResponse(t *testing.T) {
@@ -20,3 +27,12 @@ func TestSaslAuthenticateResponse(t *testing.T) {
 
 	testResponse(t, "authenticate reponse", response, saslAuthenticatResponseErr)
 }
+
+func TestSaslAuthenticateResponseV1(t *testing.T) {
+	response := new(SaslAuthenticateResponse)
+	response.Version = 1
+	response.SaslAuthBytes = []byte(`msg`)
+	response.SessionLifetimeMs = 3600
+
+	testResponse(t, "authenticate response with session lifetime", response, saslAuthenticatResponseV1)
+}
@@ -0,0 +1,108 @@
+package sarama
+
+import "fmt"
+
+// plainMechanism is the SASL/PLAIN mechanism (RFC 4616) wrapped in the
+// SaslAuthenticate request, the broker replying with an error when the
+// credentials are invalid
+type plainMechanism struct {
+	user     string
+	password string
+	done     bool
+}
+
+func (m *plainMechanism) Name() string {
+	return SASLTypePlaintext
+}
+
+func (m *plainMechanism) Start() ([]byte, error) {
+	m.done = false
+	return []byte("\x00" + m.user + "\x00" + m.password), nil
+}
+
+func (m *plainMechanism) Step(challenge []byte) ([]byte, error) {
+	m.done = true
+	return nil, nil
+}
+
+func (m *plainMechanism) Done() bool {
+	return m.done
+}
+
+// oauthBearerMechanism is the SASL/OAUTHBEARER mechanism as described by
+// KIP-255, a token being asked for to the provider on every authentication
+type oauthBearerMechanism struct {
+	provider AccessTokenProvider
+	done     bool
+}
+
+func (m *oauthBearerMechanism) Name() string {
+	return SASLTypeOAuth
+}
+
+func (m *oauthBearerMechanism) Start() ([]byte, error) {
+	m.done = false
+	token, err := m.provider.Token()
+	if err != nil {
+		return nil, err
+	}
+	return buildClientInitialResponse(token)
+}
+
+func (m *oauthBearerMechanism) Step(challenge []byte) ([]byte, error) {
+	if len(challenge) > 0 {
+		Logger.Printf("Received SASL auth response: %s", challenge)
+	}
+	m.done = true
+	return nil, nil
+}
+
+func (m *oauthBearerMechanism) Done() bool {
+	return m.done
+}
+
+// scramMechanism is the SASL/SCRAM mechanism, the exchange being performed by
+// a new SCRAM client on every authentication
+type scramMechanism struct {
+	name      SASLMechanism
+	newClient func() (SCRAMClient, error)
+	user      string
+	password  string
+	authzID   string
+	client    SCRAMClient
+}
+
+func (m *scramMechanism) Name() string {
+	return string(m.name)
+}
+
+func (m *scramMechanism) Start() ([]byte, error) {
+	client, err := m.newClient()
+	if err != nil {
+		return nil, err
+	}
+	m.client = client
+
+	if err := m.client.Begin(m.user, m.password, m.authzID); err != nil {
+		return nil, fmt.Errorf("failed to start SCRAM exchange with the server: %s", err.Error())
+	}
+
+	msg, err := m.client.Step("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to advance the SCRAM exchange: %s", err.Error())
+	}
+	return []byte(msg), nil
+}
+
+func (m *scramMechanism) Step(challenge []byte) ([]byte, error) {
+	msg, err := m.client.Step(string(challenge))
+	if err != nil {
+		Logger.Println("SASL authentication failed", err)
+		return nil, err
+	}
+	return []byte(msg), nil
+}
+
+func (m *scramMechanism) Done() bool {
+	return m.client != nil && m.client.Done()
+}
@@ -0,0 +1,168 @@
+package sarama
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// scramClient is the SCRAM client (RFC 5802) sarama uses for the exchanges
+// a SCRAMClient provided through Net.SASL.SCRAMClientGeneratorFunc cannot
+// perform, namely the ones sending extensions such as tokenauth=true in the
+// client-first message.
+type scramClient struct {
+	hash       func() hash.Hash
+	extensions map[string]string
+
+	// the exchange so far
+	step            int
+	gs2Header       string
+	clientFirstBare string
+	nonce           string
+	password        string
+	serverSignature []byte
+}
+
+func newSCRAMClient(mechanism SASLMechanism, extensions map[string]string) (*scramClient, error) {
+	client := &scramClient{extensions: extensions}
+	switch mechanism {
+	case SASLTypeSCRAMSHA256:
+		client.hash = sha256.New
+	case SASLTypeSCRAMSHA512:
+		client.hash = sha512.New
+	default:
+		return nil, fmt.Errorf("unsupported SCRAM mechanism %s", mechanism)
+	}
+	return client, nil
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	nonce := make([]byte, 24)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	c.nonce = base64.RawStdEncoding.EncodeToString(nonce)
+	c.password = password
+
+	c.gs2Header = "n,,"
+	if authzID != "" {
+		c.gs2Header = "n,a=" + scramEscape(authzID) + ","
+	}
+
+	c.clientFirstBare = "n=" + scramEscape(userName) + ",r=" + c.nonce
+	keys := make([]string, 0, len(c.extensions))
+	for key := range c.extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		c.clientFirstBare += "," + key + "=" + c.extensions[key]
+	}
+
+	c.step = 0
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	c.step++
+	switch c.step {
+	case 1:
+		return c.gs2Header + c.clientFirstBare, nil
+	case 2:
+		return c.clientFinal(challenge)
+	case 3:
+		return "", c.verifyServerFinal(challenge)
+	default:
+		return "", errors.New("SCRAM exchange is already over")
+	}
+}
+
+func (c *scramClient) Done() bool {
+	return c.step >= 3
+}
+
+func (c *scramClient) clientFinal(serverFirst string) (string, error) {
+	var (
+		nonce      string
+		salt       []byte
+		iterations int
+		err        error
+	)
+	for _, field := range strings.Split(serverFirst, ",") {
+		if len(field) < 2 || field[1] != '=' {
+			continue
+		}
+		switch value := field[2:]; field[0] {
+		case 'r':
+			nonce = value
+		case 's':
+			if salt, err = base64.StdEncoding.DecodeString(value); err != nil {
+				return "", fmt.Errorf("invalid SCRAM salt: %s", err)
+			}
+		case 'i':
+			if iterations, err = strconv.Atoi(value); err != nil {
+				return "", fmt.Errorf("invalid SCRAM iteration count: %s", err)
+			}
+		case 'e':
+			return "", fmt.Errorf("SCRAM server error: %s", value)
+		}
+	}
+	if !strings.HasPrefix(nonce, c.nonce) || len(nonce) == len(c.nonce) {
+		return "", errors.New("invalid SCRAM server nonce")
+	}
+	if len(salt) == 0 || iterations <= 0 {
+		return "", errors.New("invalid SCRAM server-first message")
+	}
+
+	saltedPassword := pbkdf2.Key([]byte(c.password), salt, iterations, c.hash().Size(), c.hash)
+	clientKey := c.hmac(saltedPassword, "Client Key")
+	storedKey := c.hash()
+	storedKey.Write(clientKey)
+
+	clientFinalWithoutProof := "c=" + base64.StdEncoding.EncodeToString([]byte(c.gs2Header)) + ",r=" + nonce
+	authMessage := c.clientFirstBare + "," + serverFirst + "," + clientFinalWithoutProof
+
+	proof := c.hmac(storedKey.Sum(nil), authMessage)
+	for i := range proof {
+		proof[i] ^= clientKey[i]
+	}
+	c.serverSignature = c.hmac(c.hmac(saltedPassword, "Server Key"), authMessage)
+
+	return clientFinalWithoutProof + ",p=" + base64.StdEncoding.EncodeToString(proof), nil
+}
+
+func (c *scramClient) verifyServerFinal(serverFinal string) error {
+	switch {
+	case strings.HasPrefix(serverFinal, "e="):
+		return fmt.Errorf("SCRAM server error: %s", serverFinal[2:])
+	case strings.HasPrefix(serverFinal, "v="):
+		signature, err := base64.StdEncoding.DecodeString(strings.SplitN(serverFinal[2:], ",", 2)[0])
+		if err != nil || !hmac.Equal(signature, c.serverSignature) {
+			return errors.New("invalid SCRAM server signature")
+		}
+		return nil
+	default:
+		return errors.New("invalid SCRAM server-final message")
+	}
+}
+
+func (c *scramClient) hmac(key []byte, message string) []byte {
+	mac := hmac.New(c.hash, key)
+	mac.Write([]byte(message))
+	return mac.Sum(nil)
+}
+
+// scramEscape escapes the user names as required by the SCRAM messages.
+func scramEscape(name string) string {
+	return strings.NewReplacer("=", "=3D", ",", "=2C").Replace(name)
+}
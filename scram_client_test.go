@@ -0,0 +1,172 @@
+package sarama
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/rcrowley/go-metrics"
+	"github.com/xdg/scram"
+)
+
+// runSCRAMExchange drives client against an independent SCRAM server holding
+// the credentials of user, and returns the client-first message.
+func runSCRAMExchange(t *testing.T, client SCRAMClient, hash scram.HashGeneratorFcn, user, password, clientPassword string) (string, error) {
+	credentials, err := hash.NewClient(user, password, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := credentials.GetStoredCredentials(scram.KeyFactors{Salt: "some-salt", Iters: 4096})
+	server, err := hash.NewServer(func(name string) (scram.StoredCredentials, error) {
+		if name != user {
+			t.Errorf("Unexpected user %q", name)
+		}
+		return stored, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conversation := server.NewConversation()
+
+	if err := client.Begin(user, clientPassword, ""); err != nil {
+		t.Fatal(err)
+	}
+	clientFirst, err := client.Step("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := clientFirst
+	for !client.Done() {
+		challenge, err := conversation.Step(msg)
+		if err != nil && !conversation.Done() {
+			return clientFirst, err
+		}
+		if msg, err = client.Step(challenge); err != nil {
+			return clientFirst, err
+		}
+	}
+	if !conversation.Valid() {
+		t.Error("Expected the server to authenticate the client")
+	}
+	return clientFirst, nil
+}
+
+func TestSCRAMClientTokenAuth(t *testing.T) {
+	hmac := base64.StdEncoding.EncodeToString([]byte{1, 2, 3, 4})
+
+	for mechanism, hash := range map[SASLMechanism]scram.HashGeneratorFcn{
+		SASLTypeSCRAMSHA256: scram.SHA256,
+		SASLTypeSCRAMSHA512: sha512.New,
+	} {
+		client, err := newSCRAMClient(mechanism, map[string]string{"tokenauth": "true"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		clientFirst, err := runSCRAMExchange(t, client, hash, "token-id", hmac, hmac)
+		if err != nil {
+			t.Errorf("%s exchange failed: %s", mechanism, err)
+		}
+		if !strings.HasPrefix(clientFirst, "n,,n=token-id,r=") || !strings.HasSuffix(clientFirst, ",tokenauth=true") {
+			t.Errorf("Unexpected client-first message %q", clientFirst)
+		}
+	}
+}
+
+func TestSCRAMClientWrongPassword(t *testing.T) {
+	client, err := newSCRAMClient(SASLTypeSCRAMSHA256, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runSCRAMExchange(t, client, scram.SHA256, "user", "password", "wrong"); err == nil {
+		t.Error("Expected the exchange to fail with a wrong password")
+	}
+}
+
+func TestSCRAMClientUnknownMechanism(t *testing.T) {
+	if _, err := newSCRAMClient(SASLTypePlaintext, nil); err == nil {
+		t.Error("Expected an error for a mechanism other than SCRAM")
+	}
+}
+
+// mockSCRAMServer answers the SaslAuthenticateRequests of a SCRAM exchange
+// with the given credentials.
+type mockSCRAMServer struct {
+	t            *testing.T
+	conversation *scram.ServerConversation
+	clientFirst  string
+}
+
+func newMockSCRAMServer(t *testing.T, user, password string) *mockSCRAMServer {
+	credentials, err := scram.SHA256.NewClient(user, password, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := credentials.GetStoredCredentials(scram.KeyFactors{Salt: "some-salt", Iters: 4096})
+	server, err := scram.SHA256.NewServer(func(name string) (scram.StoredCredentials, error) {
+		return stored, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &mockSCRAMServer{t: t, conversation: server.NewConversation()}
+}
+
+func (m *mockSCRAMServer) For(reqBody versionedDecoder) encoder {
+	msg := string(reqBody.(*SaslAuthenticateRequest).SaslAuthBytes)
+	if m.clientFirst == "" {
+		m.clientFirst = msg
+	}
+	challenge, err := m.conversation.Step(msg)
+	if err != nil {
+		return &SaslAuthenticateResponse{Err: ErrSASLAuthenticationFailed}
+	}
+	return &SaslAuthenticateResponse{SaslAuthBytes: []byte(challenge)}
+}
+
+func TestSASLSCRAMDelegationToken(t *testing.T) {
+	token := &DelegationToken{TokenID: "token-id", HMAC: []byte{1, 2, 3, 4}}
+	server := newMockSCRAMServer(t, token.TokenID, base64.StdEncoding.EncodeToString(token.HMAC))
+
+	mockBroker := NewMockBroker(t, 0)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"SaslAuthenticateRequest": server,
+		"SaslHandshakeRequest":    NewMockSaslHandshakeResponse(t).SetEnabledMechanisms([]string{SASLTypeSCRAMSHA256}),
+	})
+
+	conf := NewConfig()
+	conf.Net.SASL.Mechanism = SASLTypeSCRAMSHA256
+	conf.Net.SASL.DelegationToken = token
+
+	broker := NewBroker(mockBroker.Addr())
+	broker.conf = conf
+	broker.requestRate = metrics.NilMeter{}
+	broker.outgoingByteRate = metrics.NilMeter{}
+	broker.incomingByteRate = metrics.NilMeter{}
+	broker.requestSize = metrics.NilHistogram{}
+	broker.responseSize = metrics.NilHistogram{}
+	broker.responseRate = metrics.NilMeter{}
+	broker.requestLatency = metrics.NilHistogram{}
+
+	conn, err := net.Dial("tcp", mockBroker.Addr())
+	if err != nil {
+		t.Fatal(err)
+	}
+	broker.conn = conn
+	defer conn.Close()
+
+	if err := broker.authenticateViaSASL(); err != nil {
+		t.Fatal(err)
+	}
+	if !server.conversation.Valid() {
+		t.Error("Expected the server to authenticate the token")
+	}
+	if !strings.HasSuffix(server.clientFirst, ",tokenauth=true") {
+		t.Errorf("Expected the tokenauth extension, got %q", server.clientFirst)
+	}
+}
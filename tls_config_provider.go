@@ -0,0 +1,211 @@
+package sarama
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+)
+
+// TLSConfigProvider provides the TLS configuration of the broker connections.
+// It is asked for one every time a connection is opened, which allows rotating
+// the credentials without restarting the client.
+type TLSConfigProvider interface {
+	// TLSConfig returns the TLS configuration to open a new connection with.
+	TLSConfig() (*tls.Config, error)
+}
+
+// defaultTLSReloadInterval is how often FileTLSConfigProvider checks its files
+// for changes when no interval is given
+const defaultTLSReloadInterval = time.Minute
+
+// FileTLSConfigProvider is a TLSConfigProvider loading the PEM encoded client
+// certificate and key, and CA bundle, from files. The files are checked for
+// changes periodically and the credentials reloaded when they change, so that
+// the connections opened from then on use the new ones.
+type FileTLSConfigProvider struct {
+	certFile string
+	keyFile  string
+	caFile   string
+	base     *tls.Config
+
+	lock    sync.RWMutex
+	config  *tls.Config
+	modTime map[string]time.Time
+
+	closer chan none
+	closed sync.Once
+}
+
+// NewFileTLSConfigProvider loads the client certificate and key, and the CA
+// bundle, from the given files and watches them for changes every interval
+// (defaults to 1 minute when 0). The certificate and key files must be both set
+// or both empty; without a CA file the system roots are used. The credentials
+// are set on a copy of base, which may be nil. Close must be called to stop
+// watching the files.
+func NewFileTLSConfigProvider(certFile, keyFile, caFile string, base *tls.Config, interval time.Duration) (*FileTLSConfigProvider, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, ConfigurationError("the certificate and key files must be set together")
+	}
+	if interval < 0 {
+		return nil, ConfigurationError("the reload interval must be >= 0")
+	}
+	if interval == 0 {
+		interval = defaultTLSReloadInterval
+	}
+	if base == nil {
+		base = &tls.Config{}
+	}
+
+	p := &FileTLSConfigProvider{
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+		base:     base,
+		closer:   make(chan none),
+	}
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+
+	go withRecover(func() { p.watch(interval) })
+	return p, nil
+}
+
+// TLSConfig returns a copy of the TLS configuration with the credentials last
+// loaded.
+func (p *FileTLSConfigProvider) TLSConfig() (*tls.Config, error) {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.config.Clone(), nil
+}
+
+// Reload loads the credentials from the files, whether they changed or not. On
+// error the credentials previously loaded are kept.
+func (p *FileTLSConfigProvider) Reload() error {
+	modTime, err := p.modTimes()
+	if err != nil {
+		return err
+	}
+
+	config := p.base.Clone()
+	if p.certFile != "" {
+		cert, err := tls.LoadX509KeyPair(p.certFile, p.keyFile)
+		if err != nil {
+			return err
+		}
+		// parsed once here so the connections can tell when it expires
+		if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+			return err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if p.caFile != "" {
+		pem, err := ioutil.ReadFile(p.caFile)
+		if err != nil {
+			return err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no CA certificate found in %s", p.caFile)
+		}
+		config.RootCAs = pool
+	}
+
+	p.lock.Lock()
+	p.config = config
+	p.modTime = modTime
+	p.lock.Unlock()
+	return nil
+}
+
+// Close stops watching the files for changes. The credentials last loaded are
+// still provided.
+func (p *FileTLSConfigProvider) Close() error {
+	p.closed.Do(func() { close(p.closer) })
+	return nil
+}
+
+func (p *FileTLSConfigProvider) watch(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			changed, err := p.changed()
+			if err != nil {
+				Logger.Printf("tls/files failed to check the credentials for changes: %v\n", err)
+				continue
+			}
+			if !changed {
+				continue
+			}
+			// the files may be in the middle of being rotated, in which case they
+			// are retried on the next tick as they are still seen as changed
+			if err := p.Reload(); err != nil {
+				Logger.Printf("tls/files failed to reload the credentials: %v\n", err)
+				continue
+			}
+			Logger.Println("tls/files reloaded the credentials")
+		case <-p.closer:
+			return
+		}
+	}
+}
+
+func (p *FileTLSConfigProvider) changed() (bool, error) {
+	modTime, err := p.modTimes()
+	if err != nil {
+		return false, err
+	}
+
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	for file, t := range modTime {
+		if !t.Equal(p.modTime[file]) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (p *FileTLSConfigProvider) modTimes() (map[string]time.Time, error) {
+	modTime := make(map[string]time.Time)
+	for _, file := range []string{p.certFile, p.keyFile, p.caFile} {
+		if file == "" {
+			continue
+		}
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		modTime[file] = info.ModTime()
+	}
+	return modTime, nil
+}
+
+// clientCertificateExpiry returns when the client certificate of config
+// expires, or the zero time when it is not known up front
+func clientCertificateExpiry(config *tls.Config) (time.Time, error) {
+	if config == nil || len(config.Certificates) == 0 {
+		return time.Time{}, nil
+	}
+
+	cert := config.Certificates[0]
+	if cert.Leaf != nil {
+		return cert.Leaf.NotAfter, nil
+	}
+	if len(cert.Certificate) == 0 {
+		return time.Time{}, errors.New("the client certificate is empty")
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return time.Time{}, err
+	}
+	return leaf.NotAfter, nil
+}
@@ -0,0 +1,208 @@
+package sarama
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type testCertificateAuthority struct {
+	key  *rsa.PrivateKey
+	cert *x509.Certificate
+	der  []byte
+}
+
+func newTestCertificateAuthority(t *testing.T) *testCertificateAuthority {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		Subject:               pkix.Name{CommonName: "ca"},
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCertificateAuthority{key: key, cert: cert, der: der}
+}
+
+// issue returns a certificate signed by the authority, with its key
+func (ca *testCertificateAuthority) issue(t *testing.T, name string, serial int64, notAfter time.Time) (*rsa.PrivateKey, []byte) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		SerialNumber: big.NewInt(serial),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, der
+}
+
+func writePEM(t *testing.T, file, blockType string, der []byte) {
+	if err := ioutil.WriteFile(file, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeTestCredentials(t *testing.T, dir string, key *rsa.PrivateKey, cert []byte) {
+	writePEM(t, filepath.Join(dir, "client.key"), "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(key))
+	writePEM(t, filepath.Join(dir, "client.crt"), "CERTIFICATE", cert)
+}
+
+func TestFileTLSConfigProviderReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sarama-tls")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	ca := newTestCertificateAuthority(t)
+	writePEM(t, filepath.Join(dir, "ca.crt"), "CERTIFICATE", ca.der)
+	key, cert := ca.issue(t, "client", 2, time.Now().Add(time.Hour))
+	writeTestCredentials(t, dir, key, cert)
+
+	provider, err := NewFileTLSConfigProvider(
+		filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key"), filepath.Join(dir, "ca.crt"),
+		&tls.Config{ServerName: "broker"}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer safeClose(t, provider)
+
+	config, err := provider.TLSConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if config.ServerName != "broker" || config.RootCAs == nil {
+		t.Error("expected the base configuration with the CA bundle, got", config)
+	}
+	if serial := config.Certificates[0].Leaf.SerialNumber.Int64(); serial != 2 {
+		t.Fatal("expected the certificate with serial 2, got", serial)
+	}
+
+	// the modification time of the files must change for them to be reloaded
+	key, cert = ca.issue(t, "client", 3, time.Now().Add(time.Hour))
+	writeTestCredentials(t, dir, key, cert)
+	later := time.Now().Add(time.Second)
+	for _, file := range []string{"client.crt", "client.key"} {
+		if err := os.Chtimes(filepath.Join(dir, file), later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		config, _ = provider.TLSConfig()
+		if config.Certificates[0].Leaf.SerialNumber.Int64() == 3 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the certificate with serial 3 to be reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFileTLSConfigProviderInvalid(t *testing.T) {
+	if _, err := NewFileTLSConfigProvider("client.crt", "", "", nil, 0); err == nil {
+		t.Error("expected an error without key file")
+	}
+	if _, err := NewFileTLSConfigProvider("", "", "does-not-exist.crt", nil, 0); err == nil {
+		t.Error("expected an error with a missing CA file")
+	}
+}
+
+func TestTLSCloseBeforeExpiry(t *testing.T) {
+	ca := newTestCertificateAuthority(t)
+	hostKey, hostCert := ca.issue(t, "host", 2, time.Now().Add(time.Hour))
+	clientKey, clientCert := ca.issue(t, "client", 3, time.Now().Add(time.Hour))
+
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{{Certificate: [][]byte{hostCert}, PrivateKey: hostKey}},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    pool,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mockBroker := NewMockBrokerListener(t, 1, listener)
+	defer mockBroker.Close()
+	mockBroker.SetHandlerByMap(map[string]MockResponse{
+		"MetadataRequest": NewMockMetadataResponse(t),
+	})
+
+	conf := NewConfig()
+	conf.Net.TLS.Enable = true
+	conf.Net.TLS.Config = &tls.Config{
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{{Certificate: [][]byte{clientCert}, PrivateKey: clientKey}},
+	}
+	// close a bit less than the lifetime of the certificate before it expires
+	leaf, err := x509.ParseCertificate(clientCert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf.Net.TLS.CloseBeforeExpiry = time.Until(leaf.NotAfter) - 500*time.Millisecond
+
+	broker := NewBroker(mockBroker.Addr())
+	if err := broker.Open(conf); err != nil {
+		t.Fatal(err)
+	}
+	if connected, err := broker.Connected(); !connected || err != nil {
+		t.Fatal("expected the broker to be connected, got", err)
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if connected, _ := broker.Connected(); !connected {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected the connection to be closed before the certificate expires")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// the connection is reopened with the rotated certificate on next use
+	clientKey, clientCert = ca.issue(t, "client", 4, time.Now().Add(2*time.Hour))
+	conf.Net.TLS.Config.Certificates = []tls.Certificate{{Certificate: [][]byte{clientCert}, PrivateKey: clientKey}}
+	if _, err := broker.GetMetadata(&MetadataRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	if connected, err := broker.Connected(); !connected || err != nil {
+		t.Fatal("expected the broker to be reconnected, got", err)
+	}
+	safeClose(t, broker)
+}
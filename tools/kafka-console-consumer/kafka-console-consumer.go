@@ -1,29 +1,46 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"os/signal"
+	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"text/template"
+	"time"
 
 	"github.com/Shopify/sarama"
 	"github.com/Shopify/sarama/tools/tls"
 )
 
 var (
-	brokerList    = flag.String("brokers", os.Getenv("KAFKA_PEERS"), "The comma separated list of brokers in the Kafka cluster")
-	topic         = flag.String("topic", "", "REQUIRED: the topic to consume")
-	partitions    = flag.String("partitions", "all", "The partitions to consume, can be 'all' or comma-separated numbers")
-	offset        = flag.String("offset", "newest", "The offset to start with. Can be `oldest`, `newest`")
-	verbose       = flag.Bool("verbose", false, "Whether to turn on sarama logging")
-	tlsEnabled    = flag.Bool("tls-enabled", false, "Whether to enable TLS")
-	tlsSkipVerify = flag.Bool("tls-skip-verify", false, "Whether skip TLS server cert verification")
-	tlsClientCert = flag.String("tls-client-cert", "", "Client cert for client authentication (use with -tls-enabled and -tls-client-key)")
-	tlsClientKey  = flag.String("tls-client-key", "", "Client key for client authentication (use with tls-enabled and -tls-client-cert)")
+	brokerList     = flag.String("brokers", os.Getenv("KAFKA_PEERS"), "The comma separated list of brokers in the Kafka cluster")
+	topic          = flag.String("topic", "", "The topic to consume, or a comma separated list of topics. Either -topic or -topic-regex is required")
+	topicRegex     = flag.String("topic-regex", "", "A regular expression; every topic matching it is consumed")
+	partitions     = flag.String("partitions", "all", "The partitions to consume, can be 'all' or comma-separated numbers. Ignored with -group")
+	offset         = flag.String("offset", "newest", "The offset to start with. Can be `oldest`, `newest`, or an RFC3339 timestamp such as 2006-01-02T15:04:05Z")
+	group          = flag.String("group", "", "Consume as a member of this consumer group instead of consuming partitions directly")
+	maxMessages    = flag.Int("max-messages", 0, "Exit after printing this many messages (0 means no limit)")
+	output         = flag.String("output", "text", "The output format. Can be `text`, `json` (one JSON object per line) or `template`")
+	outputTemplate = flag.String("template", "{{.Value}}\n", "The Go template used to print each message with -output=template")
+	printKey       = flag.Bool("print-key", true, "Whether to print message keys (text output)")
+	printPartition = flag.Bool("print-partition", true, "Whether to print message partitions and offsets (text output)")
+	printTopic     = flag.Bool("print-topic", false, "Whether to print message topics (text output)")
+	printTimestamp = flag.Bool("print-timestamp", false, "Whether to print message timestamps (text output)")
+	printHeaders   = flag.Bool("print-headers", false, "Whether to print message headers (text output)")
+	version        = flag.String("version", "", "The assumed version of Kafka, sarama's default when not set. Headers require 0.11.0 or later and -group requires 0.10.2 or later")
+	verbose        = flag.Bool("verbose", false, "Whether to turn on sarama logging")
+	tlsEnabled     = flag.Bool("tls-enabled", false, "Whether to enable TLS")
+	tlsSkipVerify  = flag.Bool("tls-skip-verify", false, "Whether skip TLS server cert verification")
+	tlsClientCert  = flag.String("tls-client-cert", "", "Client cert for client authentication (use with -tls-enabled and -tls-client-key)")
+	tlsClientKey   = flag.String("tls-client-key", "", "Client key for client authentication (use with tls-enabled and -tls-client-cert)")
 
 	bufferSize = flag.Int("buffer-size", 256, "The buffer size of the message channel.")
 
@@ -37,25 +54,49 @@ func main() {
 		printUsageErrorAndExit("You have to provide -brokers as a comma-separated list, or set the KAFKA_PEERS environment variable.")
 	}
 
-	if *topic == "" {
-		printUsageErrorAndExit("-topic is required")
+	if *topic == "" && *topicRegex == "" {
+		printUsageErrorAndExit("-topic or -topic-regex is required")
 	}
 
 	if *verbose {
 		sarama.Logger = logger
 	}
 
-	var initialOffset int64
+	var (
+		initialOffset int64
+		startTime     time.Time
+		err           error
+	)
 	switch *offset {
 	case "oldest":
 		initialOffset = sarama.OffsetOldest
 	case "newest":
 		initialOffset = sarama.OffsetNewest
 	default:
-		printUsageErrorAndExit("-offset should be `oldest` or `newest`")
+		startTime, err = time.Parse(time.RFC3339, *offset)
+		if err != nil {
+			printUsageErrorAndExit("-offset should be `oldest`, `newest` or an RFC3339 timestamp")
+		}
+		initialOffset = sarama.OffsetOldest
+	}
+
+	p, err := newPrinter()
+	if err != nil {
+		printUsageErrorAndExit("%s", err)
 	}
 
 	config := sarama.NewConfig()
+	if *version != "" {
+		config.Version, err = sarama.ParseKafkaVersion(*version)
+		if err != nil {
+			printUsageErrorAndExit("Unknown -version: %s", *version)
+		}
+	}
+	if *group != "" && !config.Version.IsAtLeast(sarama.V0_10_2_0) {
+		printUsageErrorAndExit("-group requires -version 0.10.2 or later")
+	}
+	config.ChannelBufferSize = *bufferSize
+	config.Consumer.Offsets.Initial = initialOffset
 	if *tlsEnabled {
 		tlsConfig, err := tls.NewConfig(*tlsClientCert, *tlsClientKey)
 		if err != nil {
@@ -67,72 +108,326 @@ func main() {
 		config.Net.TLS.Config.InsecureSkipVerify = *tlsSkipVerify
 	}
 
-	c, err := sarama.NewConsumer(strings.Split(*brokerList, ","), config)
+	client, err := sarama.NewClient(strings.Split(*brokerList, ","), config)
 	if err != nil {
-		printErrorAndExit(69, "Failed to start consumer: %s", err)
+		printErrorAndExit(69, "Failed to create client: %s", err)
 	}
 
-	partitionList, err := getPartitions(c)
+	topics, err := getTopics(client)
 	if err != nil {
-		printErrorAndExit(69, "Failed to get the list of partitions: %s", err)
+		printErrorAndExit(69, "Failed to get the list of topics: %s", err)
+	}
+	if len(topics) == 0 {
+		printErrorAndExit(69, "No topic matches -topic-regex %s", *topicRegex)
 	}
 
-	var (
-		messages = make(chan *sarama.ConsumerMessage, *bufferSize)
-		closing  = make(chan struct{})
-		wg       sync.WaitGroup
-	)
+	ctx, cancel := context.WithCancel(context.Background())
+	p.done = cancel
 
 	go func() {
 		signals := make(chan os.Signal, 1)
 		signal.Notify(signals, os.Kill, os.Interrupt)
 		<-signals
 		logger.Println("Initiating shutdown of consumer...")
-		close(closing)
+		cancel()
 	}()
 
-	for _, partition := range partitionList {
-		pc, err := c.ConsumePartition(*topic, partition, initialOffset)
+	if *group != "" {
+		consumeGroup(ctx, client, topics, startTime, p)
+	} else {
+		consumePartitions(ctx, client, topics, startTime, p)
+	}
+	logger.Println("Done consuming", strings.Join(topics, ", "))
+}
+
+func consumePartitions(ctx context.Context, client sarama.Client, topics []string, startTime time.Time, p *printer) {
+	c, err := sarama.NewConsumerFromClient(client)
+	if err != nil {
+		printErrorAndExit(69, "Failed to start consumer: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	for _, topic := range topics {
+		partitionList, err := getPartitions(c, topic)
 		if err != nil {
-			printErrorAndExit(69, "Failed to start consumer for partition %d: %s", partition, err)
+			printErrorAndExit(69, "Failed to get the list of partitions of %s: %s", topic, err)
 		}
 
-		go func(pc sarama.PartitionConsumer) {
-			<-closing
-			pc.AsyncClose()
-		}(pc)
+		for _, partition := range partitionList {
+			initialOffset := client.Config().Consumer.Offsets.Initial
+			if !startTime.IsZero() {
+				initialOffset, err = client.GetOffset(topic, partition, startTime.UnixNano()/int64(time.Millisecond))
+				if err != nil {
+					printErrorAndExit(69, "Failed to find the offset of %s/%d at %s: %s", topic, partition, *offset, err)
+				}
+				if initialOffset < 0 {
+					// no message at or after startTime yet
+					initialOffset = sarama.OffsetNewest
+				}
+			}
 
-		wg.Add(1)
-		go func(pc sarama.PartitionConsumer) {
-			defer wg.Done()
-			for message := range pc.Messages() {
-				messages <- message
+			pc, err := c.ConsumePartition(topic, partition, initialOffset)
+			if err != nil {
+				printErrorAndExit(69, "Failed to start consumer for partition %s/%d: %s", topic, partition, err)
 			}
-		}(pc)
+
+			go func(pc sarama.PartitionConsumer) {
+				<-ctx.Done()
+				pc.AsyncClose()
+			}(pc)
+
+			wg.Add(1)
+			go func(pc sarama.PartitionConsumer) {
+				defer wg.Done()
+				for message := range pc.Messages() {
+					p.print(message)
+				}
+			}(pc)
+		}
 	}
 
-	go func() {
-		for msg := range messages {
+	wg.Wait()
+
+	if err := c.Close(); err != nil {
+		logger.Println("Failed to close consumer: ", err)
+	}
+	if err := client.Close(); err != nil {
+		logger.Println("Failed to close client: ", err)
+	}
+}
+
+func consumeGroup(ctx context.Context, client sarama.Client, topics []string, startTime time.Time, p *printer) {
+	cg, err := sarama.NewConsumerGroupFromClient(*group, client)
+	if err != nil {
+		printErrorAndExit(69, "Failed to start consumer group: %s", err)
+	}
+
+	handler := &groupHandler{
+		client:    client,
+		printer:   p,
+		startTime: startTime,
+		reset:     make(map[string]map[int32]bool),
+	}
+	for ctx.Err() == nil {
+		if err := cg.Consume(ctx, topics, handler); err != nil {
+			printErrorAndExit(69, "Failed to consume as group %s: %s", *group, err)
+		}
+	}
+
+	// closing the consumer group also closes the client
+	if err := cg.Close(); err != nil {
+		logger.Println("Failed to close consumer group: ", err)
+	}
+}
+
+// groupHandler prints the messages of every claim. When a start time is set,
+// the committed offset of each partition is rewound to that time the first
+// time the partition is claimed, and older messages are skipped.
+type groupHandler struct {
+	client    sarama.Client
+	printer   *printer
+	startTime time.Time
+
+	lock  sync.Mutex
+	reset map[string]map[int32]bool
+}
+
+func (h *groupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	if h.startTime.IsZero() {
+		return nil
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	for topic, partitions := range session.Claims() {
+		if h.reset[topic] == nil {
+			h.reset[topic] = make(map[int32]bool)
+		}
+		for _, partition := range partitions {
+			if h.reset[topic][partition] {
+				continue
+			}
+			offset, err := h.client.GetOffset(topic, partition, h.startTime.UnixNano()/int64(time.Millisecond))
+			if err != nil {
+				return err
+			}
+			if offset >= 0 {
+				session.ResetOffset(topic, partition, offset, "")
+			}
+			h.reset[topic][partition] = true
+		}
+	}
+	return nil
+}
+
+func (h *groupHandler) Cleanup(sarama.ConsumerGroupSession) error {
+	return nil
+}
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for message := range claim.Messages() {
+		if message.Timestamp.Before(h.startTime) {
+			session.MarkMessage(message, "")
+			continue
+		}
+		if !h.printer.print(message) {
+			return nil
+		}
+		session.MarkMessage(message, "")
+	}
+	return nil
+}
+
+// outputMessage is the representation of a message used by the json and
+// template outputs.
+type outputMessage struct {
+	Topic     string            `json:"topic"`
+	Partition int32             `json:"partition"`
+	Offset    int64             `json:"offset"`
+	Timestamp time.Time         `json:"timestamp"`
+	Key       string            `json:"key"`
+	Value     string            `json:"value"`
+	Headers   map[string]string `json:"headers,omitempty"`
+}
+
+// printer serializes the printing of messages coming from several partitions
+// and stops consumption once -max-messages have been printed.
+type printer struct {
+	tmpl *template.Template
+	done func()
+
+	lock    sync.Mutex
+	printed int
+}
+
+func newPrinter() (*printer, error) {
+	p := &printer{}
+	switch *output {
+	case "text", "json":
+	case "template":
+		tmpl, err := template.New("message").Parse(*outputTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -template: %s", err)
+		}
+		p.tmpl = tmpl
+	default:
+		return nil, fmt.Errorf("-output should be `text`, `json` or `template`")
+	}
+	return p, nil
+}
+
+// print prints msg and returns false once -max-messages have been printed.
+func (p *printer) print(msg *sarama.ConsumerMessage) bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if *maxMessages > 0 && p.printed >= *maxMessages {
+		return false
+	}
+
+	switch *output {
+	case "json":
+		line, err := json.Marshal(newOutputMessage(msg))
+		if err != nil {
+			printErrorAndExit(69, "Failed to encode message: %s", err)
+		}
+		fmt.Println(string(line))
+	case "template":
+		if err := p.tmpl.Execute(os.Stdout, newOutputMessage(msg)); err != nil {
+			printErrorAndExit(69, "Failed to execute -template: %s", err)
+		}
+	default:
+		if *printTopic {
+			fmt.Printf("Topic:\t%s\n", msg.Topic)
+		}
+		if *printPartition {
 			fmt.Printf("Partition:\t%d\n", msg.Partition)
 			fmt.Printf("Offset:\t%d\n", msg.Offset)
+		}
+		if *printTimestamp {
+			fmt.Printf("Timestamp:\t%s\n", msg.Timestamp.Format(time.RFC3339Nano))
+		}
+		if *printKey {
 			fmt.Printf("Key:\t%s\n", string(msg.Key))
-			fmt.Printf("Value:\t%s\n", string(msg.Value))
-			fmt.Println()
 		}
-	}()
+		if *printHeaders {
+			for _, header := range msg.Headers {
+				fmt.Printf("Header:\t%s=%s\n", string(header.Key), string(header.Value))
+			}
+		}
+		fmt.Printf("Value:\t%s\n", string(msg.Value))
+		fmt.Println()
+	}
 
-	wg.Wait()
-	logger.Println("Done consuming topic", *topic)
-	close(messages)
+	p.printed++
+	if *maxMessages > 0 && p.printed >= *maxMessages {
+		p.done()
+		return false
+	}
+	return true
+}
 
-	if err := c.Close(); err != nil {
-		logger.Println("Failed to close consumer: ", err)
+func newOutputMessage(msg *sarama.ConsumerMessage) *outputMessage {
+	om := &outputMessage{
+		Topic:     msg.Topic,
+		Partition: msg.Partition,
+		Offset:    msg.Offset,
+		Timestamp: msg.Timestamp,
+		Key:       string(msg.Key),
+		Value:     string(msg.Value),
+	}
+	if len(msg.Headers) > 0 {
+		om.Headers = make(map[string]string, len(msg.Headers))
+		for _, header := range msg.Headers {
+			om.Headers[string(header.Key)] = string(header.Value)
+		}
+	}
+	return om
+}
+
+func getTopics(client sarama.Client) ([]string, error) {
+	var topics []string
+	for _, name := range strings.Split(*topic, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			topics = append(topics, name)
+		}
+	}
+
+	if *topicRegex == "" {
+		return topics, nil
+	}
+
+	re, err := regexp.Compile(*topicRegex)
+	if err != nil {
+		printUsageErrorAndExit("Invalid -topic-regex: %s", err)
+	}
+
+	all, err := client.Topics()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range all {
+		if re.MatchString(name) && !contains(topics, name) {
+			topics = append(topics, name)
+		}
+	}
+	sort.Strings(topics)
+	return topics, nil
+}
+
+func contains(names []string, name string) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
 	}
+	return false
 }
 
-func getPartitions(c sarama.Consumer) ([]int32, error) {
+func getPartitions(c sarama.Consumer, topic string) ([]int32, error) {
 	if *partitions == "all" {
-		return c.Partitions(*topic)
+		return c.Partitions(topic)
 	}
 
 	tmp := strings.Split(*partitions, ",")
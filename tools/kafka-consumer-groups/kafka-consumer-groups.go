@@ -0,0 +1,439 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/tools/tls"
+)
+
+var (
+	brokerList    = flag.String("brokers", os.Getenv("KAFKA_PEERS"), "The comma separated list of brokers in the Kafka cluster")
+	group         = flag.String("group", "", "The consumer group to describe, delete or reset")
+	topics        = flag.String("topic", "", "The topics to reset offsets for, as a comma-separated list. A topic can be restricted to some partitions with topic:0,1,2 (use a semicolon to separate several topics)")
+	list          = flag.Bool("list", false, "List all consumer groups")
+	describe      = flag.Bool("describe", false, "Describe the members, assignments, offsets and lag of -group")
+	deleteGroup   = flag.Bool("delete", false, "Delete -group. The group must not have any active members")
+	resetOffsets  = flag.Bool("reset-offsets", false, "Reset the committed offsets of -group. The group must not have any active members")
+	toEarliest    = flag.Bool("to-earliest", false, "Reset offsets to the earliest available offset (use with -reset-offsets)")
+	toLatest      = flag.Bool("to-latest", false, "Reset offsets to the latest offset (use with -reset-offsets)")
+	toDatetime    = flag.String("to-datetime", "", "Reset offsets to the first offset with a timestamp at or after the given RFC3339 time, e.g. 2006-01-02T15:04:05Z (use with -reset-offsets)")
+	shiftBy       = flag.Int64("shift-by", 0, "Shift the committed offsets by the given amount, which can be negative (use with -reset-offsets)")
+	execute       = flag.Bool("execute", false, "Apply the offset reset. Without it, -reset-offsets only prints the planned offsets (dry run)")
+	version       = flag.String("version", "1.1.0", "The assumed version of Kafka")
+	verbose       = flag.Bool("verbose", false, "Whether to turn on sarama logging")
+	tlsEnabled    = flag.Bool("tls-enabled", false, "Whether to enable TLS")
+	tlsSkipVerify = flag.Bool("tls-skip-verify", false, "Whether skip TLS server cert verification")
+	tlsClientCert = flag.String("tls-client-cert", "", "Client cert for client authentication (use with -tls-enabled and -tls-client-key)")
+	tlsClientKey  = flag.String("tls-client-key", "", "Client key for client authentication (use with tls-enabled and -tls-client-cert)")
+
+	logger = log.New(os.Stderr, "", log.LstdFlags)
+)
+
+func main() {
+	flag.Parse()
+
+	if *brokerList == "" {
+		printUsageErrorAndExit("You have to provide -brokers as a comma-separated list, or set the KAFKA_PEERS environment variable.")
+	}
+
+	actions := 0
+	for _, enabled := range []bool{*list, *describe, *deleteGroup, *resetOffsets} {
+		if enabled {
+			actions++
+		}
+	}
+	if actions != 1 {
+		printUsageErrorAndExit("Exactly one of -list, -describe, -delete or -reset-offsets is required")
+	}
+
+	if !*list && *group == "" {
+		printUsageErrorAndExit("-group is required")
+	}
+
+	if *verbose {
+		sarama.Logger = logger
+	}
+
+	kafkaVersion, err := sarama.ParseKafkaVersion(*version)
+	if err != nil {
+		printUsageErrorAndExit("Unknown -version: %s", *version)
+	}
+
+	config := sarama.NewConfig()
+	config.Version = kafkaVersion
+	if *tlsEnabled {
+		tlsConfig, err := tls.NewConfig(*tlsClientCert, *tlsClientKey)
+		if err != nil {
+			printErrorAndExit(69, "Failed to create TLS config: %s", err)
+		}
+
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+		config.Net.TLS.Config.InsecureSkipVerify = *tlsSkipVerify
+	}
+
+	client, err := sarama.NewClient(strings.Split(*brokerList, ","), config)
+	if err != nil {
+		printErrorAndExit(69, "Failed to create client: %s", err)
+	}
+
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		_ = client.Close()
+		printErrorAndExit(69, "Failed to create cluster admin: %s", err)
+	}
+	defer func() {
+		if err := admin.Close(); err != nil {
+			logger.Println("Failed to close cluster admin: ", err)
+		}
+	}()
+
+	switch {
+	case *list:
+		listGroups(admin)
+	case *describe:
+		describeGroup(admin, client, *group)
+	case *deleteGroup:
+		if err := admin.DeleteConsumerGroup(*group); err != nil {
+			printErrorAndExit(69, "Failed to delete group %s: %s", *group, err)
+		}
+		fmt.Printf("Deleted consumer group %s\n", *group)
+	case *resetOffsets:
+		resetGroupOffsets(admin, client, *group)
+	}
+}
+
+func listGroups(admin sarama.ClusterAdmin) {
+	groups, err := admin.ListConsumerGroups()
+	if err != nil {
+		printErrorAndExit(69, "Failed to list consumer groups: %s", err)
+	}
+
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
+
+// partitionLag holds the committed and log end offsets of one partition of a group.
+type partitionLag struct {
+	topic     string
+	partition int32
+	committed int64
+	logEnd    int64
+	memberID  string
+	clientID  string
+	host      string
+}
+
+func (pl *partitionLag) lag() string {
+	if pl.committed < 0 || pl.logEnd < 0 {
+		return "-"
+	}
+	return strconv.FormatInt(pl.logEnd-pl.committed, 10)
+}
+
+func describeGroup(admin sarama.ClusterAdmin, client sarama.Client, group string) {
+	descriptions, err := admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		printErrorAndExit(69, "Failed to describe group %s: %s", group, err)
+	}
+	if len(descriptions) != 1 {
+		printErrorAndExit(69, "Failed to describe group %s: incomplete response", group)
+	}
+	description := descriptions[0]
+	if description.Err != sarama.ErrNoError {
+		printErrorAndExit(69, "Failed to describe group %s: %s", group, description.Err)
+	}
+
+	fmt.Printf("Group %s is %s (protocol type %q, assignment strategy %q) with %d member(s)\n\n",
+		group, description.State, description.ProtocolType, description.Protocol, len(description.Members))
+
+	partitions := make(map[string]map[int32]*partitionLag)
+	entry := func(topic string, partition int32) *partitionLag {
+		if partitions[topic] == nil {
+			partitions[topic] = make(map[int32]*partitionLag)
+		}
+		if partitions[topic][partition] == nil {
+			partitions[topic][partition] = &partitionLag{topic: topic, partition: partition, committed: -1, logEnd: -1}
+		}
+		return partitions[topic][partition]
+	}
+
+	for memberID, member := range description.Members {
+		if description.ProtocolType != "consumer" || len(member.MemberAssignment) == 0 {
+			continue
+		}
+		assignment, err := member.GetMemberAssignment()
+		if err != nil {
+			printErrorAndExit(69, "Failed to decode the assignment of member %s: %s", memberID, err)
+		}
+		for topic, ids := range assignment.Topics {
+			for _, id := range ids {
+				pl := entry(topic, id)
+				pl.memberID, pl.clientID, pl.host = memberID, member.ClientId, member.ClientHost
+			}
+		}
+	}
+
+	offsets, err := admin.ListConsumerGroupOffsets(group, nil)
+	if err != nil {
+		printErrorAndExit(69, "Failed to fetch the offsets of group %s: %s", group, err)
+	}
+	if offsets.Err != sarama.ErrNoError {
+		printErrorAndExit(69, "Failed to fetch the offsets of group %s: %s", group, offsets.Err)
+	}
+	for topic, blocks := range offsets.Blocks {
+		for id, block := range blocks {
+			if block.Err != sarama.ErrNoError {
+				logger.Printf("Failed to fetch the offset of %s/%d: %s\n", topic, id, block.Err)
+				continue
+			}
+			entry(topic, id).committed = block.Offset
+		}
+	}
+
+	rows := make([]*partitionLag, 0)
+	for _, byPartition := range partitions {
+		for _, pl := range byPartition {
+			logEnd, err := client.GetOffset(pl.topic, pl.partition, sarama.OffsetNewest)
+			if err != nil {
+				logger.Printf("Failed to fetch the log end offset of %s/%d: %s\n", pl.topic, pl.partition, err)
+			} else {
+				pl.logEnd = logEnd
+			}
+			rows = append(rows, pl)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].topic != rows[j].topic {
+			return rows[i].topic < rows[j].topic
+		}
+		return rows[i].partition < rows[j].partition
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITION\tCURRENT-OFFSET\tLOG-END-OFFSET\tLAG\tMEMBER-ID\tCLIENT-ID\tHOST")
+	for _, pl := range rows {
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			pl.topic, pl.partition, formatOffset(pl.committed), formatOffset(pl.logEnd), pl.lag(),
+			orDash(pl.memberID), orDash(pl.clientID), orDash(pl.host))
+	}
+	_ = w.Flush()
+}
+
+func resetGroupOffsets(admin sarama.ClusterAdmin, client sarama.Client, group string) {
+	strategies := 0
+	for _, enabled := range []bool{*toEarliest, *toLatest, *toDatetime != "", *shiftBy != 0} {
+		if enabled {
+			strategies++
+		}
+	}
+	if strategies != 1 {
+		printUsageErrorAndExit("-reset-offsets requires exactly one of -to-earliest, -to-latest, -to-datetime or -shift-by")
+	}
+
+	var timestamp int64
+	if *toDatetime != "" {
+		t, err := time.Parse(time.RFC3339, *toDatetime)
+		if err != nil {
+			printUsageErrorAndExit("Invalid -to-datetime: %s", err)
+		}
+		timestamp = t.UnixNano() / int64(time.Millisecond)
+	}
+
+	descriptions, err := admin.DescribeConsumerGroups([]string{group})
+	if err != nil {
+		printErrorAndExit(69, "Failed to describe group %s: %s", group, err)
+	}
+	if len(descriptions) == 1 && len(descriptions[0].Members) > 0 {
+		printErrorAndExit(69, "Group %s has %d active member(s); stop them before resetting offsets", group, len(descriptions[0].Members))
+	}
+
+	targets, err := parseTopicPartitions(client, *topics)
+	if err != nil {
+		printUsageErrorAndExit("Invalid -topic: %s", err)
+	}
+
+	committed, err := admin.ListConsumerGroupOffsets(group, targets)
+	if err != nil {
+		printErrorAndExit(69, "Failed to fetch the offsets of group %s: %s", group, err)
+	}
+
+	request := &sarama.OffsetCommitRequest{
+		Version:                 1,
+		ConsumerGroup:           group,
+		ConsumerGroupGeneration: sarama.GroupGenerationUndefined,
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITION\tCURRENT-OFFSET\tNEW-OFFSET")
+	for _, topic := range sortedTopics(targets) {
+		for _, partition := range targets[topic] {
+			current := int64(-1)
+			if block := committed.GetBlock(topic, partition); block != nil && block.Err == sarama.ErrNoError {
+				current = block.Offset
+			}
+
+			oldest, err := client.GetOffset(topic, partition, sarama.OffsetOldest)
+			if err != nil {
+				printErrorAndExit(69, "Failed to fetch the earliest offset of %s/%d: %s", topic, partition, err)
+			}
+			newest, err := client.GetOffset(topic, partition, sarama.OffsetNewest)
+			if err != nil {
+				printErrorAndExit(69, "Failed to fetch the latest offset of %s/%d: %s", topic, partition, err)
+			}
+
+			var target int64
+			switch {
+			case *toEarliest:
+				target = oldest
+			case *toLatest:
+				target = newest
+			case *toDatetime != "":
+				target, err = client.GetOffset(topic, partition, timestamp)
+				if err != nil {
+					printErrorAndExit(69, "Failed to fetch the offset of %s/%d at %s: %s", topic, partition, *toDatetime, err)
+				}
+				if target < 0 {
+					// no message at or after the given time
+					target = newest
+				}
+			default:
+				if current < 0 {
+					printErrorAndExit(69, "Cannot shift %s/%d: the group has no committed offset", topic, partition)
+				}
+				target = current + *shiftBy
+			}
+
+			if target < oldest {
+				target = oldest
+			} else if target > newest {
+				target = newest
+			}
+
+			request.AddBlock(topic, partition, target, sarama.ReceiveTime, "")
+			fmt.Fprintf(w, "%s\t%d\t%s\t%d\n", topic, partition, formatOffset(current), target)
+		}
+	}
+	_ = w.Flush()
+
+	if !*execute {
+		fmt.Println("\nDry run: no offsets were changed. Use -execute to apply them.")
+		return
+	}
+
+	coordinator, err := client.Coordinator(group)
+	if err != nil {
+		printErrorAndExit(69, "Failed to find the coordinator of group %s: %s", group, err)
+	}
+	response, err := coordinator.CommitOffset(request)
+	if err != nil {
+		printErrorAndExit(69, "Failed to commit offsets for group %s: %s", group, err)
+	}
+
+	failed := false
+	for topic, partitions := range response.Errors {
+		for partition, kerr := range partitions {
+			if kerr != sarama.ErrNoError {
+				logger.Printf("Failed to commit the offset of %s/%d: %s\n", topic, partition, kerr)
+				failed = true
+			}
+		}
+	}
+	if failed {
+		os.Exit(69)
+	}
+	fmt.Println("\nOffsets were reset.")
+}
+
+// parseTopicPartitions parses the -topic flag, which must name at least one
+// topic so that offsets are never reset for the whole cluster by mistake.
+func parseTopicPartitions(client sarama.Client, value string) (map[string][]int32, error) {
+	result := make(map[string][]int32)
+
+	if value == "" {
+		return nil, fmt.Errorf("at least one topic is required")
+	}
+
+	for _, spec := range strings.Split(value, ";") {
+		parts := strings.SplitN(spec, ":", 2)
+		topic := strings.TrimSpace(parts[0])
+		if topic == "" {
+			return nil, fmt.Errorf("empty topic name in %q", spec)
+		}
+
+		if len(parts) == 1 {
+			for _, name := range strings.Split(topic, ",") {
+				partitions, err := client.Partitions(name)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %s", name, err)
+				}
+				result[name] = partitions
+			}
+			continue
+		}
+
+		for _, p := range strings.Split(parts[1], ",") {
+			partition, err := strconv.ParseInt(strings.TrimSpace(p), 10, 32)
+			if err != nil {
+				return nil, err
+			}
+			result[topic] = append(result[topic], int32(partition))
+		}
+	}
+
+	return result, nil
+}
+
+func sortedTopics(topicPartitions map[string][]int32) []string {
+	names := make([]string, 0, len(topicPartitions))
+	for topic, partitions := range topicPartitions {
+		sort.Slice(partitions, func(i, j int) bool { return partitions[i] < partitions[j] })
+		names = append(names, topic)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func formatOffset(offset int64) string {
+	if offset < 0 {
+		return "-"
+	}
+	return strconv.FormatInt(offset, 10)
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func printErrorAndExit(code int, format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	os.Exit(code)
+}
+
+func printUsageErrorAndExit(format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Available command line options:")
+	flag.PrintDefaults()
+	os.Exit(64)
+}
@@ -0,0 +1,462 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	gosync "sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	metrics "github.com/rcrowley/go-metrics"
+)
+
+var (
+	messageLoad = flag.Int(
+		"message-load",
+		0,
+		"REQUIRED: The number of messages to consume from -topic.",
+	)
+	brokers = flag.String(
+		"brokers",
+		"",
+		"REQUIRED: A comma separated list of broker addresses.",
+	)
+	topic = flag.String(
+		"topic",
+		"",
+		"REQUIRED: The topic to run the performance test on.",
+	)
+	partition = flag.Int(
+		"partition",
+		-1,
+		"The partition of -topic to run the performance test on (-1 for all partitions). Ignored with -group.",
+	)
+	group = flag.String(
+		"group",
+		"",
+		"Consume with a ConsumerGroup using this group ID instead of consuming partitions directly.",
+	)
+	offset = flag.String(
+		"offset",
+		"oldest",
+		"The offset to start consuming from (oldest, newest).",
+	)
+	fetchMin = flag.Int(
+		"fetch-min",
+		1,
+		"The minimum number of message bytes to fetch in a request.",
+	)
+	fetchDefault = flag.Int(
+		"fetch-default",
+		1024*1024,
+		"The default number of message bytes to fetch from the broker in each request.",
+	)
+	fetchMax = flag.Int(
+		"fetch-max",
+		0,
+		"The maximum number of message bytes to fetch from the broker in a single request (0 for no limit).",
+	)
+	maxWaitTime = flag.Duration(
+		"max-wait-time",
+		250*time.Millisecond,
+		"The maximum amount of time the broker will wait for -fetch-min bytes to become available.",
+	)
+	isolationLevel = flag.String(
+		"isolation-level",
+		"read_uncommitted",
+		"The isolation level of fetch requests (read_uncommitted, read_committed).",
+	)
+	seedMessageLoad = flag.Int(
+		"seed-message-load",
+		0,
+		"The number of messages to produce to -topic before consuming (0 to consume existing messages only).",
+	)
+	seedMessageSize = flag.Int(
+		"seed-message-size",
+		100,
+		"The approximate size (in bytes) of each seeded message.",
+	)
+	seedCompression = flag.String(
+		"seed-compression",
+		"none",
+		"The compression method of seeded messages, which the consumer has to decompress (none, gzip, snappy, lz4, zstd).",
+	)
+	clientID = flag.String(
+		"client-id",
+		"sarama",
+		"The client ID sent with every request to the brokers.",
+	)
+	channelBufferSize = flag.Int(
+		"channel-buffer-size",
+		256,
+		"The number of events to buffer in internal and external channels.",
+	)
+	version = flag.String(
+		"version",
+		"1.0.0",
+		"The assumed version of Kafka.",
+	)
+)
+
+// stats tracks the throughput and the end-to-end latency of consumed messages.
+type stats struct {
+	records metrics.Meter
+	bytes   metrics.Meter
+	latency metrics.Histogram
+
+	lock      gosync.Mutex
+	remaining int
+	done      func()
+}
+
+func newStats(messageLoad int, done func()) *stats {
+	return &stats{
+		records:   metrics.NewMeter(),
+		bytes:     metrics.NewMeter(),
+		latency:   metrics.NewHistogram(metrics.NewExpDecaySample(1028, 0.015)),
+		remaining: messageLoad,
+		done:      done,
+	}
+}
+
+// record accounts for msg and returns false once -message-load messages have
+// been consumed.
+func (s *stats) record(msg *sarama.ConsumerMessage) bool {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.remaining <= 0 {
+		return false
+	}
+
+	s.records.Mark(1)
+	s.bytes.Mark(int64(len(msg.Key) + len(msg.Value)))
+	if !msg.Timestamp.IsZero() {
+		s.latency.Update(int64(time.Since(msg.Timestamp) / time.Millisecond))
+	}
+
+	s.remaining--
+	if s.remaining == 0 {
+		s.done()
+		return false
+	}
+	return true
+}
+
+func parseCompression(scheme string) sarama.CompressionCodec {
+	switch scheme {
+	case "none":
+		return sarama.CompressionNone
+	case "gzip":
+		return sarama.CompressionGZIP
+	case "snappy":
+		return sarama.CompressionSnappy
+	case "lz4":
+		return sarama.CompressionLZ4
+	case "zstd":
+		return sarama.CompressionZSTD
+	default:
+		printUsageErrorAndExit(fmt.Sprintf("Unknown -seed-compression: %s", scheme))
+	}
+	panic("should not happen")
+}
+
+func parseIsolationLevel(level string) sarama.IsolationLevel {
+	switch level {
+	case "read_uncommitted":
+		return sarama.ReadUncommitted
+	case "read_committed":
+		return sarama.ReadCommitted
+	default:
+		printUsageErrorAndExit(fmt.Sprintf("Unknown -isolation-level: %s", level))
+	}
+	panic("should not happen")
+}
+
+func parseOffset(offset string) int64 {
+	switch offset {
+	case "oldest":
+		return sarama.OffsetOldest
+	case "newest":
+		return sarama.OffsetNewest
+	default:
+		printUsageErrorAndExit(fmt.Sprintf("Unknown -offset: %s", offset))
+	}
+	panic("should not happen")
+}
+
+func parseVersion(version string) sarama.KafkaVersion {
+	result, err := sarama.ParseKafkaVersion(version)
+	if err != nil {
+		printUsageErrorAndExit(fmt.Sprintf("unknown -version: %s", version))
+	}
+	return result
+}
+
+func main() {
+	flag.Parse()
+
+	if *brokers == "" {
+		printUsageErrorAndExit("-brokers is required")
+	}
+	if *topic == "" {
+		printUsageErrorAndExit("-topic is required")
+	}
+	if *messageLoad <= 0 {
+		printUsageErrorAndExit("-message-load must be greater than 0")
+	}
+
+	config := sarama.NewConfig()
+
+	config.Consumer.Fetch.Min = int32(*fetchMin)
+	config.Consumer.Fetch.Default = int32(*fetchDefault)
+	config.Consumer.Fetch.Max = int32(*fetchMax)
+	config.Consumer.MaxWaitTime = *maxWaitTime
+	config.Consumer.IsolationLevel = parseIsolationLevel(*isolationLevel)
+	config.Consumer.Offsets.Initial = parseOffset(*offset)
+	config.Consumer.Return.Errors = true
+	config.ClientID = *clientID
+	config.ChannelBufferSize = *channelBufferSize
+	config.Version = parseVersion(*version)
+
+	if err := config.Validate(); err != nil {
+		printErrorAndExit(69, "Invalid configuration: %s", err)
+	}
+
+	brokers := strings.Split(*brokers, ",")
+
+	if *seedMessageLoad > 0 {
+		seed(brokers, config)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt)
+		<-signals
+		cancel()
+	}()
+
+	s := newStats(*messageLoad, cancel)
+
+	// Print out metrics periodically.
+	done := make(chan struct{})
+	go func(ctx context.Context) {
+		defer close(done)
+		t := time.Tick(5 * time.Second)
+		for {
+			select {
+			case <-t:
+				printMetrics(os.Stdout, config.MetricRegistry, s)
+			case <-ctx.Done():
+				return
+			}
+		}
+	}(ctx)
+
+	if *group != "" {
+		runConsumerGroup(ctx, brokers, config, s)
+	} else {
+		runPartitionConsumers(ctx, brokers, config, s)
+	}
+
+	cancel()
+	<-done
+
+	// Print final metrics.
+	printMetrics(os.Stdout, config.MetricRegistry, s)
+}
+
+// seed produces -seed-message-load messages to -topic so that the consumer
+// has data to read, compressed with -seed-compression.
+func seed(brokers []string, config *sarama.Config) {
+	producerConfig := sarama.NewConfig()
+	producerConfig.Version = config.Version
+	producerConfig.ClientID = config.ClientID
+	producerConfig.Producer.Compression = parseCompression(*seedCompression)
+	producerConfig.Producer.RequiredAcks = sarama.WaitForAll
+	producerConfig.Producer.Return.Successes = true
+	producerConfig.Producer.Flush.Messages = 500
+	if *partition >= 0 && *group == "" {
+		// the hash partitioner would scatter the unkeyed messages
+		producerConfig.Producer.Partitioner = sarama.NewManualPartitioner
+	}
+
+	producer, err := sarama.NewAsyncProducer(brokers, producerConfig)
+	if err != nil {
+		printErrorAndExit(69, "Failed to create seed producer: %s", err)
+	}
+
+	go func() {
+		for i := 0; i < *seedMessageLoad; i++ {
+			payload := make([]byte, *seedMessageSize)
+			if _, err := rand.Read(payload); err != nil {
+				printErrorAndExit(69, "Failed to generate message payload: %s", err)
+			}
+			message := &sarama.ProducerMessage{Topic: *topic, Value: sarama.ByteEncoder(payload)}
+			if *partition >= 0 && *group == "" {
+				message.Partition = int32(*partition)
+			}
+			producer.Input() <- message
+		}
+	}()
+
+	for i := 0; i < *seedMessageLoad; i++ {
+		select {
+		case <-producer.Successes():
+		case err := <-producer.Errors():
+			printErrorAndExit(69, "Failed to seed message: %s", err)
+		}
+	}
+
+	if err := producer.Close(); err != nil {
+		printErrorAndExit(69, "Failed to close seed producer: %s", err)
+	}
+	fmt.Fprintf(os.Stdout, "%d messages seeded\n", *seedMessageLoad)
+}
+
+func runPartitionConsumers(ctx context.Context, brokers []string, config *sarama.Config, s *stats) {
+	consumer, err := sarama.NewConsumer(brokers, config)
+	if err != nil {
+		printErrorAndExit(69, "Failed to create consumer: %s", err)
+	}
+	defer func() {
+		if err := consumer.Close(); err != nil {
+			printErrorAndExit(69, "Failed to close consumer: %s", err)
+		}
+	}()
+
+	partitions := []int32{int32(*partition)}
+	if *partition < 0 {
+		partitions, err = consumer.Partitions(*topic)
+		if err != nil {
+			printErrorAndExit(69, "Failed to get the partitions of %s: %s", *topic, err)
+		}
+	}
+
+	var wg gosync.WaitGroup
+	for _, p := range partitions {
+		pc, err := consumer.ConsumePartition(*topic, p, config.Consumer.Offsets.Initial)
+		if err != nil {
+			printErrorAndExit(69, "Failed to consume partition %d: %s", p, err)
+		}
+
+		go func(pc sarama.PartitionConsumer) {
+			<-ctx.Done()
+			pc.AsyncClose()
+		}(pc)
+
+		wg.Add(2)
+		go func(pc sarama.PartitionConsumer) {
+			defer wg.Done()
+			for message := range pc.Messages() {
+				s.record(message)
+			}
+		}(pc)
+		go func(pc sarama.PartitionConsumer) {
+			defer wg.Done()
+			for err := range pc.Errors() {
+				printErrorAndExit(69, "%s", err)
+			}
+		}(pc)
+	}
+	wg.Wait()
+}
+
+type groupHandler struct {
+	stats *stats
+}
+
+func (h *groupHandler) Setup(sarama.ConsumerGroupSession) error   { return nil }
+func (h *groupHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for message := range claim.Messages() {
+		session.MarkMessage(message, "")
+		if !h.stats.record(message) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func runConsumerGroup(ctx context.Context, brokers []string, config *sarama.Config, s *stats) {
+	consumerGroup, err := sarama.NewConsumerGroup(brokers, *group, config)
+	if err != nil {
+		printErrorAndExit(69, "Failed to create consumer group: %s", err)
+	}
+	defer func() {
+		if err := consumerGroup.Close(); err != nil {
+			printErrorAndExit(69, "Failed to close consumer group: %s", err)
+		}
+	}()
+
+	go func() {
+		for err := range consumerGroup.Errors() {
+			printErrorAndExit(69, "%s", err)
+		}
+	}()
+
+	handler := &groupHandler{stats: s}
+	for ctx.Err() == nil {
+		if err := consumerGroup.Consume(ctx, []string{*topic}, handler); err != nil {
+			printErrorAndExit(69, "Failed to consume: %s", err)
+		}
+	}
+}
+
+func printMetrics(w io.Writer, r metrics.Registry, s *stats) {
+	records := s.records.Snapshot()
+	bytes := s.bytes.Snapshot()
+	latency := s.latency.Snapshot()
+	latencyPercentiles := latency.Percentiles([]float64{0.5, 0.95, 0.99})
+	fmt.Fprintf(w, "%d records consumed, %.1f records/sec (%.2f MB/sec), "+
+		"end-to-end latency %.1f ms avg, %.1f ms 50th, %.1f ms 95th, %.1f ms 99th\n",
+		records.Count(),
+		records.RateMean(),
+		bytes.RateMean()/1024/1024,
+		latency.Mean(),
+		latencyPercentiles[0],
+		latencyPercentiles[1],
+		latencyPercentiles[2],
+	)
+
+	// The latency of all the requests sent to the brokers: mostly fetches, but
+	// with -group also the requests to join, sync and heartbeat the group and
+	// to commit its offsets.
+	if r.Get("request-latency-in-ms") == nil {
+		return
+	}
+	requestLatency := r.Get("request-latency-in-ms").(metrics.Histogram).Snapshot()
+	requestLatencyPercentiles := requestLatency.Percentiles([]float64{0.5, 0.75, 0.95, 0.99, 0.999})
+	fmt.Fprintf(w, "request latency %.1f ms avg, %.1f ms stddev, %.1f ms 50th, %.1f ms 75th, "+
+		"%.1f ms 95th, %.1f ms 99th, %.1f ms 99.9th\n",
+		requestLatency.Mean(),
+		requestLatency.StdDev(),
+		requestLatencyPercentiles[0],
+		requestLatencyPercentiles[1],
+		requestLatencyPercentiles[2],
+		requestLatencyPercentiles[3],
+		requestLatencyPercentiles[4],
+	)
+}
+
+func printUsageErrorAndExit(message string) {
+	fmt.Fprintln(os.Stderr, "ERROR:", message)
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Available command line options:")
+	flag.PrintDefaults()
+	os.Exit(64)
+}
+
+func printErrorAndExit(code int, format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	os.Exit(code)
+}
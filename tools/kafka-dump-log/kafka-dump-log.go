@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Shopify/sarama"
+)
+
+const (
+	// every entry of a log segment starts with its offset and its length
+	logOverhead = 8 + 4
+
+	// the position of the magic byte within an entry
+	magicOffset = 16
+
+	// the position and length of the CRC of a record batch (magic 2), which
+	// covers everything from the attributes to the end of the batch
+	batchCRCOffset = 17
+	batchCRCLength = 4
+
+	// the position of the CRC of a legacy message (magic 0 and 1), which
+	// covers everything from the magic byte to the end of the message
+	messageCRCOffset = 12
+)
+
+var (
+	files        = flag.String("files", "", "REQUIRED: the comma separated list of log segment files to dump")
+	printPayload = flag.Bool("print-data-log", false, "Whether to print the keys and values of records")
+	printRecords = flag.Bool("deep-iteration", true, "Whether to print every record, or only the batches")
+	maxBatches   = flag.Int("max-batches", 0, "Stop after this many batches per file (0 means no limit)")
+
+	castagnoli = crc32.MakeTable(crc32.Castagnoli)
+)
+
+func main() {
+	flag.Parse()
+
+	paths := flag.Args()
+	if *files != "" {
+		paths = append(strings.Split(*files, ","), paths...)
+	}
+	if len(paths) == 0 {
+		printUsageErrorAndExit("-files is required")
+	}
+
+	for _, path := range paths {
+		if err := dumpFile(path); err != nil {
+			printErrorAndExit(69, "Failed to dump %s: %s", path, err)
+		}
+	}
+}
+
+func dumpFile(path string) error {
+	if !strings.HasSuffix(path, ".log") {
+		fmt.Fprintf(os.Stderr, "WARNING: %s does not look like a log segment, dumping it anyway\n", path)
+	}
+
+	buf, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Dumping %s\n", path)
+
+	position := 0
+	for batches := 0; position < len(buf); batches++ {
+		if *maxBatches > 0 && batches >= *maxBatches {
+			return nil
+		}
+
+		remaining := buf[position:]
+		if len(remaining) < logOverhead {
+			fmt.Printf("Found %d trailing bytes at position %d, which is too short for a batch header\n", len(remaining), position)
+			return nil
+		}
+
+		size := logOverhead + int(int32(binary.BigEndian.Uint32(remaining[8:12])))
+		if size <= magicOffset || size > len(remaining) {
+			fmt.Printf("Found a truncated or invalid batch of %d bytes at position %d with only %d bytes left\n", size, position, len(remaining))
+			return nil
+		}
+
+		dumpEntry(remaining[:size], position)
+		position += size
+	}
+	return nil
+}
+
+// dumpEntry prints a single record batch or legacy message found at position.
+func dumpEntry(entry []byte, position int) {
+	magic := int8(entry[magicOffset])
+
+	crcOffset := messageCRCOffset
+	var storedCRC, computedCRC uint32
+	switch {
+	case magic >= 2:
+		if len(entry) < batchCRCOffset+batchCRCLength {
+			fmt.Printf("position: %d magic: %d size: %d truncated batch header\n", position, magic, len(entry))
+			return
+		}
+		crcOffset = batchCRCOffset
+		storedCRC = binary.BigEndian.Uint32(entry[batchCRCOffset:])
+		computedCRC = crc32.Checksum(entry[batchCRCOffset+batchCRCLength:], castagnoli)
+	default:
+		storedCRC = binary.BigEndian.Uint32(entry[messageCRCOffset:])
+		computedCRC = crc32.ChecksumIEEE(entry[magicOffset:])
+	}
+	valid := storedCRC == computedCRC
+
+	if !valid {
+		// DecodeRecords rejects CRC mismatches, so decode a copy carrying the
+		// computed CRC to still print what can be parsed of a corrupt entry
+		entry = append([]byte(nil), entry...)
+		binary.BigEndian.PutUint32(entry[crcOffset:], computedCRC)
+	}
+
+	records, err := sarama.DecodeRecords(entry)
+	if err != nil {
+		fmt.Printf("baseOffset: %d position: %d size: %d magic: %d crc: %d isValid: %t error: %s\n",
+			int64(binary.BigEndian.Uint64(entry)), position, len(entry), magic, storedCRC, valid, err)
+		return
+	}
+
+	if records.RecordBatch != nil {
+		dumpBatch(records.RecordBatch, position, len(entry), storedCRC, valid)
+	} else if records.MsgSet != nil {
+		for _, block := range records.MsgSet.Messages {
+			dumpMessage(block, position, len(entry), storedCRC, valid)
+		}
+	}
+}
+
+func dumpBatch(batch *sarama.RecordBatch, position, size int, crc uint32, valid bool) {
+	lastSequence := int32(-1)
+	if batch.FirstSequence >= 0 {
+		lastSequence = batch.FirstSequence + batch.LastOffsetDelta
+	}
+
+	fmt.Printf("baseOffset: %d lastOffset: %d count: %d baseSequence: %d lastSequence: %d "+
+		"producerId: %d producerEpoch: %d partitionLeaderEpoch: %d isTransactional: %t isControl: %t "+
+		"position: %d %s: %d size: %d magic: %d compresscodec: %s crc: %d isValid: %t\n",
+		batch.FirstOffset, batch.FirstOffset+int64(batch.LastOffsetDelta), len(batch.Records),
+		batch.FirstSequence, lastSequence, batch.ProducerID, batch.ProducerEpoch, batch.PartitionLeaderEpoch,
+		batch.IsTransactional, batch.Control, position, timestampType(batch.LogAppendTime),
+		millis(batch.MaxTimestamp), size, batch.Version, batch.Codec, crc, valid)
+
+	if !*printRecords {
+		return
+	}
+
+	for _, record := range batch.Records {
+		fmt.Printf("| offset: %d %s: %d keysize: %d valuesize: %d",
+			batch.FirstOffset+record.OffsetDelta, timestampType(batch.LogAppendTime),
+			millis(batch.FirstTimestamp.Add(record.TimestampDelta)), len(record.Key), len(record.Value))
+
+		if batch.FirstSequence >= 0 {
+			fmt.Printf(" sequence: %d", batch.FirstSequence+int32(record.OffsetDelta))
+		}
+
+		headerKeys := make([]string, len(record.Headers))
+		for i, header := range record.Headers {
+			headerKeys[i] = string(header.Key)
+		}
+		fmt.Printf(" headerKeys: [%s]", strings.Join(headerKeys, ","))
+
+		if batch.Control {
+			fmt.Printf(" %s", controlRecord(record))
+		} else if *printPayload {
+			fmt.Printf(" key: %s payload: %s", string(record.Key), string(record.Value))
+			for _, header := range record.Headers {
+				fmt.Printf(" header: %s=%s", string(header.Key), string(header.Value))
+			}
+		}
+		fmt.Println()
+	}
+}
+
+func dumpMessage(block *sarama.MessageBlock, position, size int, crc uint32, valid bool) {
+	msg := block.Msg
+	fmt.Printf("offset: %d position: %d %s: %d isValid: %t size: %d magic: %d compresscodec: %s crc: %d",
+		block.Offset, position, timestampType(msg.LogAppendTime), millis(msg.Timestamp),
+		valid, size, msg.Version, msg.Codec, crc)
+
+	if msg.Set == nil {
+		printLegacyPayload(msg)
+		fmt.Println()
+		return
+	}
+	fmt.Println()
+
+	if !*printRecords {
+		return
+	}
+
+	// the records of a compressed message are wrapped in its value
+	for _, inner := range msg.Set.Messages {
+		fmt.Printf("| offset: %d %s: %d keysize: %d valuesize: %d magic: %d",
+			inner.Offset, timestampType(inner.Msg.LogAppendTime), millis(inner.Msg.Timestamp),
+			len(inner.Msg.Key), len(inner.Msg.Value), inner.Msg.Version)
+		printLegacyPayload(inner.Msg)
+		fmt.Println()
+	}
+}
+
+func printLegacyPayload(msg *sarama.Message) {
+	fmt.Printf(" keysize: %d valuesize: %d", len(msg.Key), len(msg.Value))
+	if *printPayload {
+		fmt.Printf(" key: %s payload: %s", string(msg.Key), string(msg.Value))
+	}
+}
+
+// controlRecord describes the transaction marker stored in a control record.
+func controlRecord(record *sarama.Record) string {
+	// the key holds a version and a type, the value a version and the coordinator epoch
+	if len(record.Key) < 4 || len(record.Value) < 6 {
+		return "endTxnMarker: invalid"
+	}
+
+	var marker string
+	switch int16(binary.BigEndian.Uint16(record.Key[2:])) {
+	case 0:
+		marker = "ABORT"
+	case 1:
+		marker = "COMMIT"
+	default:
+		marker = "UNKNOWN"
+	}
+	return fmt.Sprintf("endTxnMarker: %s coordinatorEpoch: %d", marker, int32(binary.BigEndian.Uint32(record.Value[2:])))
+}
+
+func timestampType(logAppendTime bool) string {
+	if logAppendTime {
+		return "LogAppendTime"
+	}
+	return "CreateTime"
+}
+
+func millis(t time.Time) int64 {
+	if t.IsZero() {
+		return -1
+	}
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func printErrorAndExit(code int, format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	os.Exit(code)
+}
+
+func printUsageErrorAndExit(format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Available command line options:")
+	flag.PrintDefaults()
+	os.Exit(64)
+}
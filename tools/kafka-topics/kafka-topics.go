@@ -0,0 +1,416 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Shopify/sarama"
+	"github.com/Shopify/sarama/tools/tls"
+)
+
+const minInsyncReplicasConfig = "min.insync.replicas"
+
+// configFlag collects repeated -config key=value flags.
+type configFlag map[string]*string
+
+func (c configFlag) String() string {
+	entries := make([]string, 0, len(c))
+	for name, value := range c {
+		entries = append(entries, name+"="+*value)
+	}
+	sort.Strings(entries)
+	return strings.Join(entries, ",")
+}
+
+func (c configFlag) Set(entry string) error {
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("invalid config %q, expected key=value", entry)
+	}
+	c[parts[0]] = &parts[1]
+	return nil
+}
+
+var (
+	brokerList        = flag.String("brokers", os.Getenv("KAFKA_PEERS"), "The comma separated list of brokers in the Kafka cluster. You can also set the KAFKA_PEERS environment variable")
+	topics            = flag.String("topic", "", "The topic to create, delete or alter. With -describe or -show-configs, a comma separated list of topics (default: all topics)")
+	create            = flag.Bool("create", false, "Create -topic")
+	deleteTopic       = flag.Bool("delete", false, "Delete -topic")
+	list              = flag.Bool("list", false, "List the topics in the cluster")
+	describe          = flag.Bool("describe", false, "Describe the partitions of topics")
+	alter             = flag.Bool("alter", false, "Increase the number of partitions of -topic to -partitions")
+	showConfigs       = flag.Bool("show-configs", false, "Show the configuration of topics")
+	partitions        = flag.Int("partitions", 0, "The number of partitions (use with -create or -alter)")
+	replicationFactor = flag.Int("replication-factor", 0, "The replication factor (use with -create)")
+	underReplicated   = flag.Bool("under-replicated-partitions", false, "Only describe partitions whose ISR is smaller than their replica list")
+	unavailable       = flag.Bool("unavailable-partitions", false, "Only describe partitions whose leader is not available")
+	underMinIsr       = flag.Bool("under-min-isr-partitions", false, "Only describe partitions whose ISR is smaller than the topic's min.insync.replicas")
+	includeDefaults   = flag.Bool("include-defaults", false, "Also show default config values (use with -show-configs)")
+	validateOnly      = flag.Bool("validate-only", false, "Only validate the request without applying it (use with -create or -alter)")
+	output            = flag.String("output", "table", "The output format. Can be `table` or `json`")
+	version           = flag.String("version", "1.0.0", "The assumed version of Kafka")
+	verbose           = flag.Bool("verbose", false, "Whether to turn on sarama logging")
+	tlsEnabled        = flag.Bool("tls-enabled", false, "Whether to enable TLS")
+	tlsSkipVerify     = flag.Bool("tls-skip-verify", false, "Whether skip TLS server cert verification")
+	tlsClientCert     = flag.String("tls-client-cert", "", "Client cert for client authentication (use with -tls-enabled and -tls-client-key)")
+	tlsClientKey      = flag.String("tls-client-key", "", "Client key for client authentication (use with tls-enabled and -tls-client-cert)")
+
+	configs = make(configFlag)
+
+	logger = log.New(os.Stderr, "", log.LstdFlags)
+)
+
+func init() {
+	flag.Var(configs, "config", "A topic config override as key=value, can be repeated (use with -create)")
+}
+
+func main() {
+	flag.Parse()
+
+	if *brokerList == "" {
+		printUsageErrorAndExit("You have to provide -brokers as a comma-separated list, or set the KAFKA_PEERS environment variable.")
+	}
+
+	actions := 0
+	for _, enabled := range []bool{*create, *deleteTopic, *list, *describe, *alter, *showConfigs} {
+		if enabled {
+			actions++
+		}
+	}
+	if actions != 1 {
+		printUsageErrorAndExit("Exactly one of -create, -delete, -list, -describe, -alter or -show-configs is required")
+	}
+
+	if (*create || *deleteTopic || *alter) && (*topics == "" || strings.Contains(*topics, ",")) {
+		printUsageErrorAndExit("-topic must name a single topic")
+	}
+
+	if *output != "table" && *output != "json" {
+		printUsageErrorAndExit("-output must be either table or json")
+	}
+
+	if *verbose {
+		sarama.Logger = logger
+	}
+
+	kafkaVersion, err := sarama.ParseKafkaVersion(*version)
+	if err != nil {
+		printUsageErrorAndExit("Unknown -version: %s", *version)
+	}
+
+	config := sarama.NewConfig()
+	config.Version = kafkaVersion
+	if *tlsEnabled {
+		tlsConfig, err := tls.NewConfig(*tlsClientCert, *tlsClientKey)
+		if err != nil {
+			printErrorAndExit(69, "Failed to create TLS config: %s", err)
+		}
+
+		config.Net.TLS.Enable = true
+		config.Net.TLS.Config = tlsConfig
+		config.Net.TLS.Config.InsecureSkipVerify = *tlsSkipVerify
+	}
+
+	admin, err := sarama.NewClusterAdmin(strings.Split(*brokerList, ","), config)
+	if err != nil {
+		printErrorAndExit(69, "Failed to create cluster admin: %s", err)
+	}
+	defer func() {
+		if err := admin.Close(); err != nil {
+			logger.Println("Failed to close cluster admin: ", err)
+		}
+	}()
+
+	switch {
+	case *create:
+		createTopic(admin, *topics)
+	case *deleteTopic:
+		if err := admin.DeleteTopic(*topics); err != nil {
+			printErrorAndExit(69, "Failed to delete topic %s: %s", *topics, err)
+		}
+		fmt.Printf("Deleted topic %s\n", *topics)
+	case *list:
+		listTopics(admin)
+	case *describe:
+		describeTopics(admin, topicList(*topics))
+	case *alter:
+		if *partitions <= 0 {
+			printUsageErrorAndExit("-alter requires -partitions")
+		}
+		if err := admin.CreatePartitions(*topics, int32(*partitions), nil, *validateOnly); err != nil {
+			printErrorAndExit(69, "Failed to alter the partitions of topic %s: %s", *topics, err)
+		}
+		if *validateOnly {
+			fmt.Printf("Validated adding partitions to topic %s for a total of %d, nothing was changed\n", *topics, *partitions)
+		} else {
+			fmt.Printf("Topic %s now has %d partitions\n", *topics, *partitions)
+		}
+	case *showConfigs:
+		showTopicConfigs(admin, topicList(*topics))
+	}
+}
+
+func createTopic(admin sarama.ClusterAdmin, topic string) {
+	if *partitions <= 0 || *replicationFactor <= 0 {
+		printUsageErrorAndExit("-create requires -partitions and -replication-factor")
+	}
+
+	detail := &sarama.TopicDetail{
+		NumPartitions:     int32(*partitions),
+		ReplicationFactor: int16(*replicationFactor),
+		ConfigEntries:     configs,
+	}
+	if err := admin.CreateTopic(topic, detail, *validateOnly); err != nil {
+		printErrorAndExit(69, "Failed to create topic %s: %s", topic, err)
+	}
+	if *validateOnly {
+		fmt.Printf("Validated the creation of topic %s, nothing was changed\n", topic)
+	} else {
+		fmt.Printf("Created topic %s\n", topic)
+	}
+}
+
+func listTopics(admin sarama.ClusterAdmin) {
+	details, err := admin.ListTopics()
+	if err != nil {
+		printErrorAndExit(69, "Failed to list topics: %s", err)
+	}
+
+	names := make([]string, 0, len(details))
+	for name := range details {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if *output == "json" {
+		printJSON(names)
+		return
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+}
+
+type partitionDescription struct {
+	Topic       string  `json:"topic"`
+	Partition   int32   `json:"partition"`
+	Leader      int32   `json:"leader"`
+	Replicas    []int32 `json:"replicas"`
+	Isr         []int32 `json:"isr"`
+	Offline     []int32 `json:"offline_replicas,omitempty"`
+	MinIsr      int     `json:"min_insync_replicas,omitempty"`
+	Error       string  `json:"error,omitempty"`
+	unavailable bool
+}
+
+func describeTopics(admin sarama.ClusterAdmin, names []string) {
+	metadata, err := admin.DescribeTopics(names)
+	if err != nil {
+		printErrorAndExit(69, "Failed to describe topics: %s", err)
+	}
+
+	brokers, _, err := admin.DescribeCluster()
+	if err != nil {
+		printErrorAndExit(69, "Failed to describe the cluster: %s", err)
+	}
+	live := make(map[int32]bool, len(brokers))
+	for _, broker := range brokers {
+		live[broker.ID()] = true
+	}
+
+	sort.Slice(metadata, func(i, j int) bool { return metadata[i].Name < metadata[j].Name })
+
+	var result []*partitionDescription
+	for _, topic := range metadata {
+		if topic.Err != sarama.ErrNoError {
+			logger.Printf("Failed to describe topic %s: %s\n", topic.Name, topic.Err)
+			continue
+		}
+
+		minIsr := 0
+		if *underMinIsr {
+			minIsr = topicMinIsr(admin, topic.Name)
+		}
+
+		sort.Slice(topic.Partitions, func(i, j int) bool { return topic.Partitions[i].ID < topic.Partitions[j].ID })
+		for _, partition := range topic.Partitions {
+			pd := &partitionDescription{
+				Topic:       topic.Name,
+				Partition:   partition.ID,
+				Leader:      partition.Leader,
+				Replicas:    partition.Replicas,
+				Isr:         partition.Isr,
+				Offline:     partition.OfflineReplicas,
+				MinIsr:      minIsr,
+				unavailable: partition.Leader < 0 || !live[partition.Leader],
+			}
+			if partition.Err != sarama.ErrNoError {
+				pd.Error = partition.Err.Error()
+			}
+
+			if *underReplicated && len(partition.Isr) >= len(partition.Replicas) {
+				continue
+			}
+			if *unavailable && !pd.unavailable {
+				continue
+			}
+			if *underMinIsr && len(partition.Isr) >= minIsr {
+				continue
+			}
+			result = append(result, pd)
+		}
+	}
+
+	if *output == "json" {
+		if result == nil {
+			result = []*partitionDescription{}
+		}
+		printJSON(result)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tPARTITION\tLEADER\tREPLICAS\tISR\tOFFLINE\tERROR")
+	for _, pd := range result {
+		leader := strconv.Itoa(int(pd.Leader))
+		if pd.unavailable {
+			leader = "none"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%s\t%s\t%s\n",
+			pd.Topic, pd.Partition, leader, formatIDs(pd.Replicas), formatIDs(pd.Isr), formatIDs(pd.Offline), pd.Error)
+	}
+	_ = w.Flush()
+}
+
+// topicMinIsr returns the effective min.insync.replicas of a topic, which
+// includes the broker default if the topic does not override it.
+func topicMinIsr(admin sarama.ClusterAdmin, topic string) int {
+	entries, err := admin.DescribeConfig(sarama.ConfigResource{
+		Type:        sarama.TopicResource,
+		Name:        topic,
+		ConfigNames: []string{minInsyncReplicasConfig},
+	})
+	if err != nil {
+		printErrorAndExit(69, "Failed to describe the config of topic %s: %s", topic, err)
+	}
+
+	for _, entry := range entries {
+		if entry.Name != minInsyncReplicasConfig {
+			continue
+		}
+		minIsr, err := strconv.Atoi(entry.Value)
+		if err != nil {
+			printErrorAndExit(69, "Invalid %s for topic %s: %s", minInsyncReplicasConfig, topic, entry.Value)
+		}
+		return minIsr
+	}
+	return 1
+}
+
+type configDescription struct {
+	Topic     string `json:"topic"`
+	Name      string `json:"name"`
+	Value     string `json:"value"`
+	Source    string `json:"source"`
+	ReadOnly  bool   `json:"read_only"`
+	Sensitive bool   `json:"sensitive"`
+}
+
+func showTopicConfigs(admin sarama.ClusterAdmin, names []string) {
+	if len(names) == 0 {
+		details, err := admin.ListTopics()
+		if err != nil {
+			printErrorAndExit(69, "Failed to list topics: %s", err)
+		}
+		for name := range details {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	result := []*configDescription{}
+	for _, topic := range names {
+		entries, err := admin.DescribeConfig(sarama.ConfigResource{Type: sarama.TopicResource, Name: topic})
+		if err != nil {
+			printErrorAndExit(69, "Failed to describe the config of topic %s: %s", topic, err)
+		}
+		sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+
+		for _, entry := range entries {
+			if entry.Default && !*includeDefaults {
+				continue
+			}
+			result = append(result, &configDescription{
+				Topic:     topic,
+				Name:      entry.Name,
+				Value:     entry.Value,
+				Source:    entry.Source.String(),
+				ReadOnly:  entry.ReadOnly,
+				Sensitive: entry.Sensitive,
+			})
+		}
+	}
+
+	if *output == "json" {
+		printJSON(result)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TOPIC\tNAME\tVALUE\tSOURCE")
+	for _, cd := range result {
+		value := cd.Value
+		if cd.Sensitive {
+			value = "(sensitive)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", cd.Topic, cd.Name, value, cd.Source)
+	}
+	_ = w.Flush()
+}
+
+func topicList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func formatIDs(ids []int32) string {
+	strs := make([]string, len(ids))
+	for i, id := range ids {
+		strs[i] = strconv.Itoa(int(id))
+	}
+	return strings.Join(strs, ",")
+}
+
+func printJSON(v interface{}) {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		printErrorAndExit(69, "Failed to encode output: %s", err)
+	}
+}
+
+func printErrorAndExit(code int, format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	os.Exit(code)
+}
+
+func printUsageErrorAndExit(format string, values ...interface{}) {
+	fmt.Fprintf(os.Stderr, "ERROR: %s\n", fmt.Sprintf(format, values...))
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Available command line options:")
+	flag.PrintDefaults()
+	os.Exit(64)
+}
@@ -160,6 +160,11 @@ var (
 	V2_0_1_0  = newKafkaVersion(2, 0, 1, 0)
 	V2_1_0_0  = newKafkaVersion(2, 1, 0, 0)
 	V2_2_0_0  = newKafkaVersion(2, 2, 0, 0)
+	V2_3_0_0  = newKafkaVersion(2, 3, 0, 0)
+	V2_4_0_0  = newKafkaVersion(2, 4, 0, 0)
+	V2_5_0_0  = newKafkaVersion(2, 5, 0, 0)
+	V2_6_0_0  = newKafkaVersion(2, 6, 0, 0)
+	V2_7_0_0  = newKafkaVersion(2, 7, 0, 0)
 
 	SupportedVersions = []KafkaVersion{
 		V0_8_2_0,
@@ -183,9 +188,14 @@ var (
 		V2_0_1_0,
 		V2_1_0_0,
 		V2_2_0_0,
+		V2_3_0_0,
+		V2_4_0_0,
+		V2_5_0_0,
+		V2_6_0_0,
+		V2_7_0_0,
 	}
 	MinVersion = V0_8_2_0
-	MaxVersion = V2_2_0_0
+	MaxVersion = V2_7_0_0
 )
 
 //ParseKafkaVersion parses and returns kafka version or error from a string